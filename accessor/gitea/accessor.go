@@ -13,6 +13,7 @@ type Issue struct {
 	OriginalAuthorID   int64
 	OriginalAuthorName string
 	Closed             bool
+	Priority           int64 // higher values denote higher priorities, 0 if none
 	Description        string
 	Created            int64
 	Updated            int64
@@ -69,11 +70,28 @@ type IssueComment struct {
 	Time               int64
 }
 
+// TrackedTime describes time tracked against a Gitea issue.
+type TrackedTime struct {
+	UserID  int64
+	Seconds int64 // time spent
+	Time    int64 // time at which the time was recorded
+}
+
+// IssueContentHistory describes an entry in the edit history of the content of a Gitea issue or issue comment.
+type IssueContentHistory struct {
+	CommentID      int64 // NullID for history of the issue content itself
+	AuthorID       int64
+	Text           string
+	Time           int64
+	IsFirstCreated bool // whether entry records the content as originally created rather than an edit of it
+}
+
 // Label describes a Gitea label
 type Label struct {
 	Name        string
 	Description string
 	Color       string
+	Exclusive   bool // whether an issue can have only one label of the label's scope (the part of its name before the last '/')
 }
 
 // Milestone describes a Gitea milestone.
@@ -85,6 +103,47 @@ type Milestone struct {
 	ClosedTime  int64
 }
 
+// Release describes a Gitea release.
+type Release struct {
+	TagName     string
+	Title       string
+	Description string
+	PublisherID int64
+	IsDraft     bool
+	Created     int64
+}
+
+// User describes a Gitea user.
+type User struct {
+	Name     string
+	FullName string
+	Email    string
+}
+
+// AccessMode is a level of access to a Gitea repository.
+type AccessMode int
+
+const (
+	// NoAccess denotes no access to a repository
+	NoAccess AccessMode = 0
+
+	// ReadAccess denotes read-only access to a repository
+	ReadAccess AccessMode = 1
+
+	// WriteAccess denotes read and write access to a repository
+	WriteAccess AccessMode = 2
+
+	// AdminAccess denotes administrative access to a repository
+	AdminAccess AccessMode = 3
+)
+
+// Team describes a team within the Gitea organization owning our repository.
+type Team struct {
+	Name        string
+	Description string
+	Access      AccessMode
+}
+
 // NullID id for unset references in Gitea, also used for lookup failures
 const NullID = int64(0)
 
@@ -144,6 +203,18 @@ type Accessor interface {
 	// GetIssueCommentURL retrieves the URL for viewing a Gitea comment for a given issue.
 	GetIssueCommentURL(issueID int64, commentID int64) string
 
+	/*
+	 * Issue Content History
+	 */
+	// AddIssueContentHistory adds an entry to the edit history of the content of a Gitea issue or issue comment, returns id of created entry
+	AddIssueContentHistory(issueID int64, history *IssueContentHistory) (int64, error)
+
+	/*
+	 * Issue Dependencies
+	 */
+	// AddIssueDependency adds a dependency of one Gitea issue on another, created by the given user at the given time.
+	AddIssueDependency(issueID int64, dependencyID int64, userID int64, time int64) error
+
 	/*
 	 * Issue Labels
 	 */
@@ -165,6 +236,21 @@ type Accessor interface {
 	// AddIssueParticipant adds a user as a participant in a Gitea issue
 	AddIssueParticipant(issueID int64, userID int64) error
 
+	/*
+	 * Issue Time Tracking
+	 */
+	// AddIssueTrackedTime records time tracked against a Gitea issue
+	AddIssueTrackedTime(issueID int64, trackedTime *TrackedTime) error
+
+	// SetIssueTimeEstimate sets the estimated time (in seconds) for a Gitea issue
+	SetIssueTimeEstimate(issueID int64, seconds int64) error
+
+	/*
+	 * Issue Watches
+	 */
+	// SetIssueWatch sets whether or not a user is watching a Gitea issue
+	SetIssueWatch(issueID int64, userID int64, isWatching bool, time int64) error
+
 	/*
 	 * Labels
 	 */
@@ -186,6 +272,15 @@ type Accessor interface {
 	// GetMilestoneURL gets the URL for accessing a given milestone
 	GetMilestoneURL(milestoneID int64) string
 
+	/*
+	 * Releases
+	 */
+	// HasTag returns true if our chosen Gitea repository has a git tag of the given name.
+	HasTag(tagName string) (bool, error)
+
+	// AddRelease adds a release to Gitea, returns id of created release
+	AddRelease(release *Release) (int64, error)
+
 	/*
 	 * Repository
 	 */
@@ -195,12 +290,32 @@ type Accessor interface {
 	// UpdateRepoMilestoneCounts updates milestone counts for our chosen Gitea repository.
 	UpdateRepoMilestoneCounts() error
 
+	// SetRepoOriginalServiceType records the type of the git service from which our chosen Gitea repository was migrated.
+	SetRepoOriginalServiceType(serviceType int64) error
+
 	// GetCommitURL retrieves the URL for viewing a given commit in the current repository
 	GetCommitURL(commitID string) string
 
 	// GetSourceURL retrieves the URL for viewing the latest version of a source file on a given branch of the current repository
 	GetSourceURL(branchPath string, filePath string) string
 
+	// IsRepoOwnedByOrganization returns true if our chosen Gitea repository is owned by an organization rather than a user.
+	IsRepoOwnedByOrganization() (bool, error)
+
+	// AddRepoCollaborator adds a user as a collaborator on our chosen Gitea repository with the given access.
+	AddRepoCollaborator(userID int64, access AccessMode) error
+
+	/*
+	 * Teams
+	 * - only available where our repository is owned by an organization
+	 */
+	// AddTeam adds a team to the organization owning our repository, or finds an existing team of the same name, and gives it access to the repository.
+	// Returns the id of the team.
+	AddTeam(team *Team) (int64, error)
+
+	// AddTeamMember adds a user to a team of the organization owning our repository.
+	AddTeamMember(teamID int64, userID int64) error
+
 	/*
 	 * Transactions
 	 * - a transaction is started on creation of the accessor
@@ -223,6 +338,21 @@ type Accessor interface {
 	// MatchUser retrieves the name of the user best matching a user name or email address
 	MatchUser(userName string, userEmail string) (string, error)
 
+	// GetUsers retrieves all Gitea users (excluding organizations), passing each one to the provided "handler" function.
+	GetUsers(handlerFn func(user *User) error) error
+
+	// GetExternalUserID retrieves the id of the Gitea user who has linked the account with a given external id from a given login source
+	// - returns NullID if no such user.
+	GetExternalUserID(loginSourceID int64, externalID string) (int64, error)
+
+	// GetLoginSourceServiceType retrieves the type of the git service after which a given login source is named
+	// - returns 0 if the login source is not named after a git service from which Gitea can migrate repositories.
+	GetLoginSourceServiceType(loginSourceID int64) (int64, error)
+
+	// AddPlaceholderUser creates a restricted Gitea user who cannot log in, to stand in for a Trac user with no Gitea equivalent.
+	// A no-reply email address is used if the user has none. Returns the id of the created user.
+	AddPlaceholderUser(user *User) (int64, error)
+
 	/*
 	 * Wiki
 	 */
@@ -0,0 +1,294 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// APIAccessor is an implementation of the gitea Accessor interface which accesses Gitea via its REST API.
+// Unlike the DefaultAccessor, this requires no access to the Gitea database or filestore, only a Gitea access token.
+// Note however that the REST API does not allow the timestamps of issues and comments to be set, nor changes to be rolled back.
+type APIAccessor struct {
+	serverURL        string
+	token            string
+	client           *http.Client
+	userName         string
+	repoName         string
+	tokenUserName    string
+	canSudo          bool
+	overwrite        bool
+	userNamesByID    map[int64]string
+	issueIndexesByID map[int64]int64
+	existingIssueIDs map[int64]bool
+	// existing comments of the issue currently being imported, matched in order against the comments being added
+	commentsIssueID      int64
+	existingCommentIDs   []int64
+	existingCommentIndex int
+	historyWarned        bool
+	// IDs of the text comments added or matched by this import, keyed by issue ID then original comment time
+	commentIDsByTime map[int64]map[int64][]int64
+	*wikiRepository
+}
+
+// apiUser describes a user as returned by the Gitea API.
+type apiUser struct {
+	ID       int64  `json:"id"`
+	Login    string `json:"login"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+	IsAdmin  bool   `json:"is_admin"`
+}
+
+// apiPageSize is the number of items we request per page when listing items through the API.
+const apiPageSize = 50
+
+// CreateAPIAccessor returns a new Gitea API accessor.
+// If no wiki repository token is provided, the API token is used to access the wiki repository.
+func CreateAPIAccessor(
+	giteaServerURL string,
+	giteaToken string,
+	giteaUserName string,
+	giteaRepoName string,
+	giteaWikiRepoURL string,
+	giteaWikiRepoToken string,
+	giteaWikiRepoDir string,
+	overwriteData bool,
+	pushWiki bool) (*APIAccessor, error) {
+	if giteaToken == "" {
+		return nil, fmt.Errorf("an access token is required to use the Gitea API")
+	}
+
+	giteaAccessor := APIAccessor{
+		serverURL:        strings.TrimSuffix(giteaServerURL, "/"),
+		token:            giteaToken,
+		client:           &http.Client{Timeout: 5 * time.Minute},
+		userName:         giteaUserName,
+		repoName:         giteaRepoName,
+		tokenUserName:    "",
+		canSudo:          false,
+		overwrite:        overwriteData,
+		userNamesByID:    make(map[int64]string),
+		issueIndexesByID: make(map[int64]int64),
+		existingIssueIDs: make(map[int64]bool),
+		commentsIssueID:  NullID,
+		commentIDsByTime: make(map[int64]map[int64][]int64),
+		wikiRepository:   nil}
+
+	// find user owning token - if an administrator, we can make requests on behalf of other users
+	var tokenUser apiUser
+	status, err := giteaAccessor.apiRequest("GET", "/user", "", nil, &tokenUser)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, fmt.Errorf("cannot find Gitea API at %s", giteaServerURL)
+	}
+	giteaAccessor.tokenUserName = tokenUser.Login
+	giteaAccessor.canSudo = tokenUser.IsAdmin
+	giteaAccessor.userNamesByID[tokenUser.ID] = tokenUser.Login
+	log.Info("using Gitea API at %s as user %s", giteaServerURL, tokenUser.Login)
+
+	status, err = giteaAccessor.apiRequest("GET", giteaAccessor.repoPath(), "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, fmt.Errorf("cannot find repository %s for user %s", giteaRepoName, giteaUserName)
+	}
+
+	if giteaWikiRepoToken == "" {
+		giteaWikiRepoToken = giteaToken
+	}
+	wiki, err := createWikiRepository(giteaAccessor.serverURL,
+		giteaUserName, giteaRepoName, giteaWikiRepoURL, giteaWikiRepoToken, giteaWikiRepoDir, overwriteData, pushWiki)
+	if err != nil {
+		return nil, err
+	}
+	giteaAccessor.wikiRepository = wiki
+
+	return &giteaAccessor, nil
+}
+
+// repoPath returns the API path of our Gitea repository.
+func (accessor *APIAccessor) repoPath() string {
+	return "/repos/" + url.PathEscape(accessor.userName) + "/" + url.PathEscape(accessor.repoName)
+}
+
+// apiURL returns the URL for a given API path.
+func (accessor *APIAccessor) apiURL(path string) string {
+	return accessor.serverURL + "/api/v1" + path
+}
+
+// apiRequest performs a request on the Gitea API, sending any provided request data as JSON and decoding any JSON response into the provided response data.
+// If a sudo user is provided, the request is performed on behalf of that user.
+// Returns the HTTP status of the response - a "not found" status of a GET request is not treated as an error to allow callers to detect missing items,
+// whereas any failure to create, change or delete an item is an error.
+func (accessor *APIAccessor) apiRequest(method string, path string, sudoUser string, requestData interface{}, responseData interface{}) (int, error) {
+	var body io.Reader
+	if requestData != nil {
+		requestBytes, err := json.Marshal(requestData)
+		if err != nil {
+			err = errors.Wrapf(err, "encoding Gitea API request %s %s", method, path)
+			return 0, err
+		}
+		body = bytes.NewReader(requestBytes)
+	}
+
+	request, err := http.NewRequest(method, accessor.apiURL(path), body)
+	if err != nil {
+		err = errors.Wrapf(err, "creating Gitea API request %s %s", method, path)
+		return 0, err
+	}
+	if requestData != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	return accessor.performRequest(request, sudoUser, responseData)
+}
+
+// performRequest performs an HTTP request on the Gitea API, decoding any JSON response into the provided response data.
+func (accessor *APIAccessor) performRequest(request *http.Request, sudoUser string, responseData interface{}) (int, error) {
+	request.Header.Set("Authorization", "token "+accessor.token)
+	request.Header.Set("Accept", "application/json")
+	if sudoUser != "" {
+		request.Header.Set("Sudo", sudoUser)
+	}
+
+	response, err := accessor.client.Do(request)
+	if err != nil {
+		err = errors.Wrapf(err, "performing Gitea API request %s %s", request.Method, request.URL.Path)
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound && request.Method == "GET" {
+		return response.StatusCode, nil
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		message, _ := ioutil.ReadAll(io.LimitReader(response.Body, 1024))
+		return response.StatusCode, fmt.Errorf("Gitea API request %s %s failed with status \"%s\": %s",
+			request.Method, request.URL.Path, response.Status, strings.TrimSpace(string(message)))
+	}
+
+	if responseData != nil && response.StatusCode != http.StatusNoContent {
+		err = json.NewDecoder(response.Body).Decode(responseData)
+		if err != nil {
+			err = errors.Wrapf(err, "decoding response to Gitea API request %s %s", request.Method, request.URL.Path)
+			return response.StatusCode, err
+		}
+	}
+
+	return response.StatusCode, nil
+}
+
+// pagedPath returns an API path for retrieving a given page of a list of items.
+func pagedPath(path string, page int) string {
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+	return fmt.Sprintf("%s%spage=%d&limit=%d", path, separator, page, apiPageSize)
+}
+
+// sudoUser returns the name of the user on whose behalf to perform a request for a given Gitea user id.
+// Returns an empty string if requests cannot be made on behalf of that user, in which case they are made as the owner of our token.
+func (accessor *APIAccessor) sudoUser(userID int64) string {
+	if !accessor.canSudo {
+		return ""
+	}
+
+	userName := accessor.userNamesByID[userID]
+	if userName == accessor.tokenUserName {
+		return ""
+	}
+	return userName
+}
+
+// attributedText returns the text of an issue or comment, noting its original author if the API request is not being made on behalf of that author.
+func (accessor *APIAccessor) attributedText(text string, sudoUser string, authorID int64, originalAuthorName string, createdTime int64) string {
+	authorName := originalAuthorName
+	if authorName == "" && sudoUser == "" {
+		authorName = accessor.userNamesByID[authorID]
+		if authorName == accessor.tokenUserName {
+			return text
+		}
+	}
+	if authorName == "" {
+		return text
+	}
+
+	return fmt.Sprintf("_Originally posted by %s on %s_\n\n%s", authorName, time.Unix(createdTime, 0).UTC().Format("2006-01-02 15:04:05 MST"), text)
+}
+
+// GetStringConfig retrieves a value from the Gitea config as a string.
+// The Gitea config is not accessible through the API: the only value we can provide is the server root URL.
+func (accessor *APIAccessor) GetStringConfig(sectionName string, configName string) string {
+	if sectionName == "server" && configName == "ROOT_URL" {
+		return accessor.serverURL
+	}
+
+	return ""
+}
+
+// getUserRepoURL retrieves the URL of the current repository for the current user
+func (accessor *APIAccessor) getUserRepoURL() string {
+	return fmt.Sprintf("%s/%s/%s", accessor.serverURL, accessor.userName, accessor.repoName)
+}
+
+// UpdateRepoIssueCounts updates issue counts for our chosen Gitea repository.
+// Gitea maintains these counts itself for changes made through the API.
+func (accessor *APIAccessor) UpdateRepoIssueCounts() error {
+	return nil
+}
+
+// UpdateRepoMilestoneCounts updates milestone counts for our chosen Gitea repository.
+// Gitea maintains these counts itself for changes made through the API.
+func (accessor *APIAccessor) UpdateRepoMilestoneCounts() error {
+	return nil
+}
+
+// SetRepoOriginalServiceType records the type of the git service from which our chosen Gitea repository was migrated.
+// The Gitea API provides no means of setting this so it is not recorded.
+func (accessor *APIAccessor) SetRepoOriginalServiceType(serviceType int64) error {
+	log.Warn("cannot record the original service type of a repository through the Gitea API - ignored")
+	return nil
+}
+
+// GetCommitURL retrieves the URL for viewing a given commit in the current repository
+func (accessor *APIAccessor) GetCommitURL(commitID string) string {
+	repoURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/commit/%s", repoURL, commitID)
+}
+
+// GetSourceURL retrieves the URL for viewing the latest version of a source file on a given branch of the current repository
+func (accessor *APIAccessor) GetSourceURL(branchPath string, filePath string) string {
+	repoURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/src/branch/%s/%s", repoURL, branchPath, filePath)
+}
+
+// CommitTransaction commits a Gitea transaction.
+// Changes made through the API take effect immediately so only the wiki needs committing.
+func (accessor *APIAccessor) CommitTransaction() error {
+	return accessor.commitWikiRepo()
+}
+
+// RollbackTransaction rolls back a Gitea transaction.
+// Changes made through the API cannot be rolled back so only the wiki changes are discarded.
+func (accessor *APIAccessor) RollbackTransaction() error {
+	log.Warn("changes already made to Gitea through the API cannot be rolled back")
+	return accessor.rollbackWikiRepo()
+}
@@ -0,0 +1,529 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+)
+
+const (
+	apiToken    = "secret-token"
+	apiOwner    = "owner"
+	apiRepo     = "repo"
+	apiRepoPath = "/api/v1/repos/" + apiOwner + "/" + apiRepo
+)
+
+// recordedRequest is a request received by our stand-in Gitea server.
+type recordedRequest struct {
+	method string
+	path   string
+	sudo   string
+	body   map[string]interface{}
+}
+
+// standInGitea is a minimal stand-in for the Gitea REST API.
+type standInGitea struct {
+	t           *testing.T
+	server      *httptest.Server
+	requests    []recordedRequest
+	labels      []map[string]interface{}
+	issues      map[string]map[string]interface{}
+	nextIssueID int64
+	attachments []map[string]interface{}
+	tags        []string
+	releases    []map[string]interface{}
+	comments    map[string][]map[string]interface{}
+}
+
+func newStandInGitea(t *testing.T) *standInGitea {
+	standIn := standInGitea{t: t, issues: make(map[string]map[string]interface{}), nextIssueID: 101, comments: make(map[string][]map[string]interface{})}
+	standIn.server = httptest.NewServer(http.HandlerFunc(standIn.handle))
+	return &standIn
+}
+
+func (standIn *standInGitea) reply(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if data != nil {
+		json.NewEncoder(w).Encode(data)
+	}
+}
+
+func (standIn *standInGitea) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "token "+apiToken {
+		standIn.reply(w, http.StatusUnauthorized, nil)
+		return
+	}
+
+	request := recordedRequest{method: r.Method, path: r.URL.Path, sudo: r.Header.Get("Sudo")}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		json.NewDecoder(r.Body).Decode(&request.body)
+	}
+	standIn.requests = append(standIn.requests, request)
+
+	path := r.URL.Path
+	switch {
+	case path == "/api/v1/user":
+		standIn.reply(w, http.StatusOK, map[string]interface{}{"id": 1, "login": "admin", "is_admin": true})
+	case path == "/api/v1/users/alice":
+		standIn.reply(w, http.StatusOK, map[string]interface{}{"id": 2, "login": "alice", "email": "alice@example.com"})
+	case path == apiRepoPath:
+		standIn.reply(w, http.StatusOK, map[string]interface{}{"id": 10, "name": apiRepo})
+	case path == apiRepoPath+"/labels" && r.Method == "GET":
+		standIn.reply(w, http.StatusOK, standIn.labels)
+	case path == apiRepoPath+"/labels" && r.Method == "POST":
+		label := request.body
+		label["id"] = len(standIn.labels) + 1
+		standIn.labels = append(standIn.labels, label)
+		standIn.reply(w, http.StatusCreated, label)
+	case strings.HasPrefix(path, apiRepoPath+"/tags/"):
+		tagName := strings.TrimPrefix(path, apiRepoPath+"/tags/")
+		for _, tag := range standIn.tags {
+			if tag == tagName {
+				standIn.reply(w, http.StatusOK, map[string]interface{}{"name": tag})
+				return
+			}
+		}
+		standIn.reply(w, http.StatusNotFound, nil)
+	case path == apiRepoPath+"/releases" && r.Method == "GET":
+		standIn.reply(w, http.StatusOK, standIn.releases)
+	case path == apiRepoPath+"/releases" && r.Method == "POST":
+		release := request.body
+		release["id"] = len(standIn.releases) + 1
+		standIn.releases = append(standIn.releases, release)
+		standIn.reply(w, http.StatusCreated, release)
+	case path == apiRepoPath+"/milestones":
+		standIn.reply(w, http.StatusOK, []interface{}{})
+	case path == apiRepoPath+"/issues" && r.Method == "POST":
+		index := fmt.Sprintf("%d", len(standIn.issues)+1)
+		issue := map[string]interface{}{"id": standIn.nextIssueID, "number": len(standIn.issues) + 1, "title": request.body["title"]}
+		standIn.nextIssueID++
+		standIn.issues[index] = issue
+		standIn.reply(w, http.StatusCreated, issue)
+	case strings.HasPrefix(path, apiRepoPath+"/issues/"):
+		segments := strings.Split(strings.TrimPrefix(path, apiRepoPath+"/issues/"), "/")
+		issue, found := standIn.issues[segments[0]]
+		if !found {
+			standIn.reply(w, http.StatusNotFound, nil)
+			return
+		}
+		switch {
+		case len(segments) == 1:
+			standIn.reply(w, http.StatusOK, issue)
+		case segments[1] == "comments" && r.Method == "GET":
+			standIn.reply(w, http.StatusOK, standIn.pageOf(standIn.comments[segments[0]], r))
+		case segments[1] == "comments" && r.Method == "POST":
+			comment := map[string]interface{}{"id": 501 + len(standIn.comments[segments[0]]), "body": request.body["body"]}
+			standIn.comments[segments[0]] = append(standIn.comments[segments[0]], comment)
+			standIn.reply(w, http.StatusCreated, comment)
+		case segments[1] == "labels":
+			standIn.reply(w, http.StatusOK, []interface{}{})
+		case segments[1] == "assets" && r.Method == "GET":
+			standIn.reply(w, http.StatusOK, standIn.attachments)
+		case segments[1] == "assets" && r.Method == "POST":
+			file, header, err := r.FormFile("attachment")
+			if err != nil {
+				standIn.t.Errorf("expecting attachment upload: %v", err)
+				standIn.reply(w, http.StatusBadRequest, nil)
+				return
+			}
+			content, _ := ioutil.ReadAll(file)
+			attachment := map[string]interface{}{"id": 901, "name": r.URL.Query().Get("name"), "uuid": "uuid-" + header.Filename, "size": len(content)}
+			standIn.attachments = append(standIn.attachments, attachment)
+			standIn.reply(w, http.StatusCreated, attachment)
+		default:
+			standIn.reply(w, http.StatusNotFound, nil)
+		}
+	default:
+		standIn.reply(w, http.StatusNotFound, nil)
+	}
+}
+
+// pageOf returns the page of a list of items requested through the 'page' and 'limit' query parameters.
+func (standIn *standInGitea) pageOf(items []map[string]interface{}, r *http.Request) []map[string]interface{} {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if page < 1 || limit < 1 {
+		return items
+	}
+
+	start := (page - 1) * limit
+	if start >= len(items) {
+		return []map[string]interface{}{}
+	}
+	end := start + limit
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+// countRequests counts the requests received with the given method and path.
+func (standIn *standInGitea) countRequests(method string, path string) int {
+	count := 0
+	for _, request := range standIn.requests {
+		if request.method == method && request.path == path {
+			count++
+		}
+	}
+	return count
+}
+
+// findRequest finds the first request received with the given method and path.
+func (standIn *standInGitea) findRequest(method string, path string) *recordedRequest {
+	for _, request := range standIn.requests {
+		if request.method == method && request.path == path {
+			return &request
+		}
+	}
+	return nil
+}
+
+func createAPIAccessor(t *testing.T, standIn *standInGitea) (*gitea.APIAccessor, func()) {
+	wikiParentDir, err := ioutil.TempDir("", "trac2gitea-api-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() {
+		standIn.server.Close()
+		os.RemoveAll(wikiParentDir)
+	}
+
+	accessor, err := gitea.CreateAPIAccessor(standIn.server.URL, apiToken, apiOwner, apiRepo, "", "", filepath.Join(wikiParentDir, "wiki"), false, false)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	return accessor, cleanup
+}
+
+func TestAPIAccessorIsGiteaAccessor(t *testing.T) {
+	var _ gitea.Accessor = &gitea.APIAccessor{}
+}
+
+func TestCreateAPIAccessorForMissingRepository(t *testing.T) {
+	standIn := newStandInGitea(t)
+	defer standIn.server.Close()
+
+	_, err := gitea.CreateAPIAccessor(standIn.server.URL, apiToken, apiOwner, "missing", "", "", "", false, false)
+	if err == nil {
+		t.Errorf("expecting error for missing repository")
+	}
+}
+
+func TestCreateAPIAccessorWithoutToken(t *testing.T) {
+	_, err := gitea.CreateAPIAccessor("http://localhost", "", apiOwner, apiRepo, "", "", "", false, false)
+	if err == nil {
+		t.Errorf("expecting error for missing token")
+	}
+}
+
+func TestAPIAddLabel(t *testing.T) {
+	standIn := newStandInGitea(t)
+	accessor, cleanup := createAPIAccessor(t, standIn)
+	defer cleanup()
+
+	label := gitea.Label{Name: "component1", Description: "a component", Color: "#ff0000"}
+	labelID, err := accessor.AddLabel(&label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labelID != 1 {
+		t.Errorf("expecting label id 1, got %d", labelID)
+	}
+
+	// adding label again should find existing label rather than creating a new one
+	labelID, err = accessor.AddLabel(&label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if labelID != 1 {
+		t.Errorf("expecting existing label id 1, got %d", labelID)
+	}
+	if len(standIn.labels) != 1 {
+		t.Errorf("expecting a single label to be created, got %d", len(standIn.labels))
+	}
+}
+
+func TestAPIAddRelease(t *testing.T) {
+	standIn := newStandInGitea(t)
+	standIn.tags = []string{"v1.0"}
+	accessor, cleanup := createAPIAccessor(t, standIn)
+	defer cleanup()
+
+	hasTag, err := accessor.HasTag("v1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasTag {
+		t.Errorf("expecting tag v1.0 to be found")
+	}
+	hasTag, err = accessor.HasTag("v2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasTag {
+		t.Errorf("expecting tag v2.0 not to be found")
+	}
+
+	userID, err := accessor.GetUserID("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := gitea.Release{TagName: "v1.0", Title: "Version 1.0", Description: "first release", PublisherID: userID, IsDraft: true, Created: 1000}
+	releaseID, err := accessor.AddRelease(&release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if releaseID != 1 {
+		t.Errorf("expecting release id 1, got %d", releaseID)
+	}
+
+	request := standIn.findRequest("POST", apiRepoPath+"/releases")
+	if request == nil {
+		t.Fatal("expecting release to be created")
+	}
+	if request.body["tag_name"] != "v1.0" || request.body["name"] != "Version 1.0" || request.body["body"] != "first release" || request.body["draft"] != true {
+		t.Errorf("unexpected release data %v", request.body)
+	}
+	if request.sudo != "alice" {
+		t.Errorf("expecting release to be published on behalf of alice, got \"%s\"", request.sudo)
+	}
+
+	// adding release again should find existing release rather than creating a new one
+	releaseID, err = accessor.AddRelease(&release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if releaseID != 1 {
+		t.Errorf("expecting existing release id 1, got %d", releaseID)
+	}
+	if len(standIn.releases) != 1 {
+		t.Errorf("expecting a single release to be created, got %d", len(standIn.releases))
+	}
+}
+
+func TestAPIAddIssueAndComments(t *testing.T) {
+	standIn := newStandInGitea(t)
+	accessor, cleanup := createAPIAccessor(t, standIn)
+	defer cleanup()
+
+	reporterID, err := accessor.GetUserID("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := gitea.Issue{Index: 1, Summary: "a summary", ReporterID: reporterID, Description: "a description", Created: 1000}
+	issueID, err := accessor.AddIssue(&issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issueID != 101 {
+		t.Errorf("expecting issue id 101, got %d", issueID)
+	}
+
+	issueRequest := standIn.findRequest("POST", apiRepoPath+"/issues")
+	if issueRequest == nil {
+		t.Fatal("expecting issue to be created")
+	}
+	if issueRequest.sudo != "alice" {
+		t.Errorf("expecting issue to be created on behalf of alice, got \"%s\"", issueRequest.sudo)
+	}
+	if issueRequest.body["body"] != "a description" {
+		t.Errorf("unexpected issue description \"%v\"", issueRequest.body["body"])
+	}
+
+	// comment by unmapped Trac user should be attributed to that user in the text
+	comment := gitea.IssueComment{CommentType: gitea.CommentIssueCommentType, AuthorID: 1, OriginalAuthorName: "bob", Text: "a comment", Time: 2000}
+	commentID, err := accessor.AddIssueComment(issueID, &comment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commentID != 501 {
+		t.Errorf("expecting comment id 501, got %d", commentID)
+	}
+	commentRequest := standIn.findRequest("POST", apiRepoPath+"/issues/1/comments")
+	if commentRequest == nil {
+		t.Fatal("expecting comment to be created")
+	}
+	commentText, _ := commentRequest.body["body"].(string)
+	if !strings.Contains(commentText, "bob") || !strings.HasSuffix(commentText, "a comment") {
+		t.Errorf("unexpected comment text \"%s\"", commentText)
+	}
+
+	// close should be performed as an issue edit
+	closeComment := gitea.IssueComment{CommentType: gitea.CloseIssueCommentType, AuthorID: reporterID, Time: 3000}
+	_, err = accessor.AddIssueComment(issueID, &closeComment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeRequest := standIn.findRequest("PATCH", apiRepoPath+"/issues/1")
+	if closeRequest == nil {
+		t.Fatal("expecting issue to be closed")
+	}
+	if closeRequest.body["state"] != "closed" {
+		t.Errorf("expecting issue state to be closed, got \"%v\"", closeRequest.body["state"])
+	}
+
+	// label addition should be performed as an issue label change
+	labelComment := gitea.IssueComment{CommentType: gitea.LabelIssueCommentType, AuthorID: reporterID, LabelID: 3, Text: "1", Time: 4000}
+	_, err = accessor.AddIssueComment(issueID, &labelComment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if standIn.findRequest("POST", apiRepoPath+"/issues/1/labels") == nil {
+		t.Error("expecting label to be added to issue")
+	}
+}
+
+func TestAPIReimportIssueComments(t *testing.T) {
+	standIn := newStandInGitea(t)
+	standIn.issues["1"] = map[string]interface{}{"id": 101, "number": 1, "title": "a summary"}
+	for commentNum := 0; commentNum < 60; commentNum++ {
+		standIn.comments["1"] = append(standIn.comments["1"], map[string]interface{}{"id": 501 + commentNum, "body": fmt.Sprintf("comment %d", commentNum)})
+	}
+
+	// each accessor (i.e. each import) should match its comments against the existing comments from the first comment onwards
+	for importNum := 1; importNum <= 2; importNum++ {
+		accessor, err := gitea.CreateAPIAccessor(standIn.server.URL, apiToken, apiOwner, apiRepo, "", "", "", false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		issueID, err := accessor.AddIssue(&gitea.Issue{Index: 1, Summary: "a summary"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// existing comments span more than one page - none should be re-posted
+		for commentNum := 0; commentNum < 60; commentNum++ {
+			comment := gitea.IssueComment{CommentType: gitea.CommentIssueCommentType, Text: fmt.Sprintf("comment %d", commentNum), Time: 1000}
+			commentID, err := accessor.AddIssueComment(issueID, &comment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if commentID != int64(501+commentNum) {
+				t.Errorf("import %d: expecting existing comment id %d, got %d", importNum, 501+commentNum, commentID)
+			}
+		}
+		if count := standIn.countRequests("POST", apiRepoPath+"/issues/1/comments"); count != 0 {
+			t.Errorf("import %d: expecting no comments to be re-posted, got %d", importNum, count)
+		}
+	}
+	standIn.server.Close()
+}
+
+func TestAPIGetIssueCommentIDsByTime(t *testing.T) {
+	standIn := newStandInGitea(t)
+	accessor, cleanup := createAPIAccessor(t, standIn)
+	defer cleanup()
+
+	issueID, err := accessor.AddIssue(&gitea.Issue{Index: 1, Summary: "a summary", Created: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// comments are created with the server's time - they should be found by the original time of the comment
+	for _, commentTime := range []int64{2000, 3000} {
+		comment := gitea.IssueComment{CommentType: gitea.CommentIssueCommentType, Text: "a comment", Time: commentTime}
+		_, err = accessor.AddIssueComment(issueID, &comment)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commentIDs, err := accessor.GetIssueCommentIDsByTime(issueID, 3000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commentIDs) != 1 || commentIDs[0] != 502 {
+		t.Errorf("expecting comment id 502 for time 3000, got %v", commentIDs)
+	}
+
+	commentIDs, err = accessor.GetIssueCommentIDsByTime(issueID, 4000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commentIDs) != 0 {
+		t.Errorf("expecting no comments for time 4000, got %v", commentIDs)
+	}
+}
+
+func TestAPIAddIssueAttachment(t *testing.T) {
+	standIn := newStandInGitea(t)
+	accessor, cleanup := createAPIAccessor(t, standIn)
+	defer cleanup()
+
+	issueID, err := accessor.AddIssue(&gitea.Issue{Index: 1, Summary: "a summary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attachmentFile, err := ioutil.TempFile("", "trac2gitea-attachment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(attachmentFile.Name())
+	attachmentFile.WriteString("attachment content")
+	attachmentFile.Close()
+
+	attachment := gitea.IssueAttachment{FileName: "file.txt", Time: 1000}
+	attachmentID, err := accessor.AddIssueAttachment(issueID, &attachment, attachmentFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attachmentID != 901 {
+		t.Errorf("expecting attachment id 901, got %d", attachmentID)
+	}
+
+	uuid, err := accessor.GetIssueAttachmentUUID(issueID, "file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uuid != "uuid-file.txt" {
+		t.Errorf("expecting attachment uuid \"uuid-file.txt\", got \"%s\"", uuid)
+	}
+}
+
+func TestAPIWriteNotFoundIsError(t *testing.T) {
+	standIn := newStandInGitea(t)
+	accessor, cleanup := createAPIAccessor(t, standIn)
+	defer cleanup()
+
+	// looking up a missing item is not an error
+	issueID, err := accessor.GetIssueID(99)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issueID != gitea.NullID {
+		t.Errorf("expecting no issue to be found, got id %d", issueID)
+	}
+
+	// ...but failing to write an item is - the stand-in Gitea has no issue dependencies
+	issueID, err = accessor.AddIssue(&gitea.Issue{Index: 1, Summary: "an issue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dependencyID, err := accessor.AddIssue(&gitea.Issue{Index: 2, Summary: "a blocking issue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = accessor.AddIssueDependency(issueID, dependencyID, gitea.NullID, 1000)
+	if err == nil {
+		t.Errorf("expecting error adding dependency rejected as not found")
+	}
+}
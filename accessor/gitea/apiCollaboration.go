@@ -0,0 +1,69 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// apiPermissionName returns the name used by the Gitea API for a given access mode.
+func apiPermissionName(access AccessMode) string {
+	switch access {
+	case AdminAccess:
+		return "admin"
+	case WriteAccess:
+		return "write"
+	default:
+		return "read"
+	}
+}
+
+// IsRepoOwnedByOrganization returns true if our chosen Gitea repository is owned by an organization rather than a user.
+func (accessor *APIAccessor) IsRepoOwnedByOrganization() (bool, error) {
+	status, err := accessor.apiRequest("GET", "/orgs/"+url.PathEscape(accessor.userName), "", nil, nil)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving organization %s", accessor.userName)
+		return false, err
+	}
+
+	return status != http.StatusNotFound, nil
+}
+
+// AddRepoCollaborator adds a user as a collaborator on our chosen Gitea repository with the given access.
+func (accessor *APIAccessor) AddRepoCollaborator(userID int64, access AccessMode) error {
+	userName := accessor.userNamesByID[userID]
+	if userName == "" {
+		return fmt.Errorf("unknown collaborator id %d", userID)
+	}
+
+	collaboratorPath := accessor.repoPath() + "/collaborators/" + url.PathEscape(userName)
+	status, err := accessor.apiRequest("GET", collaboratorPath, "", nil, nil)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving collaborator %s", userName)
+		return err
+	}
+	if status != http.StatusNotFound && !accessor.overwrite {
+		log.Debug("user %s is already a collaborator - ignored", userName)
+		return nil
+	}
+
+	collaboratorData := struct {
+		Permission string `json:"permission"`
+	}{Permission: apiPermissionName(access)}
+	_, err = accessor.apiRequest("PUT", collaboratorPath, "", &collaboratorData, nil)
+	if err != nil {
+		err = errors.Wrapf(err, "adding user %s as collaborator", userName)
+		return err
+	}
+
+	log.Debug("added user %s as collaborator with %s access", userName, collaboratorData.Permission)
+
+	return nil
+}
@@ -0,0 +1,342 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// apiIssue describes an issue as returned by the Gitea API.
+type apiIssue struct {
+	ID        int64     `json:"id"`
+	Index     int64     `json:"number"`
+	Title     string    `json:"title"`
+	Assignees []apiUser `json:"assignees"`
+}
+
+// apiEditIssue describes the changes to an issue passed to the Gitea API - only non-nil fields are changed.
+type apiEditIssue struct {
+	Title     *string  `json:"title,omitempty"`
+	Body      *string  `json:"body,omitempty"`
+	Assignees []string `json:"assignees,omitempty"`
+	Milestone *int64   `json:"milestone,omitempty"`
+	State     *string  `json:"state,omitempty"`
+}
+
+// issueState returns the Gitea API state of an issue.
+func issueState(closed bool) string {
+	if closed {
+		return "closed"
+	}
+	return "open"
+}
+
+// issuePath returns the API path of the Gitea issue with a given id.
+func (accessor *APIAccessor) issuePath(issueID int64) (string, error) {
+	issueIndex, haveIndex := accessor.issueIndexesByID[issueID]
+	if !haveIndex {
+		return "", fmt.Errorf("unknown issue id %d", issueID)
+	}
+
+	return fmt.Sprintf("%s/issues/%d", accessor.repoPath(), issueIndex), nil
+}
+
+// getIssue retrieves the Gitea issue with a given index - returns nil if no such issue.
+func (accessor *APIAccessor) getIssue(issueIndex int64) (*apiIssue, error) {
+	var issue apiIssue
+	status, err := accessor.apiRequest("GET", fmt.Sprintf("%s/issues/%d", accessor.repoPath(), issueIndex), "", nil, &issue)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving issue with index %d", issueIndex)
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+
+	accessor.issueIndexesByID[issue.ID] = issue.Index
+	return &issue, nil
+}
+
+// editIssue applies a set of changes to a Gitea issue on behalf of a given user.
+func (accessor *APIAccessor) editIssue(issueID int64, sudoUser string, issueEdit *apiEditIssue) error {
+	issuePath, err := accessor.issuePath(issueID)
+	if err != nil {
+		return err
+	}
+
+	_, err = accessor.apiRequest("PATCH", issuePath, sudoUser, issueEdit, nil)
+	if err != nil {
+		err = errors.Wrapf(err, "updating issue %d", issueID)
+		return err
+	}
+
+	return nil
+}
+
+// GetIssueID retrieves the id of the Gitea issue corresponding to a given issue index - returns NullID if no such issue.
+func (accessor *APIAccessor) GetIssueID(issueIndex int64) (int64, error) {
+	issue, err := accessor.getIssue(issueIndex)
+	if err != nil {
+		return NullID, err
+	}
+	if issue == nil {
+		return NullID, nil
+	}
+
+	return issue.ID, nil
+}
+
+// AddIssue adds a new issue to Gitea.
+// Gitea allocates the indexes of issues created through the API so Trac tickets must be imported in order into a repository with no existing issues
+// if the Gitea issue indexes are to match the Trac ticket numbers.
+// The Gitea API provides no means of setting the priority of an issue so this is not imported.
+func (accessor *APIAccessor) AddIssue(issue *Issue) (int64, error) {
+	issueID, err := accessor.GetIssueID(issue.Index)
+	if err != nil {
+		return NullID, err
+	}
+
+	milestoneID, err := accessor.GetMilestoneID(issue.Milestone)
+	if err != nil {
+		return NullID, err
+	}
+
+	sudoUser := accessor.sudoUser(issue.ReporterID)
+	description := accessor.attributedText(issue.Description, sudoUser, issue.ReporterID, issue.OriginalAuthorName, issue.Created)
+	state := issueState(issue.Closed)
+	if issueID != NullID {
+		accessor.existingIssueIDs[issueID] = true
+		if !accessor.overwrite {
+			log.Info("issue %d already exists - ignored", issue.Index)
+			return issueID, nil
+		}
+
+		issueEdit := apiEditIssue{Title: &issue.Summary, Body: &description, Milestone: &milestoneID, State: &state}
+		err = accessor.editIssue(issueID, "", &issueEdit)
+		if err != nil {
+			return NullID, err
+		}
+
+		log.Info("updated issue %d: %s", issue.Index, issue.Summary)
+		return issueID, nil
+	}
+
+	issueData := struct {
+		Title     string `json:"title"`
+		Body      string `json:"body"`
+		Milestone int64  `json:"milestone,omitempty"`
+		Closed    bool   `json:"closed"`
+	}{Title: issue.Summary, Body: description, Milestone: milestoneID, Closed: issue.Closed}
+	var createdIssue apiIssue
+	_, err = accessor.apiRequest("POST", accessor.repoPath()+"/issues", sudoUser, &issueData, &createdIssue)
+	if err != nil {
+		err = errors.Wrapf(err, "adding issue with index %d", issue.Index)
+		return NullID, err
+	}
+	accessor.issueIndexesByID[createdIssue.ID] = createdIssue.Index
+	if createdIssue.Index != issue.Index {
+		log.Warn("Trac ticket %d has been created as Gitea issue %d", issue.Index, createdIssue.Index)
+	}
+
+	log.Info("created issue %d: %s", createdIssue.Index, issue.Summary)
+
+	return createdIssue.ID, nil
+}
+
+// SetIssueUpdateTime sets the update time on a given Gitea issue.
+// The Gitea API does not allow update times to be set so this is a no-op.
+func (accessor *APIAccessor) SetIssueUpdateTime(issueID int64, updateTime int64) error {
+	return nil
+}
+
+// GetIssueURL retrieves a URL for viewing a given issue
+func (accessor *APIAccessor) GetIssueURL(issueID int64) string {
+	repoURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/issues/%d", repoURL, accessor.issueIndexesByID[issueID])
+}
+
+// UpdateIssueCommentCount updates the count of comments a given issue
+// Gitea maintains this count itself for comments added through the API.
+func (accessor *APIAccessor) UpdateIssueCommentCount(issueID int64) error {
+	return nil
+}
+
+// AddIssueAssignee adds an assignee to a Gitea issue
+func (accessor *APIAccessor) AddIssueAssignee(issueID int64, assigneeID int64) error {
+	assigneeName := accessor.userNamesByID[assigneeID]
+	if assigneeName == "" {
+		return fmt.Errorf("unknown assignee id %d for issue %d", assigneeID, issueID)
+	}
+
+	issue, err := accessor.getIssue(accessor.issueIndexesByID[issueID])
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return fmt.Errorf("cannot find issue %d", issueID)
+	}
+
+	assigneeNames := []string{assigneeName}
+	for _, assignee := range issue.Assignees {
+		if assignee.Login == assigneeName {
+			log.Debug("issue %d already has assignee %d - ignored", issueID, assigneeID)
+			return nil
+		}
+		assigneeNames = append(assigneeNames, assignee.Login)
+	}
+
+	err = accessor.editIssue(issueID, "", &apiEditIssue{Assignees: assigneeNames})
+	if err != nil {
+		return err
+	}
+
+	log.Debug("added assignee %d for issue %d", assigneeID, issueID)
+
+	return nil
+}
+
+// removeIssueAssignee removes an assignee from a Gitea issue on behalf of a given user.
+func (accessor *APIAccessor) removeIssueAssignee(issueID int64, assigneeID int64, sudoUser string) error {
+	assigneeName := accessor.userNamesByID[assigneeID]
+	issue, err := accessor.getIssue(accessor.issueIndexesByID[issueID])
+	if err != nil {
+		return err
+	}
+	if issue == nil {
+		return fmt.Errorf("cannot find issue %d", issueID)
+	}
+
+	assigneeNames := []string{}
+	for _, assignee := range issue.Assignees {
+		if assignee.Login != assigneeName {
+			assigneeNames = append(assigneeNames, assignee.Login)
+		}
+	}
+	if len(assigneeNames) == len(issue.Assignees) {
+		return nil
+	}
+
+	// an empty list of assignees is omitted from an edit so explicitly send the empty list in that case
+	issuePath, err := accessor.issuePath(issueID)
+	if err != nil {
+		return err
+	}
+	assigneeData := struct {
+		Assignees []string `json:"assignees"`
+	}{Assignees: assigneeNames}
+	_, err = accessor.apiRequest("PATCH", issuePath, sudoUser, &assigneeData, nil)
+	if err != nil {
+		err = errors.Wrapf(err, "removing assignee %d from issue %d", assigneeID, issueID)
+		return err
+	}
+
+	return nil
+}
+
+// AddIssueLabel adds an issue label to Gitea, returns issue label ID
+// The API does not expose the ids of issue labels so the label id is returned instead.
+func (accessor *APIAccessor) AddIssueLabel(issueID int64, labelID int64) (int64, error) {
+	return labelID, accessor.changeIssueLabel(issueID, labelID, true, "")
+}
+
+// changeIssueLabel adds or removes a label on a Gitea issue on behalf of a given user.
+func (accessor *APIAccessor) changeIssueLabel(issueID int64, labelID int64, add bool, sudoUser string) error {
+	issuePath, err := accessor.issuePath(issueID)
+	if err != nil {
+		return err
+	}
+
+	if add {
+		labelData := struct {
+			Labels []int64 `json:"labels"`
+		}{Labels: []int64{labelID}}
+		_, err = accessor.apiRequest("POST", issuePath+"/labels", sudoUser, &labelData, nil)
+	} else {
+		_, err = accessor.apiRequest("DELETE", fmt.Sprintf("%s/labels/%d", issuePath, labelID), sudoUser, nil, nil)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "changing label %d for issue %d", labelID, issueID)
+		return err
+	}
+
+	log.Debug("changed label %d for issue %d", labelID, issueID)
+
+	return nil
+}
+
+// UpdateLabelIssueCounts updates issue counts for all labels.
+// Gitea maintains these counts itself for changes made through the API.
+func (accessor *APIAccessor) UpdateLabelIssueCounts() error {
+	return nil
+}
+
+// AddIssueParticipant adds a participant to a Gitea issue.
+// Gitea records participants itself as users act on issues through the API so this is a no-op.
+func (accessor *APIAccessor) AddIssueParticipant(issueID int64, userID int64) error {
+	return nil
+}
+
+// apiIssueMeta identifies an issue in a request to the Gitea API.
+type apiIssueMeta struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	Index int64  `json:"index"`
+}
+
+// AddIssueDependency adds a dependency of one Gitea issue on another, created by the given user at the given time.
+// The API does not allow the creator or time of the dependency to be set so these are ignored.
+func (accessor *APIAccessor) AddIssueDependency(issueID int64, dependencyID int64, userID int64, time int64) error {
+	issuePath, err := accessor.issuePath(issueID)
+	if err != nil {
+		return err
+	}
+
+	dependencyIndex, haveIndex := accessor.issueIndexesByID[dependencyID]
+	if !haveIndex {
+		return fmt.Errorf("unknown dependency issue id %d for issue %d", dependencyID, issueID)
+	}
+
+	dependencyData := apiIssueMeta{Owner: accessor.userName, Repo: accessor.repoName, Index: dependencyIndex}
+	_, err = accessor.apiRequest("POST", issuePath+"/dependencies", "", &dependencyData, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("added dependency of issue %d on issue %d", issueID, dependencyID)
+
+	return nil
+}
+
+// SetIssueWatch sets whether or not a user is watching a Gitea issue.
+// The API does not allow the time of the watch to be set so this is ignored.
+func (accessor *APIAccessor) SetIssueWatch(issueID int64, userID int64, isWatching bool, time int64) error {
+	userName := accessor.userNamesByID[userID]
+	if userName == "" {
+		return fmt.Errorf("unknown watcher id %d for issue %d", userID, issueID)
+	}
+
+	issuePath, err := accessor.issuePath(issueID)
+	if err != nil {
+		return err
+	}
+
+	method := "DELETE"
+	if isWatching {
+		method = "PUT"
+	}
+	_, err = accessor.apiRequest(method, issuePath+"/subscriptions/"+url.PathEscape(userName), "", nil, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("set watch by user %d on issue %d to %t", userID, issueID, isWatching)
+
+	return nil
+}
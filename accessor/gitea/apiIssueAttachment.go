@@ -0,0 +1,144 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// apiAttachment describes an attachment as returned by the Gitea API.
+type apiAttachment struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	UUID string `json:"uuid"`
+}
+
+// getIssueAttachment retrieves the named attachment of a given issue - returns nil if no such attachment.
+func (accessor *APIAccessor) getIssueAttachment(issueID int64, fileName string) (*apiAttachment, error) {
+	issuePath, err := accessor.issuePath(issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	var attachments []apiAttachment
+	_, err = accessor.apiRequest("GET", issuePath+"/assets", "", nil, &attachments)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving attachments for issue %d", issueID)
+		return nil, err
+	}
+
+	for _, attachment := range attachments {
+		if attachment.Name == fileName {
+			return &attachment, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// GetIssueAttachmentUUID returns the UUID for a named attachment of a given issue - returns empty string if cannot find issue/attachment.
+func (accessor *APIAccessor) GetIssueAttachmentUUID(issueID int64, fileName string) (string, error) {
+	attachment, err := accessor.getIssueAttachment(issueID, fileName)
+	if err != nil {
+		return "", err
+	}
+	if attachment == nil {
+		return "", nil
+	}
+
+	return attachment.UUID, nil
+}
+
+// uploadIssueAttachment uploads a file as an attachment to a Gitea issue, returns id of created attachment.
+func (accessor *APIAccessor) uploadIssueAttachment(issueID int64, attachment *IssueAttachment, filePath string) (int64, error) {
+	issuePath, err := accessor.issuePath(issueID)
+	if err != nil {
+		return NullID, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		err = errors.Wrapf(err, "opening attachment file %s", filePath)
+		return NullID, err
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("attachment", attachment.FileName)
+	if err != nil {
+		err = errors.Wrapf(err, "creating upload of attachment %s for issue %d", attachment.FileName, issueID)
+		return NullID, err
+	}
+	if _, err = io.Copy(part, file); err != nil {
+		err = errors.Wrapf(err, "reading attachment file %s", filePath)
+		return NullID, err
+	}
+	if err = writer.Close(); err != nil {
+		err = errors.Wrapf(err, "creating upload of attachment %s for issue %d", attachment.FileName, issueID)
+		return NullID, err
+	}
+
+	request, err := http.NewRequest("POST", accessor.apiURL(issuePath+"/assets?name="+url.QueryEscape(attachment.FileName)), &body)
+	if err != nil {
+		err = errors.Wrapf(err, "creating upload of attachment %s for issue %d", attachment.FileName, issueID)
+		return NullID, err
+	}
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	var createdAttachment apiAttachment
+	_, err = accessor.performRequest(request, "", &createdAttachment)
+	if err != nil {
+		err = errors.Wrapf(err, "adding attachment %s for issue %d", attachment.FileName, issueID)
+		return NullID, err
+	}
+
+	log.Debug("added attachment %s for issue %d (id %d)", attachment.FileName, issueID, createdAttachment.ID)
+
+	return createdAttachment.ID, nil
+}
+
+// AddIssueAttachment adds a new attachment to an issue using the provided file - returns id of created attachment
+// Gitea allocates the UUIDs of attachments uploaded through the API so the UUID of the provided attachment is ignored.
+func (accessor *APIAccessor) AddIssueAttachment(issueID int64, attachment *IssueAttachment, filePath string) (int64, error) {
+	existingAttachment, err := accessor.getIssueAttachment(issueID, attachment.FileName)
+	if err != nil {
+		return NullID, err
+	}
+
+	if existingAttachment != nil {
+		if !accessor.overwrite {
+			log.Debug("issue %d already has attachment %s - ignored", issueID, attachment.FileName)
+			return existingAttachment.ID, nil
+		}
+
+		issuePath, err := accessor.issuePath(issueID)
+		if err != nil {
+			return NullID, err
+		}
+		_, err = accessor.apiRequest("DELETE", fmt.Sprintf("%s/assets/%d", issuePath, existingAttachment.ID), "", nil, nil)
+		if err != nil {
+			err = errors.Wrapf(err, "deleting attachment %s for issue %d", attachment.FileName, issueID)
+			return NullID, err
+		}
+	}
+
+	return accessor.uploadIssueAttachment(issueID, attachment, filePath)
+}
+
+// GetIssueAttachmentURL retrieves the URL for viewing a Gitea attachment
+func (accessor *APIAccessor) GetIssueAttachmentURL(issueID int64, uuid string) string {
+	baseURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/attachments/%s", baseURL, uuid)
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// apiComment describes an issue comment as returned by the Gitea API.
+type apiComment struct {
+	ID      int64     `json:"id"`
+	Body    string    `json:"body"`
+	Created time.Time `json:"created_at"`
+}
+
+// getIssueComments retrieves all comments on a Gitea issue.
+func (accessor *APIAccessor) getIssueComments(issueID int64) ([]apiComment, error) {
+	issuePath, err := accessor.issuePath(issueID)
+	if err != nil {
+		return nil, err
+	}
+
+	issueComments := []apiComment{}
+	seenCommentIDs := make(map[int64]bool)
+	for page := 1; ; page++ {
+		var pageComments []apiComment
+		_, err = accessor.apiRequest("GET", pagedPath(issuePath+"/comments", page), "", nil, &pageComments)
+		if err != nil {
+			err = errors.Wrapf(err, "retrieving comments for issue %d", issueID)
+			return nil, err
+		}
+
+		// older Gitea versions ignore the paging parameters and return all comments on every page
+		newComments := 0
+		for _, issueComment := range pageComments {
+			if !seenCommentIDs[issueComment.ID] {
+				seenCommentIDs[issueComment.ID] = true
+				issueComments = append(issueComments, issueComment)
+				newComments++
+			}
+		}
+		if newComments == 0 || len(pageComments) < apiPageSize {
+			return issueComments, nil
+		}
+	}
+}
+
+// GetIssueCommentIDsByTime retrieves the IDs of all comments created at a given time for a given issue
+// Gitea stamps comments created through the API with the time of their creation so we cannot look for the original time on the server:
+// instead we can only find comments which have been added (or matched against existing comments) by this import.
+func (accessor *APIAccessor) GetIssueCommentIDsByTime(issueID int64, createdTime int64) ([]int64, error) {
+	var issueCommentIDs = []int64{}
+	issueCommentIDs = append(issueCommentIDs, accessor.commentIDsByTime[issueID][createdTime]...)
+	return issueCommentIDs, nil
+}
+
+// recordIssueCommentTime records the original time of a comment added by this import.
+func (accessor *APIAccessor) recordIssueCommentTime(issueID int64, issueCommentID int64, createdTime int64) {
+	if accessor.commentIDsByTime[issueID] == nil {
+		accessor.commentIDsByTime[issueID] = make(map[int64][]int64)
+	}
+	accessor.commentIDsByTime[issueID][createdTime] = append(accessor.commentIDsByTime[issueID][createdTime], issueCommentID)
+}
+
+// nextExistingIssueCommentID returns the id of the next existing comment on an issue which existed prior to this import - returns NullID if no such comment.
+func (accessor *APIAccessor) nextExistingIssueCommentID(issueID int64) (int64, error) {
+	if !accessor.existingIssueIDs[issueID] {
+		return NullID, nil
+	}
+
+	// HACK:
+	// Gitea sets the timestamp of comments created through the API so, unlike the database accessor, we cannot use timestamps
+	// to identify whether a comment already exists. Instead we rely on comments always being added in the same order for a given issue
+	// and match each comment being added to the corresponding existing comment.
+	if issueID != accessor.commentsIssueID {
+		accessor.commentsIssueID = issueID
+		accessor.existingCommentIndex = 0
+		accessor.existingCommentIDs = []int64{}
+		issueComments, err := accessor.getIssueComments(issueID)
+		if err != nil {
+			return NullID, err
+		}
+		for _, issueComment := range issueComments {
+			accessor.existingCommentIDs = append(accessor.existingCommentIDs, issueComment.ID)
+		}
+	}
+
+	if accessor.existingCommentIndex >= len(accessor.existingCommentIDs) {
+		return NullID, nil
+	}
+
+	issueCommentID := accessor.existingCommentIDs[accessor.existingCommentIndex]
+	accessor.existingCommentIndex++
+	return issueCommentID, nil
+}
+
+// addTextIssueComment adds a text comment to a Gitea issue, returns id of created comment.
+func (accessor *APIAccessor) addTextIssueComment(issueID int64, comment *IssueComment) (int64, error) {
+	sudoUser := accessor.sudoUser(comment.AuthorID)
+	commentData := struct {
+		Body string `json:"body"`
+	}{Body: accessor.attributedText(comment.Text, sudoUser, comment.AuthorID, comment.OriginalAuthorName, comment.Time)}
+
+	issueCommentID, err := accessor.nextExistingIssueCommentID(issueID)
+	if err != nil {
+		return NullID, err
+	}
+	if issueCommentID != NullID {
+		accessor.recordIssueCommentTime(issueID, issueCommentID, comment.Time)
+		if !accessor.overwrite {
+			log.Debug("issue %d already has comment %d - ignored", issueID, issueCommentID)
+			return issueCommentID, nil
+		}
+
+		_, err = accessor.apiRequest("PATCH", fmt.Sprintf("%s/issues/comments/%d", accessor.repoPath(), issueCommentID), "", &commentData, nil)
+		if err != nil {
+			err = errors.Wrapf(err, "updating comment %d on issue %d", issueCommentID, issueID)
+			return NullID, err
+		}
+
+		log.Debug("updated issue comment for issue %d (id %d)", issueID, issueCommentID)
+		return issueCommentID, nil
+	}
+
+	issuePath, err := accessor.issuePath(issueID)
+	if err != nil {
+		return NullID, err
+	}
+	var createdComment apiComment
+	_, err = accessor.apiRequest("POST", issuePath+"/comments", sudoUser, &commentData, &createdComment)
+	if err != nil {
+		err = errors.Wrapf(err, "adding comment \"%s\" for issue %d", comment.Text, issueID)
+		return NullID, err
+	}
+
+	log.Debug("added issue comment originally timed at %s for issue %d (id %d)", time.Unix(comment.Time, 0), issueID, createdComment.ID)
+	accessor.recordIssueCommentTime(issueID, createdComment.ID, comment.Time)
+
+	return createdComment.ID, nil
+}
+
+// AddIssueComment adds a comment on a Gitea issue, returns id of created comment
+// Other than text comments, the comments created by Gitea for issue changes cannot be created directly through the API.
+// We instead make the change itself, which causes Gitea to create the comment.
+// Such changes are only made for issues created by this import - for existing issues, the issue itself will have been updated to its final state if required.
+func (accessor *APIAccessor) AddIssueComment(issueID int64, comment *IssueComment) (int64, error) {
+	if comment.CommentType == CommentIssueCommentType {
+		return accessor.addTextIssueComment(issueID, comment)
+	}
+
+	if accessor.existingIssueIDs[issueID] {
+		log.Debug("issue %d already exists - ignoring change of type %d", issueID, comment.CommentType)
+		return NullID, nil
+	}
+
+	var err error
+	sudoUser := accessor.sudoUser(comment.AuthorID)
+	switch comment.CommentType {
+	case CloseIssueCommentType, ReopenIssueCommentType:
+		state := issueState(comment.CommentType == CloseIssueCommentType)
+		err = accessor.editIssue(issueID, sudoUser, &apiEditIssue{State: &state})
+	case LabelIssueCommentType:
+		err = accessor.changeIssueLabel(issueID, comment.LabelID, comment.Text == "1", sudoUser)
+	case MilestoneIssueCommentType:
+		milestoneID := comment.MilestoneID
+		err = accessor.editIssue(issueID, sudoUser, &apiEditIssue{Milestone: &milestoneID})
+	case AssigneeIssueCommentType:
+		if comment.RemovedAssigneeID != NullID {
+			err = accessor.removeIssueAssignee(issueID, comment.RemovedAssigneeID, sudoUser)
+		}
+		if err == nil && comment.AssigneeID != NullID {
+			err = accessor.AddIssueAssignee(issueID, comment.AssigneeID)
+		}
+	case TitleIssueCommentType:
+		title := comment.Title
+		err = accessor.editIssue(issueID, sudoUser, &apiEditIssue{Title: &title})
+	default:
+		log.Warn("cannot create issue comment of type %d through the Gitea API - ignored", comment.CommentType)
+	}
+	if err != nil {
+		return NullID, err
+	}
+
+	// changes made through the API do not return the id of the comment created by Gitea
+	return NullID, nil
+}
+
+// GetIssueCommentURL retrieves the URL for viewing a Gitea comment for a given issue.
+func (accessor *APIAccessor) GetIssueCommentURL(issueID int64, commentID int64) string {
+	repoURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/issues/%d#issuecomment-%d", repoURL, accessor.issueIndexesByID[issueID], commentID)
+}
+
+// AddIssueContentHistory adds an entry to the edit history of the content of a Gitea issue or issue comment.
+// The Gitea API provides no means of recording historical edits so this is a no-op.
+func (accessor *APIAccessor) AddIssueContentHistory(issueID int64, history *IssueContentHistory) (int64, error) {
+	if !accessor.historyWarned {
+		log.Warn("edit history of issues and comments cannot be recorded through the Gitea API - only the latest content will be imported")
+		accessor.historyWarned = true
+	}
+	log.Debug("content history of issue %d, comment %d cannot be recorded through the Gitea API - ignored", issueID, history.CommentID)
+	return NullID, nil
+}
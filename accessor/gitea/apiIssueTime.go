@@ -0,0 +1,55 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// apiAddTime describes tracked time as passed to the Gitea API.
+type apiAddTime struct {
+	Time     int64     `json:"time"`
+	Created  time.Time `json:"created"`
+	UserName string    `json:"user_name,omitempty"`
+}
+
+// AddIssueTrackedTime records time tracked against a Gitea issue.
+// Time is only recorded against issues created by this import since the API provides no means of identifying time already tracked.
+func (accessor *APIAccessor) AddIssueTrackedTime(issueID int64, trackedTime *TrackedTime) error {
+	if accessor.existingIssueIDs[issueID] {
+		log.Debug("issue %d already exists - ignoring tracked time", issueID)
+		return nil
+	}
+
+	userName := accessor.userNamesByID[trackedTime.UserID]
+	if userName == "" {
+		return fmt.Errorf("unknown user id %d for tracked time on issue %d", trackedTime.UserID, issueID)
+	}
+
+	issuePath, err := accessor.issuePath(issueID)
+	if err != nil {
+		return err
+	}
+
+	timeData := apiAddTime{Time: trackedTime.Seconds, Created: time.Unix(trackedTime.Time, 0), UserName: userName}
+	_, err = accessor.apiRequest("POST", issuePath+"/times", "", &timeData, nil)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("added %ds time tracked by user %d on issue %d", trackedTime.Seconds, trackedTime.UserID, issueID)
+
+	return nil
+}
+
+// SetIssueTimeEstimate sets the estimated time (in seconds) for a Gitea issue.
+// The Gitea API provides no means of setting an estimate so this is a no-op.
+func (accessor *APIAccessor) SetIssueTimeEstimate(issueID int64, seconds int64) error {
+	log.Warn("time estimates cannot be set through the Gitea API - estimate for issue %d ignored", issueID)
+	return nil
+}
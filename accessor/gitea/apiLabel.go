@@ -0,0 +1,77 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// apiLabel describes a label as passed to/returned by the Gitea API.
+type apiLabel struct {
+	ID          int64  `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Color       string `json:"color"`
+	Exclusive   bool   `json:"exclusive"`
+}
+
+// GetLabelID retrieves the id of the given label, returns NullID if no such label
+func (accessor *APIAccessor) GetLabelID(labelName string) (int64, error) {
+	for page := 1; ; page++ {
+		var labels []apiLabel
+		_, err := accessor.apiRequest("GET", pagedPath(accessor.repoPath()+"/labels", page), "", nil, &labels)
+		if err != nil {
+			err = errors.Wrapf(err, "retrieving id of label %s", labelName)
+			return NullID, err
+		}
+
+		for _, label := range labels {
+			if label.Name == labelName {
+				return label.ID, nil
+			}
+		}
+		if len(labels) < apiPageSize {
+			return NullID, nil
+		}
+	}
+}
+
+// AddLabel adds a label to Gitea, returns label id.
+func (accessor *APIAccessor) AddLabel(label *Label) (int64, error) {
+	labelID, err := accessor.GetLabelID(label.Name)
+	if err != nil {
+		return NullID, err
+	}
+
+	labelData := apiLabel{Name: label.Name, Description: label.Description, Color: label.Color, Exclusive: label.Exclusive}
+	if labelID == NullID {
+		var createdLabel apiLabel
+		_, err = accessor.apiRequest("POST", accessor.repoPath()+"/labels", "", &labelData, &createdLabel)
+		if err != nil {
+			err = errors.Wrapf(err, "adding label %s", label.Name)
+			return NullID, err
+		}
+
+		log.Debug("added label %s, color %s (id %d)", label.Name, label.Color, createdLabel.ID)
+		return createdLabel.ID, nil
+	}
+
+	if accessor.overwrite {
+		_, err = accessor.apiRequest("PATCH", fmt.Sprintf("%s/labels/%d", accessor.repoPath(), labelID), "", &labelData, nil)
+		if err != nil {
+			err = errors.Wrapf(err, "updating label %s", label.Name)
+			return NullID, err
+		}
+
+		log.Debug("updated label %s, color %s (id %d)", label.Name, label.Color, labelID)
+	} else {
+		log.Debug("label %s already exists - ignored", label.Name)
+	}
+
+	return labelID, nil
+}
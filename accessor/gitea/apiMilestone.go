@@ -0,0 +1,112 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// apiMilestone describes a milestone as passed to/returned by the Gitea API.
+type apiMilestone struct {
+	ID          int64      `json:"id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	State       string     `json:"state,omitempty"`
+	DueOn       *time.Time `json:"due_on,omitempty"`
+}
+
+// GetMilestoneID gets the ID of a named milestone - returns NullID if no such milestone
+func (accessor *APIAccessor) GetMilestoneID(milestoneName string) (int64, error) {
+	if milestoneName == "" {
+		return NullID, nil
+	}
+
+	for page := 1; ; page++ {
+		var milestones []apiMilestone
+		_, err := accessor.apiRequest("GET", pagedPath(accessor.repoPath()+"/milestones?state=all", page), "", nil, &milestones)
+		if err != nil {
+			err = errors.Wrapf(err, "retrieving id of milestone %s", milestoneName)
+			return NullID, err
+		}
+
+		for _, milestone := range milestones {
+			if milestone.Title == milestoneName {
+				return milestone.ID, nil
+			}
+		}
+		if len(milestones) < apiPageSize {
+			return NullID, nil
+		}
+	}
+}
+
+// AddMilestone adds a milestone to Gitea, returns id of created milestone
+func (accessor *APIAccessor) AddMilestone(milestone *Milestone) (int64, error) {
+	milestoneID, err := accessor.GetMilestoneID(milestone.Name)
+	if err != nil {
+		return NullID, err
+	}
+
+	milestoneData := apiMilestone{Title: milestone.Name, Description: milestone.Description, State: "open"}
+	if milestone.Closed {
+		milestoneData.State = "closed"
+	}
+	if milestone.DueTime != 0 {
+		dueTime := time.Unix(milestone.DueTime, 0)
+		milestoneData.DueOn = &dueTime
+	}
+
+	if milestoneID == NullID {
+		var createdMilestone apiMilestone
+		_, err = accessor.apiRequest("POST", accessor.repoPath()+"/milestones", "", &milestoneData, &createdMilestone)
+		if err != nil {
+			err = errors.Wrapf(err, "adding milestone %s", milestone.Name)
+			return NullID, err
+		}
+		milestoneID = createdMilestone.ID
+
+		// milestones can only be closed once created
+		if milestone.Closed {
+			_, err = accessor.apiRequest("PATCH", fmt.Sprintf("%s/milestones/%d", accessor.repoPath(), milestoneID), "", &milestoneData, nil)
+			if err != nil {
+				err = errors.Wrapf(err, "closing milestone %s", milestone.Name)
+				return NullID, err
+			}
+		}
+
+		log.Debug("added milestone %s (id %d)", milestone.Name, milestoneID)
+		return milestoneID, nil
+	}
+
+	if accessor.overwrite {
+		_, err = accessor.apiRequest("PATCH", fmt.Sprintf("%s/milestones/%d", accessor.repoPath(), milestoneID), "", &milestoneData, nil)
+		if err != nil {
+			err = errors.Wrapf(err, "updating milestone %s", milestone.Name)
+			return NullID, err
+		}
+
+		log.Debug("updated milestone %s (id %d)", milestone.Name, milestoneID)
+	} else {
+		log.Debug("milestone %s already exists - ignored", milestone.Name)
+	}
+
+	return milestoneID, nil
+}
+
+// GetMilestoneURL gets the URL for accessing a given milestone
+func (accessor *APIAccessor) GetMilestoneURL(milestoneID int64) string {
+	repoURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/milestone/%d", repoURL, milestoneID)
+}
+
+// UpdateMilestoneIssueCounts updates issue counts for all milestones.
+// Gitea maintains these counts itself for changes made through the API.
+func (accessor *APIAccessor) UpdateMilestoneIssueCounts() error {
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// apiRelease describes a release as passed to/returned by the Gitea API.
+type apiRelease struct {
+	ID      int64  `json:"id,omitempty"`
+	TagName string `json:"tag_name"`
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+	Draft   bool   `json:"draft"`
+}
+
+// HasTag returns true if our chosen Gitea repository has a git tag of the given name.
+func (accessor *APIAccessor) HasTag(tagName string) (bool, error) {
+	status, err := accessor.apiRequest("GET", accessor.repoPath()+"/tags/"+url.PathEscape(tagName), "", nil, nil)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving git tag %s", tagName)
+		return false, err
+	}
+
+	return status != http.StatusNotFound, nil
+}
+
+// getReleaseID retrieves the id of the release with the given tag name - returns NullID if no such release.
+func (accessor *APIAccessor) getReleaseID(tagName string) (int64, error) {
+	for page := 1; ; page++ {
+		var releases []apiRelease
+		_, err := accessor.apiRequest("GET", pagedPath(accessor.repoPath()+"/releases", page), "", nil, &releases)
+		if err != nil {
+			err = errors.Wrapf(err, "retrieving id of release %s", tagName)
+			return NullID, err
+		}
+
+		for _, release := range releases {
+			if release.TagName == tagName {
+				return release.ID, nil
+			}
+		}
+		if len(releases) < apiPageSize {
+			return NullID, nil
+		}
+	}
+}
+
+// AddRelease adds a release to Gitea, returns id of created release.
+// The Gitea API provides no means of setting the creation time of a release so releases are dated at the time of import.
+func (accessor *APIAccessor) AddRelease(release *Release) (int64, error) {
+	releaseID, err := accessor.getReleaseID(release.TagName)
+	if err != nil {
+		return NullID, err
+	}
+
+	sudoUser := accessor.sudoUser(release.PublisherID)
+	releaseData := apiRelease{TagName: release.TagName, Name: release.Title, Body: release.Description, Draft: release.IsDraft}
+	if releaseID == NullID {
+		var createdRelease apiRelease
+		_, err = accessor.apiRequest("POST", accessor.repoPath()+"/releases", sudoUser, &releaseData, &createdRelease)
+		if err != nil {
+			err = errors.Wrapf(err, "adding release %s", release.TagName)
+			return NullID, err
+		}
+
+		log.Debug("added release %s (id %d)", release.TagName, createdRelease.ID)
+		return createdRelease.ID, nil
+	}
+
+	if accessor.overwrite {
+		_, err = accessor.apiRequest("PATCH", fmt.Sprintf("%s/releases/%d", accessor.repoPath(), releaseID), sudoUser, &releaseData, nil)
+		if err != nil {
+			err = errors.Wrapf(err, "updating release %s", release.TagName)
+			return NullID, err
+		}
+
+		log.Debug("updated release %s (id %d)", release.TagName, releaseID)
+	} else {
+		log.Debug("release %s already exists - ignored", release.TagName)
+	}
+
+	return releaseID, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// apiTeam describes a team as passed to and returned by the Gitea API.
+type apiTeam struct {
+	ID                      int64    `json:"id,omitempty"`
+	Name                    string   `json:"name"`
+	Description             string   `json:"description"`
+	Permission              string   `json:"permission"`
+	Units                   []string `json:"units"`
+	IncludesAllRepositories bool     `json:"includes_all_repositories"`
+	CanCreateOrgRepo        bool     `json:"can_create_org_repo"`
+}
+
+// Gitea repository units to which imported teams are given access
+var apiTeamUnits = []string{"repo.code", "repo.issues", "repo.pulls", "repo.releases", "repo.wiki"}
+
+// getTeamID retrieves the id of a named team within the organization owning our repository, returns NullID if no such team
+func (accessor *APIAccessor) getTeamID(teamName string) (int64, error) {
+	var searchResult struct {
+		Data []apiTeam `json:"data"`
+	}
+	_, err := accessor.apiRequest("GET",
+		fmt.Sprintf("/orgs/%s/teams/search?q=%s&limit=%d", url.PathEscape(accessor.userName), url.QueryEscape(teamName), apiPageSize), "", nil, &searchResult)
+	if err != nil {
+		err = errors.Wrapf(err, "searching for team %s", teamName)
+		return NullID, err
+	}
+
+	for _, team := range searchResult.Data {
+		if strings.EqualFold(team.Name, teamName) {
+			return team.ID, nil
+		}
+	}
+
+	return NullID, nil
+}
+
+// AddTeam adds a team to the organization owning our repository, or finds an existing team of the same name, and gives it access to the repository.
+// Returns the id of the team.
+func (accessor *APIAccessor) AddTeam(team *Team) (int64, error) {
+	teamID, err := accessor.getTeamID(team.Name)
+	if err != nil {
+		return NullID, err
+	}
+
+	teamData := apiTeam{
+		Name:                    team.Name,
+		Description:             team.Description,
+		Permission:              apiPermissionName(team.Access),
+		Units:                   apiTeamUnits,
+		IncludesAllRepositories: false,
+		CanCreateOrgRepo:        false}
+	if teamID == NullID {
+		var createdTeam apiTeam
+		_, err = accessor.apiRequest("POST", "/orgs/"+url.PathEscape(accessor.userName)+"/teams", "", &teamData, &createdTeam)
+		if err != nil {
+			err = errors.Wrapf(err, "adding team %s", team.Name)
+			return NullID, err
+		}
+		teamID = createdTeam.ID
+		log.Debug("added team %s (id %d)", team.Name, teamID)
+	} else if accessor.overwrite {
+		_, err = accessor.apiRequest("PATCH", fmt.Sprintf("/teams/%d", teamID), "", &teamData, nil)
+		if err != nil {
+			err = errors.Wrapf(err, "updating team %s", team.Name)
+			return NullID, err
+		}
+		log.Debug("updated team %s (id %d)", team.Name, teamID)
+	}
+
+	_, err = accessor.apiRequest("PUT", fmt.Sprintf("/teams/%d/repos/%s/%s", teamID, url.PathEscape(accessor.userName), url.PathEscape(accessor.repoName)), "", nil, nil)
+	if err != nil {
+		err = errors.Wrapf(err, "giving team %s access to repository %s", team.Name, accessor.repoName)
+		return NullID, err
+	}
+
+	return teamID, nil
+}
+
+// AddTeamMember adds a user to a team of the organization owning our repository.
+func (accessor *APIAccessor) AddTeamMember(teamID int64, userID int64) error {
+	userName := accessor.userNamesByID[userID]
+	if userName == "" {
+		return fmt.Errorf("unknown member id %d for team %d", userID, teamID)
+	}
+
+	_, err := accessor.apiRequest("PUT", fmt.Sprintf("/teams/%d/members/%s", teamID, url.PathEscape(userName)), "", nil, nil)
+	if err != nil {
+		err = errors.Wrapf(err, "adding user %s to team %d", userName, teamID)
+		return err
+	}
+
+	log.Debug("added user %s to team %d", userName, teamID)
+
+	return nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// getUser retrieves a named Gitea user - returns nil if no such user.
+func (accessor *APIAccessor) getUser(userName string) (*apiUser, error) {
+	var user apiUser
+	status, err := accessor.apiRequest("GET", "/users/"+url.PathEscape(userName), "", nil, &user)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving user %s", userName)
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+
+	accessor.userNamesByID[user.ID] = user.Login
+	return &user, nil
+}
+
+// searchUsers retrieves the Gitea users matching a search string.
+func (accessor *APIAccessor) searchUsers(searchString string) ([]apiUser, error) {
+	var searchResult struct {
+		Data []apiUser `json:"data"`
+	}
+	_, err := accessor.apiRequest("GET", fmt.Sprintf("/users/search?q=%s&limit=%d", url.QueryEscape(searchString), apiPageSize), "", nil, &searchResult)
+	if err != nil {
+		err = errors.Wrapf(err, "searching for users matching %s", searchString)
+		return nil, err
+	}
+
+	for _, user := range searchResult.Data {
+		accessor.userNamesByID[user.ID] = user.Login
+	}
+	return searchResult.Data, nil
+}
+
+// GetUserID retrieves the id of a named Gitea user - returns NullID if no such user.
+func (accessor *APIAccessor) GetUserID(userName string) (int64, error) {
+	if strings.Trim(userName, " ") == "" {
+		return NullID, nil
+	}
+
+	user, err := accessor.getUser(userName)
+	if err != nil {
+		return NullID, err
+	}
+	if user != nil {
+		return user.ID, nil
+	}
+
+	// as with the database accessor, allow users to be identified by email address
+	if strings.Contains(userName, "@") {
+		users, err := accessor.searchUsers(userName)
+		if err != nil {
+			return NullID, err
+		}
+		for _, user := range users {
+			if strings.EqualFold(user.Email, userName) {
+				return user.ID, nil
+			}
+		}
+	}
+
+	return NullID, nil
+}
+
+// GetUserEMailAddress retrieves the email address of a given user
+func (accessor *APIAccessor) GetUserEMailAddress(userName string) (string, error) {
+	user, err := accessor.getUser(userName)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", nil
+	}
+
+	return user.Email, nil
+}
+
+// MatchUser retrieves the name of the user best matching a user name or email address
+func (accessor *APIAccessor) MatchUser(userName string, userEmail string) (string, error) {
+	lcUserName := strings.ToLower(userName)
+	searchStrings := []string{userName}
+	if userEmail != "" {
+		searchStrings = append(searchStrings, userEmail)
+	}
+
+	for _, searchString := range searchStrings {
+		if searchString == "" {
+			continue
+		}
+
+		users, err := accessor.searchUsers(searchString)
+		if err != nil {
+			return "", err
+		}
+		for _, user := range users {
+			if strings.ToLower(user.Login) == lcUserName ||
+				(user.FullName != "" && user.FullName == userName) ||
+				(userEmail != "" && user.Email == userEmail) {
+				return strings.ToLower(user.Login), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// GetUsers retrieves all Gitea users (excluding organizations), passing each one to the provided "handler" function.
+func (accessor *APIAccessor) GetUsers(handlerFn func(user *User) error) error {
+	for page := 1; ; page++ {
+		var searchResult struct {
+			Data []apiUser `json:"data"`
+		}
+		_, err := accessor.apiRequest("GET", pagedPath("/users/search?q=", page), "", nil, &searchResult)
+		if err != nil {
+			err = errors.Wrapf(err, "retrieving users")
+			return err
+		}
+
+		for _, apiUser := range searchResult.Data {
+			accessor.userNamesByID[apiUser.ID] = apiUser.Login
+			user := User{Name: apiUser.Login, FullName: apiUser.FullName, Email: apiUser.Email}
+			if err = handlerFn(&user); err != nil {
+				return err
+			}
+		}
+		if len(searchResult.Data) < apiPageSize {
+			return nil
+		}
+	}
+}
+
+// GetExternalUserID retrieves the id of the Gitea user who has linked the account with a given external id from a given login source
+// The Gitea API provides no access to linked external accounts so no such user is ever found.
+func (accessor *APIAccessor) GetExternalUserID(loginSourceID int64, externalID string) (int64, error) {
+	return NullID, nil
+}
+
+// GetLoginSourceServiceType retrieves the type of the git service after which a given login source is named
+// The Gitea API provides no access to login sources so no service type is ever found.
+func (accessor *APIAccessor) GetLoginSourceServiceType(loginSourceID int64) (int64, error) {
+	return 0, nil
+}
+
+// randomPassword returns a random password for a user who will not be allowed to log in.
+func randomPassword() (string, error) {
+	passwordBytes := make([]byte, 32)
+	if _, err := rand.Read(passwordBytes); err != nil {
+		err = errors.Wrapf(err, "generating random password")
+		return "", err
+	}
+
+	return hex.EncodeToString(passwordBytes), nil
+}
+
+// AddPlaceholderUser creates a restricted Gitea user who cannot log in, to stand in for a Trac user with no Gitea equivalent.
+// A no-reply email address is used if the user has none. Returns the id of the created user.
+// Creating users requires an administrator's token.
+func (accessor *APIAccessor) AddPlaceholderUser(user *User) (int64, error) {
+	email := user.Email
+	if email == "" {
+		serverURL, err := url.Parse(accessor.serverURL)
+		if err != nil {
+			err = errors.Wrapf(err, "parsing Gitea server URL %s", accessor.serverURL)
+			return NullID, err
+		}
+		email = strings.ToLower(user.Name) + "@noreply." + serverURL.Hostname()
+	}
+
+	password, err := randomPassword()
+	if err != nil {
+		return NullID, err
+	}
+
+	userData := struct {
+		UserName           string `json:"username"`
+		FullName           string `json:"full_name"`
+		Email              string `json:"email"`
+		Password           string `json:"password"`
+		MustChangePassword bool   `json:"must_change_password"`
+		Restricted         bool   `json:"restricted"`
+		SendNotify         bool   `json:"send_notify"`
+	}{UserName: user.Name, FullName: user.FullName, Email: email, Password: password, MustChangePassword: false, Restricted: true, SendNotify: false}
+	var createdUser apiUser
+	_, err = accessor.apiRequest("POST", "/admin/users", "", &userData, &createdUser)
+	if err != nil {
+		err = errors.Wrapf(err, "adding placeholder user %s", user.Name)
+		return NullID, err
+	}
+
+	editData := struct {
+		LoginName     string `json:"login_name"`
+		SourceID      int64  `json:"source_id"`
+		ProhibitLogin bool   `json:"prohibit_login"`
+	}{LoginName: user.Name, SourceID: 0, ProhibitLogin: true}
+	_, err = accessor.apiRequest("PATCH", "/admin/users/"+url.PathEscape(createdUser.Login), "", &editData, nil)
+	if err != nil {
+		err = errors.Wrapf(err, "prohibiting login by placeholder user %s", user.Name)
+		return NullID, err
+	}
+
+	accessor.userNamesByID[createdUser.ID] = createdUser.Login
+	log.Debug("added placeholder user %s (id %d)", createdUser.Login, createdUser.ID)
+
+	return createdUser.ID, nil
+}
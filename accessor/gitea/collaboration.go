@@ -0,0 +1,138 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// Gitea user type for organizations
+const organizationUserType = 1
+
+// getRepoOwner retrieves the id of the owner of our repository and whether that owner is an organization.
+func (accessor *DefaultAccessor) getRepoOwner() (int64, bool, error) {
+	var ownerID = NullID
+	var ownerType int
+	err := accessor.queryRow(`
+		SELECT u.id, u.type FROM repository r, `+accessor.dialect.quoteIdentifier("user")+` u
+		WHERE r.owner_id = u.id AND r.id = $1`, accessor.repoID).Scan(&ownerID, &ownerType)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving owner of repository %d", accessor.repoID)
+		return NullID, false, err
+	}
+
+	return ownerID, ownerType == organizationUserType, nil
+}
+
+// IsRepoOwnedByOrganization returns true if our chosen Gitea repository is owned by an organization rather than a user.
+func (accessor *DefaultAccessor) IsRepoOwnedByOrganization() (bool, error) {
+	_, isOrganization, err := accessor.getRepoOwner()
+	return isOrganization, err
+}
+
+// getAccess retrieves the id and mode of a user's access to our repository, returns NullID if user has no recorded access
+func (accessor *DefaultAccessor) getAccess(userID int64) (int64, AccessMode, error) {
+	var accessID = NullID
+	var mode AccessMode = NoAccess
+	err := accessor.queryRow(`
+		SELECT id, mode FROM access WHERE user_id = $1 AND repo_id = $2
+		`, userID, accessor.repoID).Scan(&accessID, &mode)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving access of user %d to repository %d", userID, accessor.repoID)
+		return NullID, NoAccess, err
+	}
+
+	return accessID, mode, nil
+}
+
+// grantAccess ensures that a user has at least the given access to our repository.
+// Gitea determines a user's permissions from this table so it must be kept in step with collaborations and team memberships.
+func (accessor *DefaultAccessor) grantAccess(userID int64, access AccessMode) error {
+	accessID, mode, err := accessor.getAccess(userID)
+	if err != nil {
+		return err
+	}
+
+	if accessID == NullID {
+		_, err = accessor.exec(`INSERT INTO access(user_id, repo_id, mode) VALUES ($1, $2, $3)`, userID, accessor.repoID, access)
+	} else if mode < access {
+		_, err = accessor.exec(`UPDATE access SET mode=$1 WHERE id=$2`, access, accessID)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "granting access %d to repository %d for user %d", access, accessor.repoID, userID)
+		return err
+	}
+
+	return nil
+}
+
+// getCollaborationID retrieves the id of a user's collaboration on our repository, returns NullID if user is not a collaborator
+func (accessor *DefaultAccessor) getCollaborationID(userID int64) (int64, error) {
+	var collaborationID = NullID
+	err := accessor.queryRow(`
+		SELECT id FROM collaboration WHERE repo_id = $1 AND user_id = $2
+		`, accessor.repoID, userID).Scan(&collaborationID)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving id of collaboration by user %d on repository %d", userID, accessor.repoID)
+		return NullID, err
+	}
+
+	return collaborationID, nil
+}
+
+// updateCollaboration updates an existing collaboration
+func (accessor *DefaultAccessor) updateCollaboration(collaborationID int64, userID int64, access AccessMode) error {
+	_, err := accessor.exec(`UPDATE collaboration SET mode=$1, updated_unix=$2 WHERE id=$3`,
+		access, time.Now().Unix(), collaborationID)
+	if err != nil {
+		err = errors.Wrapf(err, "updating collaboration by user %d on repository %d", userID, accessor.repoID)
+		return err
+	}
+
+	log.Debug("updated collaboration by user %d on repository %d (id %d) to access %d", userID, accessor.repoID, collaborationID, access)
+
+	return nil
+}
+
+// insertCollaboration creates a new collaboration
+func (accessor *DefaultAccessor) insertCollaboration(userID int64, access AccessMode) error {
+	_, err := accessor.exec(`
+		INSERT INTO collaboration(repo_id, user_id, mode, created_unix, updated_unix) VALUES ($1, $2, $3, $4, $4)`,
+		accessor.repoID, userID, access, time.Now().Unix())
+	if err != nil {
+		err = errors.Wrapf(err, "adding user %d as collaborator on repository %d", userID, accessor.repoID)
+		return err
+	}
+
+	log.Debug("added user %d as collaborator on repository %d with access %d", userID, accessor.repoID, access)
+
+	return nil
+}
+
+// AddRepoCollaborator adds a user as a collaborator on our chosen Gitea repository with the given access.
+func (accessor *DefaultAccessor) AddRepoCollaborator(userID int64, access AccessMode) error {
+	collaborationID, err := accessor.getCollaborationID(userID)
+	if err != nil {
+		return err
+	}
+
+	if collaborationID == NullID {
+		err = accessor.insertCollaboration(userID, access)
+	} else if accessor.overwrite {
+		err = accessor.updateCollaboration(collaborationID, userID, access)
+	} else {
+		log.Debug("user %d is already a collaborator on repository %d - ignored", userID, accessor.repoID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return accessor.grantAccess(userID, access)
+}
@@ -0,0 +1,134 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+
+	_ "github.com/lib/pq"           // postgres database driver
+	_ "github.com/mattn/go-sqlite3" // sqlite database driver
+)
+
+// splitHostPort splits a Gitea database HOST setting into a host and port, using the default port if none is specified.
+func splitHostPort(hostSetting string, defaultPort string) (string, string) {
+	host, port, err := net.SplitHostPort(hostSetting)
+	if err != nil {
+		// no port (or malformed) - use whole setting as host
+		return hostSetting, defaultPort
+	}
+	if port == "" {
+		port = defaultPort
+	}
+	return host, port
+}
+
+// postgresConnectionString returns the connection string for the postgres driver for the Gitea database settings.
+func (accessor *DefaultAccessor) postgresConnectionString() string {
+	params := url.Values{}
+	sslMode := accessor.GetStringConfig("database", "SSL_MODE")
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	params.Set("sslmode", sslMode)
+	if schema := accessor.GetStringConfig("database", "SCHEMA"); schema != "" {
+		params.Set("search_path", schema)
+	}
+
+	databaseURL := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(accessor.GetStringConfig("database", "USER"), accessor.GetStringConfig("database", "PASSWD")),
+		Path:   "/" + accessor.GetStringConfig("database", "NAME")}
+
+	hostSetting := accessor.GetStringConfig("database", "HOST")
+	if strings.HasPrefix(hostSetting, "/") {
+		// Unix socket directory
+		params.Set("host", hostSetting)
+	} else {
+		host, port := splitHostPort(hostSetting, "5432")
+		databaseURL.Host = net.JoinHostPort(host, port)
+	}
+	databaseURL.RawQuery = params.Encode()
+
+	return databaseURL.String()
+}
+
+// mysqlConnectionString returns the connection string (DSN) for the mysql driver for the Gitea database settings.
+func (accessor *DefaultAccessor) mysqlConnectionString() string {
+	config := mysql.NewConfig()
+	config.User = accessor.GetStringConfig("database", "USER")
+	config.Passwd = accessor.GetStringConfig("database", "PASSWD")
+	config.DBName = accessor.GetStringConfig("database", "NAME")
+
+	hostSetting := accessor.GetStringConfig("database", "HOST")
+	if strings.HasPrefix(hostSetting, "/") {
+		config.Net = "unix"
+		config.Addr = hostSetting
+	} else {
+		host, port := splitHostPort(hostSetting, "3306")
+		config.Net = "tcp"
+		config.Addr = net.JoinHostPort(host, port)
+	}
+
+	// Gitea uses 'true', 'false' or 'skip-verify' as the mysql SSL mode - as does the mysql driver
+	sslMode := accessor.GetStringConfig("database", "SSL_MODE")
+	if sslMode != "" && sslMode != "disable" {
+		config.TLSConfig = sslMode
+	}
+
+	charset := accessor.GetStringConfig("database", "CHARSET")
+	if charset == "" {
+		charset = "utf8mb4"
+	}
+	config.Params = map[string]string{"charset": charset}
+
+	return config.FormatDSN()
+}
+
+// openDatabase opens the Gitea database described by the '[database]' section of the Gitea config,
+// returning the database and the SQL dialect to use with it.
+func (accessor *DefaultAccessor) openDatabase() (*sql.DB, dialect, error) {
+	databaseType := accessor.GetStringConfig("database", "DB_TYPE")
+	switch databaseType {
+	case "", "sqlite3":
+		giteaDbPath := accessor.GetStringConfig("database", "PATH")
+		log.Info("using Gitea sqlite database %s", giteaDbPath)
+		giteaDb, err := sql.Open("sqlite3", giteaDbPath)
+		if err != nil {
+			err = errors.Wrapf(err, "opening sqlite database %s", giteaDbPath)
+			return nil, nil, err
+		}
+		return giteaDb, sqliteDialect{}, nil
+
+	case "postgres":
+		databaseName := accessor.GetStringConfig("database", "NAME")
+		log.Info("using Gitea postgres database %s on host %s", databaseName, accessor.GetStringConfig("database", "HOST"))
+		giteaDb, err := sql.Open("postgres", accessor.postgresConnectionString())
+		if err != nil {
+			err = errors.Wrapf(err, "opening postgres database %s", databaseName)
+			return nil, nil, err
+		}
+		return giteaDb, postgresDialect{}, nil
+
+	case "mysql":
+		databaseName := accessor.GetStringConfig("database", "NAME")
+		log.Info("using Gitea mysql database %s on host %s", databaseName, accessor.GetStringConfig("database", "HOST"))
+		giteaDb, err := sql.Open("mysql", accessor.mysqlConnectionString())
+		if err != nil {
+			err = errors.Wrapf(err, "opening mysql database %s", databaseName)
+			return nil, nil, err
+		}
+		return giteaDb, mysqlDialect{}, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported Gitea database type \"%s\"", databaseType)
+}
@@ -8,32 +8,24 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/pkg/errors"
-	"github.com/stevejefferson/trac2gitea/log"
 
 	"github.com/go-ini/ini"
-	_ "github.com/mattn/go-sqlite3" // sqlite database driver
-	"gopkg.in/src-d/go-git.v4"
 )
 
 // DefaultAccessor is the default implementation of the gitea Accessor interface, accessing Gitea directly via its database and filestore.
 type DefaultAccessor struct {
-	rootDir       string
-	mainConfig    *ini.File
-	customConfig  *ini.File
-	db            *sql.Tx
-	userName      string
-	repoName      string
-	repoID        int64
-	wikiRepoURL   string
-	wikiRepoToken string
-	wikiRepoDir   string
-	wikiRepo      *git.Repository
-	overwrite     bool
-	pushWiki      bool
+	rootDir      string
+	mainConfig   *ini.File
+	customConfig *ini.File
+	db           *sql.Tx
+	dialect      dialect
+	userName     string
+	repoName     string
+	repoID       int64
+	overwrite    bool
+	*wikiRepository
 }
 
 func fetchConfig(configPath string) (*ini.File, error) {
@@ -89,30 +81,24 @@ func CreateDefaultAccessor(
 	}
 
 	giteaAccessor := DefaultAccessor{
-		rootDir:       giteaRootDir,
-		mainConfig:    giteaMainConfig,
-		customConfig:  giteaCustomConfig,
-		db:            nil,
-		userName:      giteaUserName,
-		repoName:      giteaRepoName,
-		repoID:        0,
-		wikiRepoURL:   "",
-		wikiRepoToken: "",
-		wikiRepoDir:   "",
-		wikiRepo:      nil,
-		overwrite:     overwriteData,
-		pushWiki:      pushWiki}
-
-	// open gitea DB - currently sqlite-specific...
-	giteaDbPath := giteaAccessor.GetStringConfig("database", "PATH")
-	giteaDb, err := sql.Open("sqlite3", giteaDbPath)
+		rootDir:        giteaRootDir,
+		mainConfig:     giteaMainConfig,
+		customConfig:   giteaCustomConfig,
+		db:             nil,
+		dialect:        nil,
+		userName:       giteaUserName,
+		repoName:       giteaRepoName,
+		repoID:         0,
+		overwrite:      overwriteData,
+		wikiRepository: nil}
+
+	giteaDb, giteaDialect, err := giteaAccessor.openDatabase()
 	if err != nil {
-		err = errors.Wrapf(err, "opening sqlite database %s", giteaDbPath)
 		return nil, err
 	}
+	giteaAccessor.dialect = giteaDialect
 
 	// start transaction
-	log.Info("using Gitea database %s", giteaDbPath)
 	giteaAccessor.db, err = giteaDb.Begin()
 	if err != nil {
 		err = errors.Wrapf(err, "creating database transaction")
@@ -128,44 +114,12 @@ func CreateDefaultAccessor(
 	}
 	giteaAccessor.repoID = giteaRepoID
 
-	// find directory into which to clone wiki
-	wikiRepoName := giteaRepoName + ".wiki"
-	if giteaWikiRepoDir == "" {
-		cwd, err := os.Getwd()
-		if err != nil {
-			err = errors.Wrapf(err, "getting cwd")
-			return nil, err
-		}
-
-		giteaWikiRepoDir = filepath.Join(cwd, wikiRepoName)
-	}
-	_, err = os.Stat(giteaWikiRepoDir)
-	if !os.IsNotExist(err) {
-		return nil, fmt.Errorf("wiki repository directory %s already exists", giteaWikiRepoDir)
-	}
-	giteaAccessor.wikiRepoDir = giteaWikiRepoDir
-
-	// find URL from which clone wiki
-	if giteaWikiRepoURL == "" {
-		rootURL := giteaAccessor.GetStringConfig("server", "ROOT_URL")
-		if giteaWikiRepoToken != "" {
-			slashSlashPos := strings.Index(rootURL, "//")
-			if slashSlashPos == -1 {
-				return nil, fmt.Errorf("ROOT_URL %s malformed? expecting a '//'", rootURL)
-			}
-
-			// insert username and token into URL - 'http://example.com' should become 'http://<user>:<token>@example.com'
-			rootURL = rootURL[0:slashSlashPos+2] + giteaUserName + ":" + giteaWikiRepoToken + "@" + rootURL[slashSlashPos+2:]
-
-			giteaAccessor.wikiRepoToken = giteaWikiRepoToken
-		}
-		if rootURL[len(rootURL)-1:] != "/" {
-			rootURL = rootURL + "/"
-		}
-		giteaWikiRepoURL = fmt.Sprintf("%s%s/%s.git", rootURL, giteaUserName, wikiRepoName)
+	wiki, err := createWikiRepository(giteaAccessor.GetStringConfig("server", "ROOT_URL"),
+		giteaUserName, giteaRepoName, giteaWikiRepoURL, giteaWikiRepoToken, giteaWikiRepoDir, overwriteData, pushWiki)
+	if err != nil {
+		return nil, err
 	}
-	log.Info("using Wiki repo URL %s", giteaWikiRepoURL)
-	giteaAccessor.wikiRepoURL = giteaWikiRepoURL
+	giteaAccessor.wikiRepository = wiki
 
 	return &giteaAccessor, nil
 }
@@ -0,0 +1,163 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// dialect describes the differences between the SQL dialects of the database types supported by Gitea.
+// All SQL in this package is written using numbered ('$<n>') placeholders which the dialect converts into the form expected by the database driver.
+type dialect interface {
+	// bindParams converts an SQL query using numbered placeholders, and its parameters, into the form expected by the database driver.
+	bindParams(query string, args []interface{}) (string, []interface{})
+
+	// quoteIdentifier quotes an identifier which may clash with an SQL reserved word (e.g. 'index' or 'user').
+	quoteIdentifier(identifier string) string
+
+	// greatest returns an SQL expression for the greater of two expressions.
+	greatest(expr1 string, expr2 string) string
+
+	// insertReturnsID returns true if an INSERT statement must use a 'RETURNING id' clause to obtain the id of the inserted row,
+	// false if the id is available from the driver's result.
+	insertReturnsID() bool
+
+	// tableExistsQuery returns an SQL query counting the tables in the Gitea database with the name given by its ('$1') parameter.
+	tableExistsQuery() string
+}
+
+// sqliteDialect is the dialect for sqlite databases.
+type sqliteDialect struct{}
+
+func (sqliteDialect) bindParams(query string, args []interface{}) (string, []interface{}) {
+	return query, args
+}
+
+func (sqliteDialect) quoteIdentifier(identifier string) string {
+	return `"` + identifier + `"`
+}
+
+func (sqliteDialect) greatest(expr1 string, expr2 string) string {
+	return "MAX(" + expr1 + ", " + expr2 + ")"
+}
+
+func (sqliteDialect) insertReturnsID() bool {
+	return false
+}
+
+func (sqliteDialect) tableExistsQuery() string {
+	return `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = $1`
+}
+
+// postgresDialect is the dialect for postgres databases.
+type postgresDialect struct{}
+
+func (postgresDialect) bindParams(query string, args []interface{}) (string, []interface{}) {
+	return query, args
+}
+
+func (postgresDialect) quoteIdentifier(identifier string) string {
+	return `"` + identifier + `"`
+}
+
+func (postgresDialect) greatest(expr1 string, expr2 string) string {
+	return "GREATEST(" + expr1 + ", " + expr2 + ")"
+}
+
+func (postgresDialect) insertReturnsID() bool {
+	return true
+}
+
+func (postgresDialect) tableExistsQuery() string {
+	return `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1`
+}
+
+// mysqlDialect is the dialect for mysql/mariadb databases.
+type mysqlDialect struct{}
+
+var numberedPlaceholderRegexp = regexp.MustCompile(`\$([[:digit:]]+)`)
+
+// bindParams converts numbered placeholders into positional '?' placeholders.
+// Because a numbered placeholder can be used more than once in a query, the parameters are re-ordered (and duplicated) to match.
+func (mysqlDialect) bindParams(query string, args []interface{}) (string, []interface{}) {
+	var boundArgs []interface{}
+	boundQuery := numberedPlaceholderRegexp.ReplaceAllStringFunc(query, func(placeholder string) string {
+		argNum, _ := strconv.Atoi(placeholder[1:])
+		if argNum >= 1 && argNum <= len(args) {
+			boundArgs = append(boundArgs, args[argNum-1])
+		}
+		return "?"
+	})
+
+	return boundQuery, boundArgs
+}
+
+func (mysqlDialect) quoteIdentifier(identifier string) string {
+	return "`" + identifier + "`"
+}
+
+func (mysqlDialect) greatest(expr1 string, expr2 string) string {
+	return "GREATEST(" + expr1 + ", " + expr2 + ")"
+}
+
+func (mysqlDialect) insertReturnsID() bool {
+	return false
+}
+
+func (mysqlDialect) tableExistsQuery() string {
+	return `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = $1`
+}
+
+// query performs an SQL query against the Gitea database, converting it into the dialect of that database.
+func (accessor *DefaultAccessor) query(query string, args ...interface{}) (*sql.Rows, error) {
+	dialectQuery, dialectArgs := accessor.dialect.bindParams(query, args)
+	return accessor.db.Query(dialectQuery, dialectArgs...)
+}
+
+// queryRow performs an SQL query expected to return at most one row against the Gitea database, converting it into the dialect of that database.
+func (accessor *DefaultAccessor) queryRow(query string, args ...interface{}) *sql.Row {
+	dialectQuery, dialectArgs := accessor.dialect.bindParams(query, args)
+	return accessor.db.QueryRow(dialectQuery, dialectArgs...)
+}
+
+// hasTable determines whether the Gitea database contains a table of the given name.
+func (accessor *DefaultAccessor) hasTable(tableName string) (bool, error) {
+	var count int64
+	err := accessor.queryRow(accessor.dialect.tableExistsQuery(), tableName).Scan(&count)
+	if err != nil {
+		err = errors.Wrapf(err, "looking up Gitea database table %s", tableName)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// exec executes an SQL statement against the Gitea database, converting it into the dialect of that database.
+func (accessor *DefaultAccessor) exec(query string, args ...interface{}) (sql.Result, error) {
+	dialectQuery, dialectArgs := accessor.dialect.bindParams(query, args)
+	return accessor.db.Exec(dialectQuery, dialectArgs...)
+}
+
+// insert executes an SQL INSERT statement against the Gitea database, returning the id of the inserted row.
+func (accessor *DefaultAccessor) insert(query string, args ...interface{}) (int64, error) {
+	if accessor.dialect.insertReturnsID() {
+		var id int64
+		err := accessor.queryRow(query+" RETURNING id", args...).Scan(&id)
+		if err != nil {
+			return NullID, err
+		}
+		return id, nil
+	}
+
+	result, err := accessor.exec(query, args...)
+	if err != nil {
+		return NullID, err
+	}
+	return result.LastInsertId()
+}
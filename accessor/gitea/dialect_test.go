@@ -0,0 +1,216 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestBindParams(t *testing.T) {
+	tests := []struct {
+		name          string
+		dialect       dialect
+		query         string
+		args          []interface{}
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			name:          "sqlite numbered placeholders unchanged",
+			dialect:       sqliteDialect{},
+			query:         "INSERT INTO t(a, b, c) VALUES ($1, $2, $2)",
+			args:          []interface{}{1, "x"},
+			expectedQuery: "INSERT INTO t(a, b, c) VALUES ($1, $2, $2)",
+			expectedArgs:  []interface{}{1, "x"},
+		},
+		{
+			name:          "postgres numbered placeholders unchanged",
+			dialect:       postgresDialect{},
+			query:         "INSERT INTO t(a, b, c) VALUES ($1, $2, $2)",
+			args:          []interface{}{1, "x"},
+			expectedQuery: "INSERT INTO t(a, b, c) VALUES ($1, $2, $2)",
+			expectedArgs:  []interface{}{1, "x"},
+		},
+		{
+			name:          "mysql positional placeholders",
+			dialect:       mysqlDialect{},
+			query:         "UPDATE t SET a=$1, b=$2 WHERE id=$3",
+			args:          []interface{}{1, "x", int64(7)},
+			expectedQuery: "UPDATE t SET a=?, b=? WHERE id=?",
+			expectedArgs:  []interface{}{1, "x", int64(7)},
+		},
+		{
+			name:          "mysql placeholder used twice",
+			dialect:       mysqlDialect{},
+			query:         "INSERT INTO issue_watch(user_id, issue_id, is_watching, created_unix, updated_unix) VALUES ($1, $2, $3, $4, $4)",
+			args:          []interface{}{int64(1), int64(2), true, int64(1000)},
+			expectedQuery: "INSERT INTO issue_watch(user_id, issue_id, is_watching, created_unix, updated_unix) VALUES (?, ?, ?, ?, ?)",
+			expectedArgs:  []interface{}{int64(1), int64(2), true, int64(1000), int64(1000)},
+		},
+		{
+			name:          "mysql multi-digit placeholders",
+			dialect:       mysqlDialect{},
+			query:         "SELECT $10, $1",
+			args:          []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+			expectedQuery: "SELECT ?, ?",
+			expectedArgs:  []interface{}{10, 1},
+		},
+	}
+
+	for _, test := range tests {
+		query, args := test.dialect.bindParams(test.query, test.args)
+		if query != test.expectedQuery {
+			t.Errorf("%s: expecting query \"%s\", got \"%s\"", test.name, test.expectedQuery, query)
+		}
+		if !reflect.DeepEqual(args, test.expectedArgs) {
+			t.Errorf("%s: expecting args %v, got %v", test.name, test.expectedArgs, args)
+		}
+	}
+}
+
+func TestInsert(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err = tx.Exec(`CREATE TABLE item(id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, created_unix INTEGER, updated_unix INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+
+	accessor := DefaultAccessor{db: tx, dialect: sqliteDialect{}}
+	for expectedID := int64(1); expectedID <= 2; expectedID++ {
+		id, err := accessor.insert(`INSERT INTO item(name, created_unix, updated_unix) VALUES ($1, $2, $2)`, "item", int64(1000))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != expectedID {
+			t.Errorf("expecting inserted id %d, got %d", expectedID, id)
+		}
+	}
+
+	var updatedTime int64
+	if err = accessor.queryRow(`SELECT updated_unix FROM item WHERE id = $1`, int64(1)).Scan(&updatedTime); err != nil {
+		t.Fatal(err)
+	}
+	if updatedTime != 1000 {
+		t.Errorf("expecting repeated placeholder to bind same value, got %d", updatedTime)
+	}
+}
+
+func TestAddPlaceholderUser(t *testing.T) {
+	for _, hasEMailAddressTable := range []bool{true, false} {
+		db, err := sql.Open("sqlite3", ":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = tx.Exec(`CREATE TABLE "user"(id INTEGER PRIMARY KEY AUTOINCREMENT,
+			lower_name TEXT, name TEXT, full_name TEXT, email TEXT, keep_email_private BOOL, passwd TEXT, login_type INTEGER, login_source INTEGER, login_name TEXT,
+			type INTEGER, is_active BOOL, is_admin BOOL, is_restricted BOOL, prohibit_login BOOL, avatar_email TEXT, created_unix INTEGER, updated_unix INTEGER)`); err != nil {
+			t.Fatal(err)
+		}
+		if hasEMailAddressTable {
+			if _, err = tx.Exec(`CREATE TABLE email_address(id INTEGER PRIMARY KEY AUTOINCREMENT,
+				uid INTEGER, email TEXT, lower_email TEXT, is_activated BOOL, is_primary BOOL)`); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		accessor := DefaultAccessor{db: tx, dialect: sqliteDialect{}}
+		userID, err := accessor.AddPlaceholderUser(&User{Name: "trac-user", FullName: "Trac User", Email: "User@example.com"})
+		if err != nil {
+			t.Fatalf("email_address table %t: %v", hasEMailAddressTable, err)
+		}
+
+		// placeholder users are local users, so that their login can later be enabled
+		var loginType int64
+		if err = accessor.queryRow(`SELECT login_type FROM "user" WHERE id = $1`, userID).Scan(&loginType); err != nil {
+			t.Fatal(err)
+		}
+		if loginType != 1 {
+			t.Errorf("email_address table %t: expecting local login type 1, got %d", hasEMailAddressTable, loginType)
+		}
+
+		if hasEMailAddressTable {
+			var lowerEMail string
+			if err = accessor.queryRow(`SELECT lower_email FROM email_address WHERE uid = $1 AND is_primary`, userID).Scan(&lowerEMail); err != nil {
+				t.Fatal(err)
+			}
+			if lowerEMail != "user@example.com" {
+				t.Errorf("expecting primary email address user@example.com, got %s", lowerEMail)
+			}
+		}
+
+		tx.Rollback()
+		db.Close()
+	}
+}
+
+func TestLoginSourceServiceType(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	for _, statement := range []string{
+		`CREATE TABLE login_source(id INTEGER PRIMARY KEY AUTOINCREMENT, type INTEGER, name TEXT)`,
+		`INSERT INTO login_source(id, type, name) VALUES (1, 6, 'GitHub'), (2, 2, 'ldap')`,
+		`CREATE TABLE repository(id INTEGER PRIMARY KEY AUTOINCREMENT, original_service_type INTEGER)`,
+		`INSERT INTO repository(id, original_service_type) VALUES (1, 0)`,
+	} {
+		if _, err = tx.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	accessor := DefaultAccessor{db: tx, dialect: sqliteDialect{}, repoID: 1}
+
+	// Gitea matches login source names against its git service names regardless of case
+	serviceType, err := accessor.GetLoginSourceServiceType(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serviceType != 2 {
+		t.Errorf("expecting GitHub service type 2 for login source named GitHub, got %d", serviceType)
+	}
+	if err = accessor.SetRepoOriginalServiceType(serviceType); err != nil {
+		t.Fatal(err)
+	}
+	var originalServiceType int64
+	if err = accessor.queryRow(`SELECT original_service_type FROM repository WHERE id = 1`).Scan(&originalServiceType); err != nil {
+		t.Fatal(err)
+	}
+	if originalServiceType != 2 {
+		t.Errorf("expecting original service type 2, got %d", originalServiceType)
+	}
+
+	serviceType, err = accessor.GetLoginSourceServiceType(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if serviceType != 0 {
+		t.Errorf("expecting no service type for login source not named after a git service, got %d", serviceType)
+	}
+
+	if _, err = accessor.GetLoginSourceServiceType(3); err == nil {
+		t.Errorf("expecting error for unknown login source")
+	}
+}
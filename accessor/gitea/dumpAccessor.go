@@ -0,0 +1,282 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+	"gopkg.in/yaml.v2"
+)
+
+// DumpAccessor is an implementation of the gitea Accessor interface which writes a Gitea repository dump:
+// the directory of YAML files read by the Gitea 'restore-repo' command.
+// The data is accumulated in memory and only written out when the "transaction" is committed.
+//
+// Gitea itself is not accessed (other than its wiki repository) so no Gitea users are known:
+// users are identified by name only and Gitea will record them as the original authors of issues and comments on restoring the dump.
+type DumpAccessor struct {
+	dumpDir               string
+	serverURL             string
+	userName              string
+	repoName              string
+	overwrite             bool
+	userNamesByID         map[int64]string
+	userIDsByName         map[string]int64
+	labels                []*dumpLabel
+	milestones            []*dumpMilestone
+	releases              []*dumpRelease
+	issues                []*dumpIssue
+	issuesByIndex         map[int64]*dumpIssue
+	commentsByIssue       map[int64][]*dumpComment
+	attachmentsByIssue    map[int64][]*dumpAttachment
+	lastIssueCommentID    int64
+	lastIssueAttachmentID int64
+	*wikiRepository
+}
+
+// dumpRepository describes our repository in the Gitea dump (repo.yml).
+type dumpRepository struct {
+	Name        string `yaml:"name"`
+	Owner       string `yaml:"owner"`
+	IsPrivate   bool   `yaml:"is_private"`
+	IsMirror    bool   `yaml:"is_mirror"`
+	Description string `yaml:"description"`
+	CloneURL    string `yaml:"clone_url"`
+	OriginalURL string `yaml:"original_url"`
+}
+
+// dumpTime converts a unix time into a time for the Gitea dump.
+func dumpTime(unixTime int64) time.Time {
+	return time.Unix(unixTime, 0).UTC()
+}
+
+// dumpOptionalTime converts a unix time into an optional time for the Gitea dump - a zero time is omitted.
+func dumpOptionalTime(unixTime int64) *time.Time {
+	if unixTime == 0 {
+		return nil
+	}
+
+	optionalTime := dumpTime(unixTime)
+	return &optionalTime
+}
+
+// CreateDumpAccessor returns a new Gitea dump accessor writing into the given dump directory.
+// The Gitea server URL is used only for creating links and for accessing the Gitea wiki repository.
+func CreateDumpAccessor(
+	giteaDumpDir string,
+	giteaServerURL string,
+	giteaUserName string,
+	giteaRepoName string,
+	giteaWikiRepoURL string,
+	giteaWikiRepoToken string,
+	giteaWikiRepoDir string,
+	overwriteData bool,
+	pushWiki bool) (*DumpAccessor, error) {
+	dumpFiles, err := ioutil.ReadDir(giteaDumpDir)
+	if err != nil && !os.IsNotExist(err) {
+		err = errors.Wrapf(err, "reading Gitea dump directory %s", giteaDumpDir)
+		return nil, err
+	}
+	if len(dumpFiles) > 0 && !overwriteData {
+		return nil, fmt.Errorf("gitea dump directory %s is not empty", giteaDumpDir)
+	}
+
+	giteaAccessor := DumpAccessor{
+		dumpDir:               giteaDumpDir,
+		serverURL:             strings.TrimSuffix(giteaServerURL, "/"),
+		userName:              giteaUserName,
+		repoName:              giteaRepoName,
+		overwrite:             overwriteData,
+		userNamesByID:         make(map[int64]string),
+		userIDsByName:         make(map[string]int64),
+		labels:                []*dumpLabel{},
+		milestones:            []*dumpMilestone{},
+		releases:              []*dumpRelease{},
+		issues:                []*dumpIssue{},
+		issuesByIndex:         make(map[int64]*dumpIssue),
+		commentsByIssue:       make(map[int64][]*dumpComment),
+		attachmentsByIssue:    make(map[int64][]*dumpAttachment),
+		lastIssueCommentID:    NullID,
+		lastIssueAttachmentID: NullID,
+		wikiRepository:        nil}
+	log.Info("writing Gitea dump into %s", giteaDumpDir)
+	log.Warn("Gitea users are not accessible when writing a repository dump - " +
+		"issues, comments and releases will be attributed to Trac users by name only and will not be linked to Gitea accounts")
+	log.Warn("repository collaborators and teams cannot be recorded in a repository dump - Trac permissions will not be imported")
+
+	wiki, err := createWikiRepository(giteaAccessor.serverURL,
+		giteaUserName, giteaRepoName, giteaWikiRepoURL, giteaWikiRepoToken, giteaWikiRepoDir, overwriteData, pushWiki)
+	if err != nil {
+		return nil, err
+	}
+	giteaAccessor.wikiRepository = wiki
+
+	return &giteaAccessor, nil
+}
+
+// writeDumpFile writes data as YAML into a file in the Gitea dump.
+func (accessor *DumpAccessor) writeDumpFile(relPath string, data interface{}) error {
+	dumpPath := filepath.Join(accessor.dumpDir, relPath)
+	err := os.MkdirAll(filepath.Dir(dumpPath), 0775)
+	if err != nil {
+		err = errors.Wrapf(err, "creating directory for Gitea dump file %s", dumpPath)
+		return err
+	}
+
+	yamlBytes, err := yaml.Marshal(data)
+	if err != nil {
+		err = errors.Wrapf(err, "encoding Gitea dump file %s", dumpPath)
+		return err
+	}
+
+	err = ioutil.WriteFile(dumpPath, yamlBytes, 0664)
+	if err != nil {
+		err = errors.Wrapf(err, "writing Gitea dump file %s", dumpPath)
+		return err
+	}
+
+	log.Debug("wrote Gitea dump file %s", dumpPath)
+	return nil
+}
+
+// writeDump writes all of our accumulated data into the Gitea dump directory.
+func (accessor *DumpAccessor) writeDump() error {
+	repository := dumpRepository{Name: accessor.repoName, Owner: accessor.userName}
+	if err := accessor.writeDumpFile("repo.yml", &repository); err != nil {
+		return err
+	}
+	if err := accessor.writeDumpFile("label.yml", accessor.labels); err != nil {
+		return err
+	}
+	if err := accessor.writeDumpFile("milestone.yml", accessor.milestones); err != nil {
+		return err
+	}
+	if err := accessor.writeDumpFile("issue.yml", accessor.issues); err != nil {
+		return err
+	}
+	if len(accessor.releases) > 0 {
+		if err := accessor.writeDumpFile("release.yml", accessor.releases); err != nil {
+			return err
+		}
+	}
+
+	for _, issue := range accessor.issues {
+		comments := accessor.commentsByIssue[issue.Index]
+		if len(comments) > 0 {
+			if err := accessor.writeDumpFile(filepath.Join("comments", fmt.Sprintf("%d.yml", issue.Index)), comments); err != nil {
+				return err
+			}
+		}
+
+		for _, attachment := range accessor.attachmentsByIssue[issue.Index] {
+			attachmentPath := filepath.Join(accessor.dumpDir, attachment.relPath())
+			if err := os.MkdirAll(filepath.Dir(attachmentPath), 0775); err != nil {
+				err = errors.Wrapf(err, "creating directory for Gitea dump attachment %s", attachmentPath)
+				return err
+			}
+			if err := copyFile(attachment.filePath, attachmentPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Info("wrote Gitea dump into %s", accessor.dumpDir)
+	return nil
+}
+
+// GetStringConfig retrieves a value from the Gitea config as a string.
+// The Gitea config is not accessible when writing a dump: the only value we can provide is the server root URL.
+func (accessor *DumpAccessor) GetStringConfig(sectionName string, configName string) string {
+	if sectionName == "server" && configName == "ROOT_URL" {
+		return accessor.serverURL
+	}
+
+	return ""
+}
+
+// getUserRepoURL retrieves the URL of the current repository for the current user
+func (accessor *DumpAccessor) getUserRepoURL() string {
+	return fmt.Sprintf("%s/%s/%s", accessor.serverURL, accessor.userName, accessor.repoName)
+}
+
+// UpdateRepoIssueCounts updates issue counts for our chosen Gitea repository.
+// Gitea calculates these counts itself on restoring the dump.
+func (accessor *DumpAccessor) UpdateRepoIssueCounts() error {
+	return nil
+}
+
+// UpdateRepoMilestoneCounts updates milestone counts for our chosen Gitea repository.
+// Gitea calculates these counts itself on restoring the dump.
+func (accessor *DumpAccessor) UpdateRepoMilestoneCounts() error {
+	return nil
+}
+
+// SetRepoOriginalServiceType records the type of the git service from which our chosen Gitea repository was migrated.
+// The Gitea dump format provides no means of recording this so it is not recorded.
+func (accessor *DumpAccessor) SetRepoOriginalServiceType(serviceType int64) error {
+	log.Warn("cannot record the original service type of a repository in a Gitea dump - ignored")
+	return nil
+}
+
+// GetCommitURL retrieves the URL for viewing a given commit in the current repository
+func (accessor *DumpAccessor) GetCommitURL(commitID string) string {
+	repoURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/commit/%s", repoURL, commitID)
+}
+
+// GetSourceURL retrieves the URL for viewing the latest version of a source file on a given branch of the current repository
+func (accessor *DumpAccessor) GetSourceURL(branchPath string, filePath string) string {
+	repoURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/src/branch/%s/%s", repoURL, branchPath, filePath)
+}
+
+// IsRepoOwnedByOrganization returns true if our chosen Gitea repository is owned by an organization rather than a user.
+// The repository dump format has no representation of repository access so the owner is always treated as a user.
+func (accessor *DumpAccessor) IsRepoOwnedByOrganization() (bool, error) {
+	return false, nil
+}
+
+// AddRepoCollaborator adds a user as a collaborator on our chosen Gitea repository with the given access.
+// The repository dump format has no representation of collaborators so this is a no-op.
+func (accessor *DumpAccessor) AddRepoCollaborator(userID int64, access AccessMode) error {
+	log.Warn("collaborators cannot be recorded in a repository dump - collaborator %d ignored", userID)
+	return nil
+}
+
+// AddTeam adds a team to the organization owning our repository.
+// The repository dump format has no representation of teams so this is a no-op.
+func (accessor *DumpAccessor) AddTeam(team *Team) (int64, error) {
+	log.Warn("teams cannot be recorded in a repository dump - team %s ignored", team.Name)
+	return NullID, nil
+}
+
+// AddTeamMember adds a user to a team of the organization owning our repository.
+// The repository dump format has no representation of teams so this is a no-op.
+func (accessor *DumpAccessor) AddTeamMember(teamID int64, userID int64) error {
+	log.Warn("teams cannot be recorded in a repository dump - team member %d ignored", userID)
+	return nil
+}
+
+// CommitTransaction commits a Gitea transaction - this is when the dump is written.
+func (accessor *DumpAccessor) CommitTransaction() error {
+	err := accessor.writeDump()
+	if err != nil {
+		return err
+	}
+
+	return accessor.commitWikiRepo()
+}
+
+// RollbackTransaction rolls back a Gitea transaction - nothing has been written to the dump at this point.
+func (accessor *DumpAccessor) RollbackTransaction() error {
+	return accessor.rollbackWikiRepo()
+}
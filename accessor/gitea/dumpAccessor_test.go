@@ -0,0 +1,199 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+)
+
+func createDumpAccessor(t *testing.T) (*gitea.DumpAccessor, string, func()) {
+	parentDir, err := ioutil.TempDir("", "trac2gitea-dump-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup := func() {
+		os.RemoveAll(parentDir)
+	}
+
+	dumpDir := filepath.Join(parentDir, "dump")
+	accessor, err := gitea.CreateDumpAccessor(dumpDir, "http://localhost:3000/", apiOwner, apiRepo, "", "", filepath.Join(parentDir, "wiki"), false, false)
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	return accessor, dumpDir, cleanup
+}
+
+func readDumpFile(t *testing.T, dumpDir string, relPath string) string {
+	dumpBytes, err := ioutil.ReadFile(filepath.Join(dumpDir, relPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(dumpBytes)
+}
+
+func expectDumpContains(t *testing.T, dumpFileName string, dumpText string, expectedText string) {
+	if !strings.Contains(dumpText, expectedText) {
+		t.Errorf("expecting %s to contain \"%s\", got:\n%s", dumpFileName, expectedText, dumpText)
+	}
+}
+
+func TestDumpAccessorIsGiteaAccessor(t *testing.T) {
+	var _ gitea.Accessor = &gitea.DumpAccessor{}
+}
+
+func TestCreateDumpAccessorForNonEmptyDirectory(t *testing.T) {
+	dumpDir, err := ioutil.TempDir("", "trac2gitea-dump-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dumpDir)
+	ioutil.WriteFile(filepath.Join(dumpDir, "issue.yml"), []byte("[]"), 0664)
+
+	_, err = gitea.CreateDumpAccessor(dumpDir, "http://localhost:3000", apiOwner, apiRepo, "", "", filepath.Join(dumpDir, "wiki"), false, false)
+	if err == nil {
+		t.Errorf("expecting error for non-empty dump directory")
+	}
+}
+
+func TestDumpIssuesAndComments(t *testing.T) {
+	accessor, dumpDir, cleanup := createDumpAccessor(t)
+	defer cleanup()
+
+	userID, err := accessor.GetUserID("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	labelID, err := accessor.AddLabel(&gitea.Label{Name: "bug", Color: "#ff0000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = accessor.AddLabel(&gitea.Label{Name: "priority/high", Color: "#00ff00", Exclusive: true}); err != nil {
+		t.Fatal(err)
+	}
+	milestoneID, err := accessor.AddMilestone(&gitea.Milestone{Name: "v1.0", Description: "first release", DueTime: 2000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = accessor.AddRelease(&gitea.Release{TagName: "v1.0", Title: "Version 1.0", Description: "first release", PublisherID: userID, Created: 3000}); err != nil {
+		t.Fatal(err)
+	}
+
+	issueID, err := accessor.AddIssue(&gitea.Issue{Index: 7, Summary: "a summary", ReporterID: userID, Milestone: "v1.0", Description: "a description", Created: 1000, Updated: 1500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issueID != 7 {
+		t.Errorf("expecting issue id 7, got %d", issueID)
+	}
+	if _, err = accessor.AddIssueLabel(issueID, labelID); err != nil {
+		t.Fatal(err)
+	}
+	if err = accessor.AddIssueAssignee(issueID, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	comments := []gitea.IssueComment{
+		{CommentType: gitea.CommentIssueCommentType, AuthorID: userID, Text: "a comment", Time: 1100},
+		{CommentType: gitea.MilestoneIssueCommentType, AuthorID: userID, MilestoneID: milestoneID, Time: 1200},
+		{CommentType: gitea.CloseIssueCommentType, OriginalAuthorName: "bob", Time: 1300}}
+	for _, comment := range comments {
+		if _, err = accessor.AddIssueComment(issueID, &comment); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commentIDs, err := accessor.GetIssueCommentIDsByTime(issueID, 1200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commentIDs) != 1 {
+		t.Errorf("expecting one comment at time 1200, got %d", len(commentIDs))
+	}
+
+	if err = accessor.CommitTransaction(); err != nil {
+		t.Fatal(err)
+	}
+
+	repoText := readDumpFile(t, dumpDir, "repo.yml")
+	expectDumpContains(t, "repo.yml", repoText, "name: "+apiRepo)
+	expectDumpContains(t, "repo.yml", repoText, "owner: "+apiOwner)
+
+	labelText := readDumpFile(t, dumpDir, "label.yml")
+	expectDumpContains(t, "label.yml", labelText, "name: bug")
+	expectDumpContains(t, "label.yml", labelText, "exclusive: true")
+
+	milestoneText := readDumpFile(t, dumpDir, "milestone.yml")
+	expectDumpContains(t, "milestone.yml", milestoneText, "title: v1.0")
+	expectDumpContains(t, "milestone.yml", milestoneText, "description: first release")
+
+	releaseText := readDumpFile(t, dumpDir, "release.yml")
+	expectDumpContains(t, "release.yml", releaseText, "tag_name: v1.0")
+	expectDumpContains(t, "release.yml", releaseText, "name: Version 1.0")
+	expectDumpContains(t, "release.yml", releaseText, "publisher_name: alice")
+	expectDumpContains(t, "release.yml", releaseText, "created: 1970-01-01T00:50:00Z")
+
+	issueText := readDumpFile(t, dumpDir, "issue.yml")
+	expectDumpContains(t, "issue.yml", issueText, "number: 7")
+	expectDumpContains(t, "issue.yml", issueText, "poster_name: alice")
+	expectDumpContains(t, "issue.yml", issueText, "milestone: v1.0")
+	expectDumpContains(t, "issue.yml", issueText, "- alice")
+	expectDumpContains(t, "issue.yml", issueText, "created: 1970-01-01T00:16:40Z")
+
+	commentText := readDumpFile(t, dumpDir, "comments/7.yml")
+	expectDumpContains(t, "comments/7.yml", commentText, "content: a comment")
+	expectDumpContains(t, "comments/7.yml", commentText, "Added to milestone **v1.0**")
+	expectDumpContains(t, "comments/7.yml", commentText, "comment_type: close")
+	expectDumpContains(t, "comments/7.yml", commentText, "poster_name: bob")
+}
+
+func TestDumpIssueAttachment(t *testing.T) {
+	accessor, dumpDir, cleanup := createDumpAccessor(t)
+	defer cleanup()
+
+	issueID, err := accessor.AddIssue(&gitea.Issue{Index: 1, Summary: "a summary"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attachmentFile, err := ioutil.TempFile("", "trac2gitea-attachment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(attachmentFile.Name())
+	attachmentFile.WriteString("attachment content")
+	attachmentFile.Close()
+
+	attachment := gitea.IssueAttachment{UUID: "78ac0001", FileName: "file.txt", Time: 1000}
+	if _, err = accessor.AddIssueAttachment(issueID, &attachment, attachmentFile.Name()); err != nil {
+		t.Fatal(err)
+	}
+
+	uuid, err := accessor.GetIssueAttachmentUUID(issueID, "file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uuid != "78ac0001" {
+		t.Errorf("expecting attachment uuid \"78ac0001\", got \"%s\"", uuid)
+	}
+
+	if err = accessor.CommitTransaction(); err != nil {
+		t.Fatal(err)
+	}
+
+	attachmentText := readDumpFile(t, dumpDir, filepath.Join("attachments", "1", "78ac0001", "file.txt"))
+	if attachmentText != "attachment content" {
+		t.Errorf("expecting dumped attachment content \"attachment content\", got \"%s\"", attachmentText)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// dumpIssue describes an issue in the Gitea dump (issue.yml).
+// Issue ids are not recorded in the dump so the issue index is used as the id of the issue.
+type dumpIssue struct {
+	Index       int64        `yaml:"number"`
+	PosterID    int64        `yaml:"poster_id"`
+	PosterName  string       `yaml:"poster_name"`
+	PosterEmail string       `yaml:"poster_email"`
+	Title       string       `yaml:"title"`
+	Content     string       `yaml:"content"`
+	Ref         string       `yaml:"ref"`
+	Milestone   string       `yaml:"milestone"`
+	State       string       `yaml:"state"`
+	IsLocked    bool         `yaml:"is_locked"`
+	Created     time.Time    `yaml:"created"`
+	Updated     time.Time    `yaml:"updated"`
+	Closed      *time.Time   `yaml:"closed"`
+	Labels      []*dumpLabel `yaml:"labels"`
+	Assignees   []string     `yaml:"assignees"`
+}
+
+// posterName returns the name to record as the poster of an issue or comment.
+func (accessor *DumpAccessor) posterName(authorID int64, originalAuthorName string) string {
+	if originalAuthorName != "" {
+		return originalAuthorName
+	}
+
+	return accessor.userNamesByID[authorID]
+}
+
+// GetIssueID retrieves the id of the Gitea issue corresponding to a given issue index - returns NullID if no such issue.
+func (accessor *DumpAccessor) GetIssueID(issueIndex int64) (int64, error) {
+	if _, haveIssue := accessor.issuesByIndex[issueIndex]; !haveIssue {
+		return NullID, nil
+	}
+
+	return issueIndex, nil
+}
+
+// AddIssue adds a new issue to Gitea.
+// The Gitea dump format has no issue priority so this is not imported.
+func (accessor *DumpAccessor) AddIssue(issue *Issue) (int64, error) {
+	// note: no Gitea user ids are known so none are dumped - 'gitea restore-repo' only links posters to Gitea users by id
+	// (via the external login of the user) so the poster name is recorded as the original author of the issue
+	dumpedIssue := dumpIssue{
+		Index:       issue.Index,
+		PosterID:    NullID,
+		PosterName:  accessor.posterName(issue.ReporterID, issue.OriginalAuthorName),
+		PosterEmail: "",
+		Title:       issue.Summary,
+		Content:     issue.Description,
+		Ref:         "",
+		Milestone:   issue.Milestone,
+		State:       "open",
+		IsLocked:    false,
+		Created:     dumpTime(issue.Created),
+		Updated:     dumpTime(issue.Updated),
+		Closed:      nil,
+		Labels:      []*dumpLabel{},
+		Assignees:   []string{}}
+	if issue.Closed {
+		dumpedIssue.State = "closed"
+		dumpedIssue.Closed = dumpOptionalTime(issue.Updated)
+	}
+
+	existingIssue, haveIssue := accessor.issuesByIndex[issue.Index]
+	if !haveIssue {
+		accessor.issues = append(accessor.issues, &dumpedIssue)
+		accessor.issuesByIndex[issue.Index] = &dumpedIssue
+		log.Info("created issue %d: %s", issue.Index, issue.Summary)
+	} else if accessor.overwrite {
+		dumpedIssue.Labels = existingIssue.Labels
+		dumpedIssue.Assignees = existingIssue.Assignees
+		*existingIssue = dumpedIssue
+		log.Info("updated issue %d: %s", issue.Index, issue.Summary)
+	} else {
+		log.Info("issue %d already exists - ignored", issue.Index)
+	}
+
+	return issue.Index, nil
+}
+
+// getIssue retrieves the issue with a given id.
+func (accessor *DumpAccessor) getIssue(issueID int64) (*dumpIssue, error) {
+	issue, haveIssue := accessor.issuesByIndex[issueID]
+	if !haveIssue {
+		return nil, fmt.Errorf("unknown issue id %d", issueID)
+	}
+
+	return issue, nil
+}
+
+// SetIssueUpdateTime sets the update time on a given Gitea issue.
+func (accessor *DumpAccessor) SetIssueUpdateTime(issueID int64, updateTime int64) error {
+	issue, err := accessor.getIssue(issueID)
+	if err != nil {
+		return err
+	}
+
+	if updated := dumpTime(updateTime); updated.After(issue.Updated) {
+		issue.Updated = updated
+	}
+	return nil
+}
+
+// GetIssueURL retrieves a URL for viewing a given issue
+func (accessor *DumpAccessor) GetIssueURL(issueID int64) string {
+	repoURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/issues/%d", repoURL, issueID)
+}
+
+// UpdateIssueCommentCount updates the count of comments a given issue
+// Gitea calculates this count itself on restoring the dump.
+func (accessor *DumpAccessor) UpdateIssueCommentCount(issueID int64) error {
+	return nil
+}
+
+// AddIssueAssignee adds an assignee to a Gitea issue
+func (accessor *DumpAccessor) AddIssueAssignee(issueID int64, assigneeID int64) error {
+	issue, err := accessor.getIssue(issueID)
+	if err != nil {
+		return err
+	}
+
+	assigneeName := accessor.userNamesByID[assigneeID]
+	for _, existingAssigneeName := range issue.Assignees {
+		if existingAssigneeName == assigneeName {
+			log.Debug("issue %d already has assignee %d - ignored", issueID, assigneeID)
+			return nil
+		}
+	}
+
+	issue.Assignees = append(issue.Assignees, assigneeName)
+	log.Debug("added assignee %d for issue %d", assigneeID, issueID)
+	return nil
+}
+
+// AddIssueLabel adds an issue label to Gitea, returns issue label ID
+// Issue labels have no ids in the dump so the label id is returned instead.
+func (accessor *DumpAccessor) AddIssueLabel(issueID int64, labelID int64) (int64, error) {
+	issue, err := accessor.getIssue(issueID)
+	if err != nil {
+		return NullID, err
+	}
+
+	label := accessor.getLabel(labelID)
+	if label == nil {
+		return NullID, fmt.Errorf("unknown label id %d for issue %d", labelID, issueID)
+	}
+
+	for _, issueLabel := range issue.Labels {
+		if issueLabel.Name == label.Name {
+			return labelID, nil
+		}
+	}
+
+	issue.Labels = append(issue.Labels, label)
+	log.Debug("added label %d for issue %d", labelID, issueID)
+	return labelID, nil
+}
+
+// AddIssueParticipant adds a participant to a Gitea issue.
+// Gitea determines participants itself on restoring the dump so this is a no-op.
+func (accessor *DumpAccessor) AddIssueParticipant(issueID int64, userID int64) error {
+	return nil
+}
+
+// AddIssueDependency adds a dependency of one Gitea issue on another, created by the given user at the given time.
+// The repository dump format has no representation of issue dependencies so this is a no-op.
+func (accessor *DumpAccessor) AddIssueDependency(issueID int64, dependencyID int64, userID int64, time int64) error {
+	log.Warn("issue dependencies cannot be recorded in a repository dump - dependency of issue %d on issue %d ignored", issueID, dependencyID)
+	return nil
+}
+
+// AddIssueTrackedTime records time tracked against a Gitea issue.
+// The repository dump format has no representation of tracked time so this is a no-op.
+func (accessor *DumpAccessor) AddIssueTrackedTime(issueID int64, trackedTime *TrackedTime) error {
+	log.Warn("tracked time cannot be recorded in a repository dump - time tracked on issue %d ignored", issueID)
+	return nil
+}
+
+// SetIssueTimeEstimate sets the estimated time (in seconds) for a Gitea issue.
+// The repository dump format has no representation of time estimates so this is a no-op.
+func (accessor *DumpAccessor) SetIssueTimeEstimate(issueID int64, seconds int64) error {
+	log.Warn("time estimates cannot be recorded in a repository dump - estimate for issue %d ignored", issueID)
+	return nil
+}
+
+// SetIssueWatch sets whether or not a user is watching a Gitea issue.
+// The repository dump format has no representation of issue watches so this is a no-op.
+func (accessor *DumpAccessor) SetIssueWatch(issueID int64, userID int64, isWatching bool, time int64) error {
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// dumpAttachment describes an issue attachment to be copied into the Gitea dump.
+type dumpAttachment struct {
+	id         int64
+	issueIndex int64
+	uuid       string
+	fileName   string
+	filePath   string
+}
+
+// relPath returns the path of an attachment relative to the root of the Gitea dump.
+// Gitea does not restore issue attachments from a dump so these are stored in their own directory for manual upload.
+func (attachment *dumpAttachment) relPath() string {
+	return filepath.Join("attachments", fmt.Sprintf("%d", attachment.issueIndex), attachment.uuid, attachment.fileName)
+}
+
+// getIssueAttachment retrieves the named attachment of a given issue - returns nil if no such attachment.
+func (accessor *DumpAccessor) getIssueAttachment(issueID int64, fileName string) *dumpAttachment {
+	for _, attachment := range accessor.attachmentsByIssue[issueID] {
+		if attachment.fileName == fileName {
+			return attachment
+		}
+	}
+
+	return nil
+}
+
+// GetIssueAttachmentUUID returns the UUID for a named attachment of a given issue - returns empty string if cannot find issue/attachment.
+func (accessor *DumpAccessor) GetIssueAttachmentUUID(issueID int64, fileName string) (string, error) {
+	attachment := accessor.getIssueAttachment(issueID, fileName)
+	if attachment == nil {
+		return "", nil
+	}
+
+	return attachment.uuid, nil
+}
+
+// AddIssueAttachment adds a new attachment to an issue using the provided file - returns id of created attachment
+func (accessor *DumpAccessor) AddIssueAttachment(issueID int64, attachment *IssueAttachment, filePath string) (int64, error) {
+	if _, err := accessor.getIssue(issueID); err != nil {
+		return NullID, err
+	}
+
+	existingAttachment := accessor.getIssueAttachment(issueID, attachment.FileName)
+	if existingAttachment != nil {
+		if accessor.overwrite {
+			existingAttachment.uuid = attachment.UUID
+			existingAttachment.filePath = filePath
+			log.Debug("updated attachment %s for issue %d (id %d)", attachment.FileName, issueID, existingAttachment.id)
+		} else {
+			log.Debug("issue %d already has attachment %s - ignored", issueID, attachment.FileName)
+		}
+		return existingAttachment.id, nil
+	}
+
+	accessor.lastIssueAttachmentID++
+	dumpedAttachment := dumpAttachment{
+		id:         accessor.lastIssueAttachmentID,
+		issueIndex: issueID,
+		uuid:       attachment.UUID,
+		fileName:   attachment.FileName,
+		filePath:   filePath}
+	accessor.attachmentsByIssue[issueID] = append(accessor.attachmentsByIssue[issueID], &dumpedAttachment)
+
+	log.Debug("added attachment %s for issue %d", attachment.FileName, issueID)
+
+	return dumpedAttachment.id, nil
+}
+
+// GetIssueAttachmentURL retrieves the URL for viewing a Gitea attachment
+func (accessor *DumpAccessor) GetIssueAttachmentURL(issueID int64, uuid string) string {
+	baseURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/attachments/%s", baseURL, uuid)
+}
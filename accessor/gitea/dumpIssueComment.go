@@ -0,0 +1,125 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// dumpComment describes an issue comment in the Gitea dump (comments/<issue-index>.yml).
+type dumpComment struct {
+	IssueIndex  int64                  `yaml:"issue_index"`
+	Index       int64                  `yaml:"index"`
+	CommentType string                 `yaml:"comment_type,omitempty"`
+	PosterID    int64                  `yaml:"poster_id"`
+	PosterName  string                 `yaml:"poster_name"`
+	PosterEmail string                 `yaml:"poster_email"`
+	Created     time.Time              `yaml:"created"`
+	Updated     time.Time              `yaml:"updated"`
+	Content     string                 `yaml:"content"`
+	Meta        map[string]interface{} `yaml:"meta,omitempty"`
+}
+
+// GetIssueCommentIDsByTime retrieves the IDs of all comments created at a given time for a given issue
+func (accessor *DumpAccessor) GetIssueCommentIDsByTime(issueID int64, createdTime int64) ([]int64, error) {
+	var issueCommentIDs = []int64{}
+	for _, comment := range accessor.commentsByIssue[issueID] {
+		if comment.Created.Unix() == createdTime {
+			issueCommentIDs = append(issueCommentIDs, comment.Index)
+		}
+	}
+
+	return issueCommentIDs, nil
+}
+
+// describeIssueComment returns the comment type, metadata and text to record in the dump for a Gitea issue comment.
+// Gitea can only restore comments describing some types of change - other changes are recorded as a textual description of the change.
+func (accessor *DumpAccessor) describeIssueComment(comment *IssueComment) (string, map[string]interface{}, string) {
+	switch comment.CommentType {
+	case CommentIssueCommentType:
+		return "comment", nil, comment.Text
+	case CloseIssueCommentType:
+		return "close", nil, ""
+	case ReopenIssueCommentType:
+		return "reopen", nil, ""
+	case TitleIssueCommentType:
+		return "change_title", map[string]interface{}{"OldTitle": comment.OldTitle, "NewTitle": comment.Title}, ""
+	case LabelIssueCommentType:
+		labelName := ""
+		if label := accessor.getLabel(comment.LabelID); label != nil {
+			labelName = label.Name
+		}
+		if comment.Text == "1" {
+			return "comment", nil, fmt.Sprintf("Added label **%s**", labelName)
+		}
+		return "comment", nil, fmt.Sprintf("Removed label **%s**", labelName)
+	case MilestoneIssueCommentType:
+		oldMilestoneName := accessor.getMilestoneName(comment.OldMilestoneID)
+		milestoneName := accessor.getMilestoneName(comment.MilestoneID)
+		switch {
+		case oldMilestoneName == "":
+			return "comment", nil, fmt.Sprintf("Added to milestone **%s**", milestoneName)
+		case milestoneName == "":
+			return "comment", nil, fmt.Sprintf("Removed from milestone **%s**", oldMilestoneName)
+		default:
+			return "comment", nil, fmt.Sprintf("Changed milestone from **%s** to **%s**", oldMilestoneName, milestoneName)
+		}
+	case AssigneeIssueCommentType:
+		assigneeName := accessor.userNamesByID[comment.AssigneeID]
+		removedAssigneeName := accessor.userNamesByID[comment.RemovedAssigneeID]
+		switch {
+		case removedAssigneeName == "":
+			return "comment", nil, fmt.Sprintf("Assigned to **%s**", assigneeName)
+		case assigneeName == "":
+			return "comment", nil, fmt.Sprintf("Unassigned **%s**", removedAssigneeName)
+		default:
+			return "comment", nil, fmt.Sprintf("Reassigned from **%s** to **%s**", removedAssigneeName, assigneeName)
+		}
+	}
+
+	return "comment", nil, comment.Text
+}
+
+// AddIssueComment adds a comment on a Gitea issue, returns id of created comment
+func (accessor *DumpAccessor) AddIssueComment(issueID int64, comment *IssueComment) (int64, error) {
+	if _, err := accessor.getIssue(issueID); err != nil {
+		return NullID, err
+	}
+
+	commentType, commentMeta, commentText := accessor.describeIssueComment(comment)
+	accessor.lastIssueCommentID++
+	// note: as for issues, no Gitea user id is dumped so the poster name is recorded as the original author of the comment
+	dumpedComment := dumpComment{
+		IssueIndex:  issueID,
+		Index:       accessor.lastIssueCommentID,
+		CommentType: commentType,
+		PosterID:    NullID,
+		PosterName:  accessor.posterName(comment.AuthorID, comment.OriginalAuthorName),
+		PosterEmail: "",
+		Created:     dumpTime(comment.Time),
+		Updated:     dumpTime(comment.Time),
+		Content:     commentText,
+		Meta:        commentMeta}
+	accessor.commentsByIssue[issueID] = append(accessor.commentsByIssue[issueID], &dumpedComment)
+
+	log.Debug("added issue comment at %s for issue %d (id %d)", time.Unix(comment.Time, 0), issueID, dumpedComment.Index)
+
+	return dumpedComment.Index, nil
+}
+
+// GetIssueCommentURL retrieves the URL for viewing a Gitea comment for a given issue.
+// Gitea allocates comment ids on restoring the dump so this URL only identifies the issue.
+func (accessor *DumpAccessor) GetIssueCommentURL(issueID int64, commentID int64) string {
+	return accessor.GetIssueURL(issueID)
+}
+
+// AddIssueContentHistory adds an entry to the edit history of the content of a Gitea issue or issue comment.
+// The repository dump format has no representation of content history so this is a no-op.
+func (accessor *DumpAccessor) AddIssueContentHistory(issueID int64, history *IssueContentHistory) (int64, error) {
+	return NullID, nil
+}
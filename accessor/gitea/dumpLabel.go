@@ -0,0 +1,65 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// dumpLabel describes a label in the Gitea dump (label.yml and issue.yml).
+type dumpLabel struct {
+	Name        string `yaml:"name"`
+	Color       string `yaml:"color"`
+	Description string `yaml:"description"`
+	Exclusive   bool   `yaml:"exclusive"`
+}
+
+// GetLabelID retrieves the id of the given label, returns NullID if no such label
+func (accessor *DumpAccessor) GetLabelID(labelName string) (int64, error) {
+	for labelIndex, label := range accessor.labels {
+		if label.Name == labelName {
+			return int64(labelIndex + 1), nil
+		}
+	}
+
+	return NullID, nil
+}
+
+// AddLabel adds a label to Gitea, returns label id.
+func (accessor *DumpAccessor) AddLabel(label *Label) (int64, error) {
+	labelID, err := accessor.GetLabelID(label.Name)
+	if err != nil {
+		return NullID, err
+	}
+
+	dumpedLabel := dumpLabel{Name: label.Name, Color: label.Color, Description: label.Description, Exclusive: label.Exclusive}
+	if labelID == NullID {
+		accessor.labels = append(accessor.labels, &dumpedLabel)
+		labelID = int64(len(accessor.labels))
+		log.Debug("added label %s, color %s (id %d)", label.Name, label.Color, labelID)
+	} else if accessor.overwrite {
+		*accessor.labels[labelID-1] = dumpedLabel
+		log.Debug("updated label %s, color %s (id %d)", label.Name, label.Color, labelID)
+	} else {
+		log.Debug("label %s already exists - ignored", label.Name)
+	}
+
+	return labelID, nil
+}
+
+// getLabel retrieves the label with the given id - returns nil if no such label.
+func (accessor *DumpAccessor) getLabel(labelID int64) *dumpLabel {
+	if labelID < 1 || labelID > int64(len(accessor.labels)) {
+		return nil
+	}
+
+	return accessor.labels[labelID-1]
+}
+
+// UpdateLabelIssueCounts updates issue counts for all labels.
+// Gitea calculates these counts itself on restoring the dump.
+func (accessor *DumpAccessor) UpdateLabelIssueCounts() error {
+	return nil
+}
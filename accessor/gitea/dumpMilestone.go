@@ -0,0 +1,99 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// dumpMilestone describes a milestone in the Gitea dump (milestone.yml).
+type dumpMilestone struct {
+	Title       string     `yaml:"title"`
+	Description string     `yaml:"description"`
+	Deadline    *time.Time `yaml:"deadline"`
+	Created     time.Time  `yaml:"created"`
+	Updated     *time.Time `yaml:"updated"`
+	Closed      *time.Time `yaml:"closed"`
+	State       string     `yaml:"state"`
+}
+
+// GetMilestoneID gets the ID of a named milestone - returns NullID if no such milestone
+func (accessor *DumpAccessor) GetMilestoneID(milestoneName string) (int64, error) {
+	for milestoneIndex, milestone := range accessor.milestones {
+		if milestone.Title == milestoneName {
+			return int64(milestoneIndex + 1), nil
+		}
+	}
+
+	return NullID, nil
+}
+
+// AddMilestone adds a milestone to Gitea, returns id of created milestone
+func (accessor *DumpAccessor) AddMilestone(milestone *Milestone) (int64, error) {
+	milestoneID, err := accessor.GetMilestoneID(milestone.Name)
+	if err != nil {
+		return NullID, err
+	}
+
+	// Trac does not record milestone creation times so use the earliest time we have
+	createdTime := time.Now().UTC()
+	if dueTime := dumpOptionalTime(milestone.DueTime); dueTime != nil && dueTime.Before(createdTime) {
+		createdTime = *dueTime
+	}
+	if closedTime := dumpOptionalTime(milestone.ClosedTime); closedTime != nil && closedTime.Before(createdTime) {
+		createdTime = *closedTime
+	}
+
+	dumpedMilestone := dumpMilestone{
+		Title:       milestone.Name,
+		Description: milestone.Description,
+		Deadline:    dumpOptionalTime(milestone.DueTime),
+		Created:     createdTime,
+		Updated:     nil,
+		Closed:      nil,
+		State:       "open"}
+	if milestone.Closed {
+		dumpedMilestone.State = "closed"
+		dumpedMilestone.Closed = dumpOptionalTime(milestone.ClosedTime)
+	}
+
+	if milestoneID == NullID {
+		accessor.milestones = append(accessor.milestones, &dumpedMilestone)
+		milestoneID = int64(len(accessor.milestones))
+		log.Debug("added milestone %s (id %d)", milestone.Name, milestoneID)
+	} else if accessor.overwrite {
+		*accessor.milestones[milestoneID-1] = dumpedMilestone
+		log.Debug("updated milestone %s (id %d)", milestone.Name, milestoneID)
+	} else {
+		log.Debug("milestone %s already exists - ignored", milestone.Name)
+	}
+
+	return milestoneID, nil
+}
+
+// getMilestoneName retrieves the name of the milestone with the given id - returns an empty string if no such milestone.
+func (accessor *DumpAccessor) getMilestoneName(milestoneID int64) string {
+	if milestoneID < 1 || milestoneID > int64(len(accessor.milestones)) {
+		return ""
+	}
+
+	return accessor.milestones[milestoneID-1].Title
+}
+
+// GetMilestoneURL gets the URL for accessing a given milestone
+// Gitea allocates milestone ids on restoring the dump so this assumes the milestones of the repository are all restored from the dump.
+func (accessor *DumpAccessor) GetMilestoneURL(milestoneID int64) string {
+	repoURL := accessor.getUserRepoURL()
+	return fmt.Sprintf("%s/milestone/%d", repoURL, milestoneID)
+}
+
+// UpdateMilestoneIssueCounts updates issue counts for all milestones.
+// Gitea calculates these counts itself on restoring the dump.
+func (accessor *DumpAccessor) UpdateMilestoneIssueCounts() error {
+	return nil
+}
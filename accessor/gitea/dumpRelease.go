@@ -0,0 +1,76 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"time"
+
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// dumpRelease describes a release in the Gitea dump (release.yml).
+type dumpRelease struct {
+	TagName         string    `yaml:"tag_name"`
+	TargetCommitish string    `yaml:"target_commitish"`
+	Name            string    `yaml:"name"`
+	Body            string    `yaml:"body"`
+	Draft           bool      `yaml:"draft"`
+	Prerelease      bool      `yaml:"prerelease"`
+	PublisherID     int64     `yaml:"publisher_id"`
+	PublisherName   string    `yaml:"publisher_name"`
+	PublisherEmail  string    `yaml:"publisher_email"`
+	Created         time.Time `yaml:"created"`
+	Published       time.Time `yaml:"published"`
+}
+
+// HasTag returns true if our chosen Gitea repository has a git tag of the given name.
+// The dump contains no git repository so no tags are ever found.
+func (accessor *DumpAccessor) HasTag(tagName string) (bool, error) {
+	return false, nil
+}
+
+// getReleaseID retrieves the id of the release with the given tag name - returns NullID if no such release.
+func (accessor *DumpAccessor) getReleaseID(tagName string) int64 {
+	for releaseIndex, release := range accessor.releases {
+		if release.TagName == tagName {
+			return int64(releaseIndex + 1)
+		}
+	}
+
+	return NullID
+}
+
+// AddRelease adds a release to Gitea, returns id of created release
+func (accessor *DumpAccessor) AddRelease(release *Release) (int64, error) {
+	releaseID := accessor.getReleaseID(release.TagName)
+
+	// note: no Gitea user ids are known so none are dumped - 'gitea restore-repo' only links publishers to Gitea users by id
+	// (via the external login of the user) so the publisher name is recorded as the original author of the release
+	dumpedRelease := dumpRelease{
+		TagName:         release.TagName,
+		TargetCommitish: "",
+		Name:            release.Title,
+		Body:            release.Description,
+		Draft:           release.IsDraft,
+		Prerelease:      false,
+		PublisherID:     NullID,
+		PublisherName:   accessor.userNamesByID[release.PublisherID],
+		PublisherEmail:  "",
+		Created:         dumpTime(release.Created),
+		Published:       dumpTime(release.Created)}
+
+	if releaseID == NullID {
+		accessor.releases = append(accessor.releases, &dumpedRelease)
+		releaseID = int64(len(accessor.releases))
+		log.Debug("added release %s (id %d)", release.TagName, releaseID)
+	} else if accessor.overwrite {
+		*accessor.releases[releaseID-1] = dumpedRelease
+		log.Debug("updated release %s (id %d)", release.TagName, releaseID)
+	} else {
+		log.Debug("release %s already exists - ignored", release.TagName)
+	}
+
+	return releaseID, nil
+}
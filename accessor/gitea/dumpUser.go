@@ -0,0 +1,65 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"strings"
+
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// GetUserID retrieves the id of a named Gitea user - returns NullID if no such user.
+// Gitea users are not accessible when writing a dump so we allocate ids for user names as we encounter them.
+func (accessor *DumpAccessor) GetUserID(userName string) (int64, error) {
+	if strings.Trim(userName, " ") == "" {
+		return NullID, nil
+	}
+
+	userID, haveUserID := accessor.userIDsByName[userName]
+	if !haveUserID {
+		userID = int64(len(accessor.userIDsByName) + 1)
+		accessor.userIDsByName[userName] = userID
+		accessor.userNamesByID[userID] = userName
+	}
+
+	return userID, nil
+}
+
+// GetUserEMailAddress retrieves the email address of a given user
+// Gitea users are not accessible when writing a dump so no email addresses are known.
+func (accessor *DumpAccessor) GetUserEMailAddress(userName string) (string, error) {
+	return "", nil
+}
+
+// MatchUser retrieves the name of the user best matching a user name or email address
+// Gitea users are not accessible when writing a dump so we assume that Gitea user names match Trac ones.
+func (accessor *DumpAccessor) MatchUser(userName string, userEmail string) (string, error) {
+	return strings.ToLower(userName), nil
+}
+
+// GetUsers retrieves all Gitea users (excluding organizations), passing each one to the provided "handler" function.
+// Gitea users are not accessible when writing a dump so no users are retrieved.
+func (accessor *DumpAccessor) GetUsers(handlerFn func(user *User) error) error {
+	return nil
+}
+
+// AddPlaceholderUser creates a restricted Gitea user who cannot log in, to stand in for a Trac user with no Gitea equivalent.
+// Gitea users cannot be created when writing a dump so this is a no-op.
+func (accessor *DumpAccessor) AddPlaceholderUser(user *User) (int64, error) {
+	log.Warn("users cannot be created when writing a repository dump - placeholder user %s ignored", user.Name)
+	return NullID, nil
+}
+
+// GetExternalUserID retrieves the id of the Gitea user who has linked the account with a given external id from a given login source
+// Gitea users are not accessible when writing a dump so no such user is ever found.
+func (accessor *DumpAccessor) GetExternalUserID(loginSourceID int64, externalID string) (int64, error) {
+	return NullID, nil
+}
+
+// GetLoginSourceServiceType retrieves the type of the git service after which a given login source is named
+// Gitea login sources are not accessible when writing a dump so no service type is ever found.
+func (accessor *DumpAccessor) GetLoginSourceServiceType(loginSourceID int64) (int64, error) {
+	return 0, nil
+}
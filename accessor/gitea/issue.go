@@ -15,8 +15,8 @@ import (
 // GetIssueID retrieves the id of the Gitea issue corresponding to a given issue index - returns NullID if no such issue.
 func (accessor *DefaultAccessor) GetIssueID(issueIndex int64) (int64, error) {
 	var issueID int64 = NullID
-	err := accessor.db.QueryRow(`
-		SELECT id FROM issue WHERE repo_id = $1 AND "index" = $2
+	err := accessor.queryRow(`
+		SELECT id FROM issue WHERE repo_id = $1 AND `+accessor.dialect.quoteIdentifier("index")+` = $2
 		`, accessor.repoID, issueIndex).Scan(&issueID)
 	if err != nil && err != sql.ErrNoRows {
 		err = errors.Wrapf(err, "retrieving issue with index %d", issueIndex)
@@ -41,14 +41,14 @@ func (accessor *DefaultAccessor) updateIssue(issueID int64, issue *Issue) error
 		return err
 	}
 
-	_, err = accessor.db.Exec(`
-		UPDATE issue SET "index"=?, repo_id=?, name=?, poster_id=?,
-			milestone_id=?, original_author_id=?, original_author=?, 
-			is_pull=0, is_closed=?, content=?, created_unix=?, updated_unix=?
-			WHERE id=?`,
+	_, err = accessor.exec(`
+		UPDATE issue SET `+accessor.dialect.quoteIdentifier("index")+`=$1, repo_id=$2, name=$3, poster_id=$4,
+			milestone_id=$5, original_author_id=$6, original_author=$7, 
+			is_pull=FALSE, is_closed=$8, content=$9, created_unix=$10, updated_unix=$11, priority=$12
+			WHERE id=$13`,
 		issue.Index, accessor.repoID, issue.Summary, issue.ReporterID,
 		milestoneID, nullOwnerID, issue.OriginalAuthorName,
-		issue.Closed, issue.Description, issue.Created, issue.Updated,
+		issue.Closed, issue.Description, issue.Created, issue.Updated, issue.Priority,
 		issueID)
 	if err != nil {
 		err = errors.Wrapf(err, "updating issue with index %d", issue.Index)
@@ -68,22 +68,15 @@ func (accessor *DefaultAccessor) insertIssue(issue *Issue) (int64, error) {
 		return NullID, err
 	}
 
-	_, err = accessor.db.Exec(`
-		INSERT INTO issue("index", repo_id, name, poster_id, milestone_id, original_author_id, original_author, is_pull, is_closed, content, created_unix)
-			SELECT $1, $2, $3, $4, $5, $6, $7, 0, $8, $9, $10`,
-		issue.Index, accessor.repoID, issue.Summary, issue.ReporterID, milestoneID, nullOwnerID, issue.OriginalAuthorName, issue.Closed, issue.Description, issue.Created)
+	issueID, err := accessor.insert(`
+		INSERT INTO issue(`+accessor.dialect.quoteIdentifier("index")+`, repo_id, name, poster_id, milestone_id, original_author_id, original_author, is_pull, is_closed, content, created_unix, priority)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, FALSE, $8, $9, $10, $11)`,
+		issue.Index, accessor.repoID, issue.Summary, issue.ReporterID, milestoneID, nullOwnerID, issue.OriginalAuthorName, issue.Closed, issue.Description, issue.Created, issue.Priority)
 	if err != nil {
 		err = errors.Wrapf(err, "adding issue with index %d", issue.Index)
 		return NullID, err
 	}
 
-	var issueID int64
-	err = accessor.db.QueryRow(`SELECT last_insert_rowid()`).Scan(&issueID)
-	if err != nil {
-		err = errors.Wrapf(err, "retrieving id of new issue with index %d", issue.Index)
-		return NullID, err
-	}
-
 	log.Info("created issue %d: %s", issue.Index, issue.Summary)
 
 	return issueID, nil
@@ -114,7 +107,7 @@ func (accessor *DefaultAccessor) AddIssue(issue *Issue) (int64, error) {
 
 // SetIssueUpdateTime sets the update time on a given Gitea issue.
 func (accessor *DefaultAccessor) SetIssueUpdateTime(issueID int64, updateTime int64) error {
-	_, err := accessor.db.Exec(`UPDATE issue SET updated_unix = MAX(updated_unix,$1) WHERE id = $2`, updateTime, issueID)
+	_, err := accessor.exec(`UPDATE issue SET updated_unix = `+accessor.dialect.greatest("COALESCE(updated_unix, 0)", "$1")+` WHERE id = $2`, updateTime, issueID)
 	if err != nil {
 		err = errors.Wrapf(err, "setting updated time for issue %d", issueID)
 		return err
@@ -131,7 +124,7 @@ func (accessor *DefaultAccessor) GetIssueURL(issueID int64) string {
 
 // UpdateIssueCommentCount updates the count of comments a given issue
 func (accessor *DefaultAccessor) UpdateIssueCommentCount(issueID int64) error {
-	_, err := accessor.db.Exec(`
+	_, err := accessor.exec(`
 	UPDATE issue SET 
 		num_comments = (SELECT COUNT(id) FROM comment)
 		WHERE id = $1`, issueID)
@@ -14,7 +14,7 @@ import (
 // getIssueAssigneeID retrieves the id of the given issue/assignee association, returns gitea.NullID if no such association
 func (accessor *DefaultAccessor) getIssueAssigneeID(issueID int64, assigneeID int64) (int64, error) {
 	var issueAssigneeID int64 = NullID
-	err := accessor.db.QueryRow(`
+	err := accessor.queryRow(`
 		SELECT id FROM issue_assignees WHERE issue_id = $1 AND assignee_id = $2
 		`, issueID, assigneeID).Scan(&issueAssigneeID)
 	if err != nil && err != sql.ErrNoRows {
@@ -27,7 +27,7 @@ func (accessor *DefaultAccessor) getIssueAssigneeID(issueID int64, assigneeID in
 
 // updateIssueAssignee updates an existing issue assignee
 func (accessor *DefaultAccessor) updateIssueAssignee(issueAssigneeID int64, issueID int64, assigneeID int64) error {
-	_, err := accessor.db.Exec(`UPDATE issue_assignees SET issue_id=?, assignee_id=? WHERE id= ?`, issueID, assigneeID, issueAssigneeID)
+	_, err := accessor.exec(`UPDATE issue_assignees SET issue_id=$1, assignee_id=$2 WHERE id= $3`, issueID, assigneeID, issueAssigneeID)
 	if err != nil {
 		err = errors.Wrapf(err, "updating issue %d/assignee %d", issueID, assigneeID)
 		return err
@@ -40,7 +40,7 @@ func (accessor *DefaultAccessor) updateIssueAssignee(issueAssigneeID int64, issu
 
 // insertIssueAssignee adds a new assignee to a Gitea issue
 func (accessor *DefaultAccessor) insertIssueAssignee(issueID int64, assigneeID int64) error {
-	_, err := accessor.db.Exec(`
+	_, err := accessor.exec(`
 		INSERT INTO issue_assignees(issue_id, assignee_id) VALUES ($1, $2)`,
 		issueID, assigneeID)
 	if err != nil {
@@ -17,7 +17,7 @@ import (
 func (accessor *DefaultAccessor) getIssueAttachmentIDandUUID(issueID int64, fileName string) (int64, string, error) {
 	var issueAttachmentID = NullID
 	var issueAttachmentUUID string
-	err := accessor.db.QueryRow(`
+	err := accessor.queryRow(`
 		SELECT id, uuid FROM attachment WHERE issue_id = $1 AND name = $2
 		`, issueID, fileName).Scan(&issueAttachmentID, &issueAttachmentUUID)
 	if err != nil && err != sql.ErrNoRows {
@@ -64,8 +64,8 @@ func (accessor *DefaultAccessor) deleteAttachment(UUID string) error {
 
 // updateIssueAttachment updates an existing issue attachment
 func (accessor *DefaultAccessor) updateIssueAttachment(issueAttachmentID int64, issueID int64, attachment *IssueAttachment, filePath string) error {
-	_, err := accessor.db.Exec(`
-		UPDATE attachment SET uuid=?, issue_id=?, comment_id=?, name=?, created_unix=? WHERE id=?`,
+	_, err := accessor.exec(`
+		UPDATE attachment SET uuid=$1, issue_id=$2, comment_id=$3, name=$4, created_unix=$5 WHERE id=$6`,
 		attachment.UUID, issueID, attachment.CommentID, attachment.FileName, attachment.Time, issueAttachmentID)
 
 	if err != nil {
@@ -80,7 +80,7 @@ func (accessor *DefaultAccessor) updateIssueAttachment(issueAttachmentID int64,
 
 // insertIssueAttachment creates a new attachment to a Gitea issue, returns id of created attachment
 func (accessor *DefaultAccessor) insertIssueAttachment(issueID int64, attachment *IssueAttachment, filePath string) (int64, error) {
-	_, err := accessor.db.Exec(`
+	issueAttachmentID, err := accessor.insert(`
 		INSERT INTO attachment(
 			uuid, issue_id, comment_id, name, created_unix)
 			VALUES ($1, $2, $3, $4, $5)`, attachment.UUID, issueID, attachment.CommentID, attachment.FileName, attachment.Time)
@@ -89,13 +89,6 @@ func (accessor *DefaultAccessor) insertIssueAttachment(issueID int64, attachment
 		return NullID, err
 	}
 
-	var issueAttachmentID int64
-	err = accessor.db.QueryRow(`SELECT last_insert_rowid()`).Scan(&issueAttachmentID)
-	if err != nil {
-		err = errors.Wrapf(err, "retrieving id of new attachment %s for issue %d", attachment.FileName, issueID)
-		return NullID, err
-	}
-
 	log.Debug("added attachment %s for issue %d", attachment.FileName, issueID)
 
 	return issueAttachmentID, nil
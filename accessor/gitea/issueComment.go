@@ -14,7 +14,7 @@ import (
 
 // GetIssueCommentIDsByTime retrieves the IDs of all comments created at a given time for a given issue
 func (accessor *DefaultAccessor) GetIssueCommentIDsByTime(issueID int64, createdTime int64) ([]int64, error) {
-	rows, err := accessor.db.Query(
+	rows, err := accessor.query(
 		`SELECT id FROM comment WHERE issue_id = $1 AND created_unix = $2`, issueID, createdTime)
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving ids of comments created at \"%s\" for issue %d", time.Unix(createdTime, 0), issueID)
@@ -37,17 +37,17 @@ func (accessor *DefaultAccessor) GetIssueCommentIDsByTime(issueID int64, created
 
 // updateIssueComment updates an existing issue comment
 func (accessor *DefaultAccessor) updateIssueComment(issueCommentID int64, issueID int64, comment *IssueComment) error {
-	_, err := accessor.db.Exec(`
+	_, err := accessor.exec(`
 		UPDATE comment SET
-			type=?, issue_id=?, poster_id=?,
-			original_author_id=?, original_author=?, 
-			label_id=?,
-			old_milestone_id=?, milestone_id=?,
-			assignee_id=?, removed_assignee=?,
-			old_title=?, new_title=?,
-			content=?,
-			created_unix=?, updated_unix=?
-			WHERE id=?`,
+			type=$1, issue_id=$2, poster_id=$3,
+			original_author_id=$4, original_author=$5, 
+			label_id=$6,
+			old_milestone_id=$7, milestone_id=$8,
+			assignee_id=$9, removed_assignee=$10,
+			old_title=$11, new_title=$12,
+			content=$13,
+			created_unix=$14, updated_unix=$15
+			WHERE id=$16`,
 		comment.CommentType, issueID, comment.AuthorID,
 		comment.OriginalAuthorID, comment.OriginalAuthorName,
 		comment.LabelID,
@@ -69,7 +69,7 @@ func (accessor *DefaultAccessor) updateIssueComment(issueCommentID int64, issueI
 
 // insertIssueComment adds a new comment to a Gitea issue, returns id of created comment.
 func (accessor *DefaultAccessor) insertIssueComment(issueID int64, comment *IssueComment) (int64, error) {
-	_, err := accessor.db.Exec(`
+	issueCommentID, err := accessor.insert(`
 		INSERT INTO comment(
 			type, issue_id, poster_id, 
 			original_author_id, original_author, 
@@ -93,13 +93,6 @@ func (accessor *DefaultAccessor) insertIssueComment(issueID int64, comment *Issu
 		return NullID, err
 	}
 
-	var issueCommentID int64
-	err = accessor.db.QueryRow(`SELECT last_insert_rowid()`).Scan(&issueCommentID)
-	if err != nil {
-		err = errors.Wrapf(err, "retrieving id of new comment \"%s\" for issue %d", comment.Text, issueID)
-		return NullID, err
-	}
-
 	log.Debug("added issue comment at %s for issue %d (id %d)", time.Unix(comment.Time, 0), issueID, issueCommentID)
 
 	return issueCommentID, nil
@@ -0,0 +1,80 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// getIssueContentHistoryID retrieves the id of the content history entry for a given issue or issue comment at a given time, returns NullID if no such entry
+func (accessor *DefaultAccessor) getIssueContentHistoryID(issueID int64, history *IssueContentHistory) (int64, error) {
+	var issueContentHistoryID = NullID
+	err := accessor.queryRow(`
+		SELECT id FROM issue_content_history WHERE issue_id = $1 AND comment_id = $2 AND edited_unix = $3 AND is_first_created = $4
+		`, issueID, history.CommentID, history.Time, history.IsFirstCreated).Scan(&issueContentHistoryID)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving id for content history of issue %d, comment %d at %s", issueID, history.CommentID, time.Unix(history.Time, 0))
+		return NullID, err
+	}
+
+	return issueContentHistoryID, nil
+}
+
+// updateIssueContentHistory updates an existing issue content history entry
+func (accessor *DefaultAccessor) updateIssueContentHistory(issueContentHistoryID int64, issueID int64, history *IssueContentHistory) error {
+	_, err := accessor.exec(`UPDATE issue_content_history SET poster_id=$1, content_text=$2 WHERE id=$3`,
+		history.AuthorID, history.Text, issueContentHistoryID)
+	if err != nil {
+		err = errors.Wrapf(err, "updating content history of issue %d, comment %d at %s", issueID, history.CommentID, time.Unix(history.Time, 0))
+		return err
+	}
+
+	log.Debug("updated content history of issue %d, comment %d at %s (id %d)", issueID, history.CommentID, time.Unix(history.Time, 0), issueContentHistoryID)
+
+	return nil
+}
+
+// insertIssueContentHistory creates a new issue content history entry, returns id of created entry
+func (accessor *DefaultAccessor) insertIssueContentHistory(issueID int64, history *IssueContentHistory) (int64, error) {
+	issueContentHistoryID, err := accessor.insert(`
+		INSERT INTO issue_content_history(poster_id, issue_id, comment_id, edited_unix, content_text, is_first_created, is_deleted)
+			VALUES ($1, $2, $3, $4, $5, $6, FALSE)`,
+		history.AuthorID, issueID, history.CommentID, history.Time, history.Text, history.IsFirstCreated)
+	if err != nil {
+		err = errors.Wrapf(err, "adding content history of issue %d, comment %d at %s", issueID, history.CommentID, time.Unix(history.Time, 0))
+		return NullID, err
+	}
+
+	log.Debug("added content history of issue %d, comment %d at %s (id %d)", issueID, history.CommentID, time.Unix(history.Time, 0), issueContentHistoryID)
+
+	return issueContentHistoryID, nil
+}
+
+// AddIssueContentHistory adds an entry to the edit history of the content of a Gitea issue or issue comment, returns id of created entry
+func (accessor *DefaultAccessor) AddIssueContentHistory(issueID int64, history *IssueContentHistory) (int64, error) {
+	issueContentHistoryID, err := accessor.getIssueContentHistoryID(issueID, history)
+	if err != nil {
+		return NullID, err
+	}
+
+	if issueContentHistoryID == NullID {
+		return accessor.insertIssueContentHistory(issueID, history)
+	}
+
+	if accessor.overwrite {
+		err = accessor.updateIssueContentHistory(issueContentHistoryID, issueID, history)
+		if err != nil {
+			return NullID, err
+		}
+	} else {
+		log.Debug("issue %d, comment %d already has content history at %s - ignored", issueID, history.CommentID, time.Unix(history.Time, 0))
+	}
+
+	return issueContentHistoryID, nil
+}
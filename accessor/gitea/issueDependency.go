@@ -0,0 +1,78 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// getIssueDependencyID retrieves the id of the dependency of one issue on another, returns NullID if no such dependency
+func (accessor *DefaultAccessor) getIssueDependencyID(issueID int64, dependencyID int64) (int64, error) {
+	var issueDependencyID = NullID
+	err := accessor.queryRow(`
+		SELECT id FROM issue_dependency WHERE issue_id = $1 AND dependency_id = $2
+		`, issueID, dependencyID).Scan(&issueDependencyID)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving id for dependency of issue %d on issue %d", issueID, dependencyID)
+		return NullID, err
+	}
+
+	return issueDependencyID, nil
+}
+
+// updateIssueDependency updates an existing issue dependency
+func (accessor *DefaultAccessor) updateIssueDependency(issueDependencyID int64, issueID int64, dependencyID int64, userID int64, time int64) error {
+	_, err := accessor.exec(`UPDATE issue_dependency SET user_id=$1, created_unix=$2, updated_unix=$2 WHERE id=$3`,
+		userID, time, issueDependencyID)
+	if err != nil {
+		err = errors.Wrapf(err, "updating dependency of issue %d on issue %d", issueID, dependencyID)
+		return err
+	}
+
+	log.Debug("updated dependency of issue %d on issue %d (id %d)", issueID, dependencyID, issueDependencyID)
+
+	return nil
+}
+
+// insertIssueDependency creates a new issue dependency
+func (accessor *DefaultAccessor) insertIssueDependency(issueID int64, dependencyID int64, userID int64, time int64) error {
+	_, err := accessor.exec(`
+		INSERT INTO issue_dependency(user_id, issue_id, dependency_id, created_unix, updated_unix) VALUES ($1, $2, $3, $4, $4)`,
+		userID, issueID, dependencyID, time)
+	if err != nil {
+		err = errors.Wrapf(err, "adding dependency of issue %d on issue %d", issueID, dependencyID)
+		return err
+	}
+
+	log.Debug("added dependency of issue %d on issue %d", issueID, dependencyID)
+
+	return nil
+}
+
+// AddIssueDependency adds a dependency of one Gitea issue on another, created by the given user at the given time.
+func (accessor *DefaultAccessor) AddIssueDependency(issueID int64, dependencyID int64, userID int64, time int64) error {
+	issueDependencyID, err := accessor.getIssueDependencyID(issueID, dependencyID)
+	if err != nil {
+		return err
+	}
+
+	if issueDependencyID == NullID {
+		return accessor.insertIssueDependency(issueID, dependencyID, userID, time)
+	}
+
+	if accessor.overwrite {
+		err = accessor.updateIssueDependency(issueDependencyID, issueID, dependencyID, userID, time)
+		if err != nil {
+			return err
+		}
+	} else {
+		log.Debug("issue %d already depends on issue %d - ignored", issueID, dependencyID)
+	}
+
+	return nil
+}
@@ -14,7 +14,7 @@ import (
 // getIssueLabelID retrieves the id of the given Gitea issue label - returns NullID if no such issue label.
 func (accessor *DefaultAccessor) getIssueLabelID(issueID int64, labelID int64) (int64, error) {
 	var issueLabelID int64 = NullID
-	err := accessor.db.QueryRow(`
+	err := accessor.queryRow(`
 		SELECT id FROM issue_label WHERE issue_id=$1 AND label_id=$2
 		`, issueID, labelID).Scan(&issueLabelID)
 	if err != nil && err != sql.ErrNoRows {
@@ -27,7 +27,7 @@ func (accessor *DefaultAccessor) getIssueLabelID(issueID int64, labelID int64) (
 
 // insertIssueLabel adds a new label to a Gitea issue, returns id of created issue label.
 func (accessor *DefaultAccessor) insertIssueLabel(issueID int64, labelID int64) (int64, error) {
-	_, err := accessor.db.Exec(`
+	issueLabelID, err := accessor.insert(`
 		INSERT INTO issue_label(issue_id, label_id) VALUES ( $1, $2 )`,
 		issueID, labelID)
 	if err != nil {
@@ -35,13 +35,6 @@ func (accessor *DefaultAccessor) insertIssueLabel(issueID int64, labelID int64)
 		return NullID, err
 	}
 
-	var issueLabelID int64
-	err = accessor.db.QueryRow(`SELECT last_insert_rowid()`).Scan(&issueLabelID)
-	if err != nil {
-		err = errors.Wrapf(err, "retrieving id of new issue label for issue %d, label %d", issueID, labelID)
-		return NullID, err
-	}
-
 	log.Debug("added label %d for issue %d (id %d)", labelID, issueID, issueLabelID)
 
 	return issueLabelID, nil
@@ -64,7 +57,7 @@ func (accessor *DefaultAccessor) AddIssueLabel(issueID int64, labelID int64) (in
 
 // UpdateLabelIssueCounts updates issue counts for all labels.
 func (accessor *DefaultAccessor) UpdateLabelIssueCounts() error {
-	_, err := accessor.db.Exec(`
+	_, err := accessor.exec(`
 		UPDATE label AS l SET 
 			num_issues = (
 				SELECT COUNT(il1.issue_id)
@@ -76,7 +69,7 @@ func (accessor *DefaultAccessor) UpdateLabelIssueCounts() error {
 				FROM issue_label il2, issue i
 				WHERE l.id = il2.label_id
 				AND il2.issue_id = i.id
-				AND i.is_closed = 1
+				AND i.is_closed = TRUE
 				GROUP BY il2.label_id)`)
 	if err != nil {
 		err = errors.Wrapf(err, "updating number of issues for labels")
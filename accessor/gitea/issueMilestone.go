@@ -10,7 +10,7 @@ import (
 
 // UpdateMilestoneIssueCounts updates issue counts for all milestones.
 func (accessor *DefaultAccessor) UpdateMilestoneIssueCounts() error {
-	_, err := accessor.db.Exec(`
+	_, err := accessor.exec(`
 		UPDATE milestone AS m SET 
 			num_issues = (
 				SELECT COUNT(i1.id)
@@ -21,7 +21,7 @@ func (accessor *DefaultAccessor) UpdateMilestoneIssueCounts() error {
 				SELECT COUNT(i2.id)
 				FROM issue i2
 				WHERE m.id = i2.milestone_id
-				AND i2.is_closed = 1
+				AND i2.is_closed = TRUE
 				GROUP BY i2.milestone_id)`)
 	if err != nil {
 		err = errors.Wrapf(err, "updating number of issues for milestones")
@@ -14,7 +14,7 @@ import (
 // getIssueParticipantID retrieves the id of the given issue participant, returns NullID if no such participant
 func (accessor *DefaultAccessor) getIssueParticipantID(issueID int64, userID int64) (int64, error) {
 	var issueParticipantID = NullID
-	err := accessor.db.QueryRow(`
+	err := accessor.queryRow(`
 		SELECT id FROM issue_user WHERE issue_id = $1 AND uid = $2
 		`, issueID, userID).Scan(&issueParticipantID)
 	if err != nil && err != sql.ErrNoRows {
@@ -27,7 +27,7 @@ func (accessor *DefaultAccessor) getIssueParticipantID(issueID int64, userID int
 
 // updateIssueParticipant updates an existing issue participant
 func (accessor *DefaultAccessor) updateIssueParticipant(issueParticipantID int64, issueID int64, userID int64) error {
-	_, err := accessor.db.Exec(`UPDATE issue_user SET issue_id=?, uid=? WHERE id=?`,
+	_, err := accessor.exec(`UPDATE issue_user SET issue_id=$1, uid=$2 WHERE id=$3`,
 		issueID, userID, issueParticipantID)
 	if err != nil {
 		err = errors.Wrapf(err, "updating participant %d in issue %d", userID, issueID)
@@ -41,8 +41,8 @@ func (accessor *DefaultAccessor) updateIssueParticipant(issueParticipantID int64
 
 // insertIssueParticipant creates a new issue participant
 func (accessor *DefaultAccessor) insertIssueParticipant(issueID int64, userID int64) error {
-	_, err := accessor.db.Exec(`
-		INSERT INTO issue_user(issue_id, uid, is_read, is_mentioned) VALUES ($1, $2, 1, 0)`,
+	_, err := accessor.exec(`
+		INSERT INTO issue_user(issue_id, uid, is_read, is_mentioned) VALUES ($1, $2, TRUE, FALSE)`,
 		issueID, userID)
 	if err != nil {
 		err = errors.Wrapf(err, "adding participant %d in issue %d", userID, issueID)
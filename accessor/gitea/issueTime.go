@@ -0,0 +1,92 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// getIssueTrackedTimeID retrieves the id of the time tracked by a user against an issue at a given time, returns NullID if no such tracked time
+func (accessor *DefaultAccessor) getIssueTrackedTimeID(issueID int64, trackedTime *TrackedTime) (int64, error) {
+	var trackedTimeID = NullID
+	err := accessor.queryRow(`
+		SELECT id FROM tracked_time WHERE issue_id = $1 AND user_id = $2 AND created_unix = $3
+		`, issueID, trackedTime.UserID, trackedTime.Time).Scan(&trackedTimeID)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving id for time tracked by user %d on issue %d at %s", trackedTime.UserID, issueID, time.Unix(trackedTime.Time, 0))
+		return NullID, err
+	}
+
+	return trackedTimeID, nil
+}
+
+// updateIssueTrackedTime updates existing tracked time
+func (accessor *DefaultAccessor) updateIssueTrackedTime(trackedTimeID int64, issueID int64, trackedTime *TrackedTime) error {
+	_, err := accessor.exec(`UPDATE tracked_time SET time=$1, deleted=FALSE WHERE id=$2`,
+		trackedTime.Seconds, trackedTimeID)
+	if err != nil {
+		err = errors.Wrapf(err, "updating time tracked by user %d on issue %d at %s", trackedTime.UserID, issueID, time.Unix(trackedTime.Time, 0))
+		return err
+	}
+
+	log.Debug("updated time tracked by user %d on issue %d at %s (id %d)", trackedTime.UserID, issueID, time.Unix(trackedTime.Time, 0), trackedTimeID)
+
+	return nil
+}
+
+// insertIssueTrackedTime creates new tracked time
+func (accessor *DefaultAccessor) insertIssueTrackedTime(issueID int64, trackedTime *TrackedTime) error {
+	_, err := accessor.exec(`
+		INSERT INTO tracked_time(issue_id, user_id, created_unix, time, deleted) VALUES ($1, $2, $3, $4, FALSE)`,
+		issueID, trackedTime.UserID, trackedTime.Time, trackedTime.Seconds)
+	if err != nil {
+		err = errors.Wrapf(err, "adding time tracked by user %d on issue %d at %s", trackedTime.UserID, issueID, time.Unix(trackedTime.Time, 0))
+		return err
+	}
+
+	log.Debug("added %ds time tracked by user %d on issue %d at %s", trackedTime.Seconds, trackedTime.UserID, issueID, time.Unix(trackedTime.Time, 0))
+
+	return nil
+}
+
+// AddIssueTrackedTime records time tracked against a Gitea issue
+func (accessor *DefaultAccessor) AddIssueTrackedTime(issueID int64, trackedTime *TrackedTime) error {
+	trackedTimeID, err := accessor.getIssueTrackedTimeID(issueID, trackedTime)
+	if err != nil {
+		return err
+	}
+
+	if trackedTimeID == NullID {
+		return accessor.insertIssueTrackedTime(issueID, trackedTime)
+	}
+
+	if accessor.overwrite {
+		err = accessor.updateIssueTrackedTime(trackedTimeID, issueID, trackedTime)
+		if err != nil {
+			return err
+		}
+	} else {
+		log.Debug("issue %d already has time tracked by user %d at %s - ignored", issueID, trackedTime.UserID, time.Unix(trackedTime.Time, 0))
+	}
+
+	return nil
+}
+
+// SetIssueTimeEstimate sets the estimated time (in seconds) for a Gitea issue
+func (accessor *DefaultAccessor) SetIssueTimeEstimate(issueID int64, seconds int64) error {
+	_, err := accessor.exec(`UPDATE issue SET time_estimate = $1 WHERE id = $2`, seconds, issueID)
+	if err != nil {
+		err = errors.Wrapf(err, "setting time estimate for issue %d", issueID)
+		return err
+	}
+
+	log.Debug("set time estimate for issue %d to %ds", issueID, seconds)
+
+	return nil
+}
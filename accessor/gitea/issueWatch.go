@@ -0,0 +1,71 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// getIssueWatchID retrieves the id of the given user's watch on an issue, returns NullID if no such watch
+func (accessor *DefaultAccessor) getIssueWatchID(issueID int64, userID int64) (int64, error) {
+	var issueWatchID = NullID
+	err := accessor.queryRow(`
+		SELECT id FROM issue_watch WHERE issue_id = $1 AND user_id = $2
+		`, issueID, userID).Scan(&issueWatchID)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving id for watch by user %d on issue %d", userID, issueID)
+		return NullID, err
+	}
+
+	return issueWatchID, nil
+}
+
+// updateIssueWatch updates an existing issue watch
+func (accessor *DefaultAccessor) updateIssueWatch(issueWatchID int64, issueID int64, userID int64, isWatching bool, time int64) error {
+	_, err := accessor.exec(`UPDATE issue_watch SET is_watching=$1, updated_unix=$2 WHERE id=$3`,
+		isWatching, time, issueWatchID)
+	if err != nil {
+		err = errors.Wrapf(err, "updating watch by user %d on issue %d", userID, issueID)
+		return err
+	}
+
+	log.Debug("updated watch by user %d on issue %d (id %d) to %t", userID, issueID, issueWatchID, isWatching)
+
+	return nil
+}
+
+// insertIssueWatch creates a new issue watch
+func (accessor *DefaultAccessor) insertIssueWatch(issueID int64, userID int64, isWatching bool, time int64) error {
+	_, err := accessor.exec(`
+		INSERT INTO issue_watch(user_id, issue_id, is_watching, created_unix, updated_unix) VALUES ($1, $2, $3, $4, $4)`,
+		userID, issueID, isWatching, time)
+	if err != nil {
+		err = errors.Wrapf(err, "adding watch by user %d on issue %d", userID, issueID)
+		return err
+	}
+
+	log.Debug("added watch by user %d on issue %d (%t)", userID, issueID, isWatching)
+
+	return nil
+}
+
+// SetIssueWatch sets whether or not a user is watching a Gitea issue.
+// Unlike most other issue data, an existing watch is always updated: watches are set by a sequence of Trac Cc changes,
+// each of which must take effect.
+func (accessor *DefaultAccessor) SetIssueWatch(issueID int64, userID int64, isWatching bool, time int64) error {
+	issueWatchID, err := accessor.getIssueWatchID(issueID, userID)
+	if err != nil {
+		return err
+	}
+
+	if issueWatchID == NullID {
+		return accessor.insertIssueWatch(issueID, userID, isWatching, time)
+	}
+
+	return accessor.updateIssueWatch(issueWatchID, issueID, userID, isWatching, time)
+}
@@ -15,7 +15,7 @@ import (
 // GetLabelID retrieves the id of the given label, returns NullID if no such label
 func (accessor *DefaultAccessor) GetLabelID(labelName string) (int64, error) {
 	var labelID int64 = NullID
-	err := accessor.db.QueryRow(`
+	err := accessor.queryRow(`
 		SELECT id FROM label WHERE repo_id = $1 AND name = $2
 		`, accessor.repoID, labelName).Scan(&labelID)
 	if err != nil && err != sql.ErrNoRows {
@@ -28,8 +28,8 @@ func (accessor *DefaultAccessor) GetLabelID(labelName string) (int64, error) {
 
 // updateLabel updates an existing label
 func (accessor *DefaultAccessor) updateLabel(labelID int64, label *Label) error {
-	_, err := accessor.db.Exec(`UPDATE label SET repo_id=?, name=?, description=?, color=? WHERE id=?`,
-		accessor.repoID, label.Name, label.Description, label.Color, labelID)
+	_, err := accessor.exec(`UPDATE label SET repo_id=$1, name=$2, description=$3, color=$4, exclusive=$5 WHERE id=$6`,
+		accessor.repoID, label.Name, label.Description, label.Color, label.Exclusive, labelID)
 	if err != nil {
 		err = errors.Wrapf(err, "updating label %s", label.Name)
 		return err
@@ -42,21 +42,14 @@ func (accessor *DefaultAccessor) updateLabel(labelID int64, label *Label) error
 
 // insertLabel inserts a new label, returns label id.
 func (accessor *DefaultAccessor) insertLabel(label *Label) (int64, error) {
-	_, err := accessor.db.Exec(`
-		INSERT INTO label(repo_id, name, description, color) VALUES($1, $2, $3, $4)`,
-		accessor.repoID, label.Name, label.Description, label.Color)
+	labelID, err := accessor.insert(`
+		INSERT INTO label(repo_id, name, description, color, exclusive) VALUES($1, $2, $3, $4, $5)`,
+		accessor.repoID, label.Name, label.Description, label.Color, label.Exclusive)
 	if err != nil {
 		err = errors.Wrapf(err, "adding label %s", label.Name)
 		return NullID, err
 	}
 
-	var labelID int64
-	err = accessor.db.QueryRow(`SELECT last_insert_rowid()`).Scan(&labelID)
-	if err != nil {
-		err = errors.Wrapf(err, "retrieving id of new label %s", label.Name)
-		return NullID, err
-	}
-
 	log.Debug("added label %s, color %s (id %d)", label.Name, label.Color, labelID)
 
 	return labelID, nil
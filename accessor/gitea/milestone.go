@@ -15,7 +15,7 @@ import (
 // GetMilestoneID gets the ID of a named milestone - returns NullID if no such milestone
 func (accessor *DefaultAccessor) GetMilestoneID(milestoneName string) (int64, error) {
 	var milestoneID int64 = NullID
-	err := accessor.db.QueryRow(`SELECT id FROM milestone WHERE name = $1 AND repo_id = $2`, milestoneName, accessor.repoID).Scan(&milestoneID)
+	err := accessor.queryRow(`SELECT id FROM milestone WHERE name = $1 AND repo_id = $2`, milestoneName, accessor.repoID).Scan(&milestoneID)
 	if err != nil && err != sql.ErrNoRows {
 		err = errors.Wrapf(err, "retrieving id of milestone %s", milestoneName)
 		return NullID, err
@@ -26,8 +26,8 @@ func (accessor *DefaultAccessor) GetMilestoneID(milestoneName string) (int64, er
 
 // updateMilestone updates an existing milestone
 func (accessor *DefaultAccessor) updateMilestone(milestoneID int64, milestone *Milestone) error {
-	_, err := accessor.db.Exec(`
-		UPDATE milestone SET repo_id=?, name=?, content=?, is_closed=?, deadline_unix=?, closed_date_unix=? WHERE id=?`,
+	_, err := accessor.exec(`
+		UPDATE milestone SET repo_id=$1, name=$2, content=$3, is_closed=$4, deadline_unix=$5, closed_date_unix=$6 WHERE id=$7`,
 		accessor.repoID, milestone.Name, milestone.Description, milestone.Closed, milestone.DueTime, milestone.ClosedTime, milestoneID)
 	if err != nil {
 		err = errors.Wrapf(err, "updating milestone %s", milestone.Name)
@@ -41,7 +41,7 @@ func (accessor *DefaultAccessor) updateMilestone(milestoneID int64, milestone *M
 
 // insertMilestone inserts a new milestone, returns milstone id.
 func (accessor *DefaultAccessor) insertMilestone(milestone *Milestone) (int64, error) {
-	_, err := accessor.db.Exec(`
+	milestoneID, err := accessor.insert(`
 		INSERT INTO	milestone(repo_id, name, content, is_closed, deadline_unix, closed_date_unix) VALUES($1, $2, $3, $4, $5, $6)`,
 		accessor.repoID, milestone.Name, milestone.Description, milestone.Closed, milestone.DueTime, milestone.ClosedTime)
 	if err != nil {
@@ -49,13 +49,6 @@ func (accessor *DefaultAccessor) insertMilestone(milestone *Milestone) (int64, e
 		return NullID, err
 	}
 
-	var milestoneID int64
-	err = accessor.db.QueryRow(`SELECT last_insert_rowid()`).Scan(&milestoneID)
-	if err != nil {
-		err = errors.Wrapf(err, "retrieving id of new milestone %s", milestone.Name)
-		return NullID, err
-	}
-
 	log.Debug("added milestone %s (id %d)", milestone.Name, milestoneID)
 
 	return milestoneID, nil
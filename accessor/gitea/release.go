@@ -0,0 +1,102 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// HasTag returns true if our chosen Gitea repository has a git tag of the given name.
+// Gitea records each git tag in the release table, either as a plain tag or as the tag of a published release.
+func (accessor *DefaultAccessor) HasTag(tagName string) (bool, error) {
+	var count int64
+	err := accessor.queryRow(`
+		SELECT COUNT(*) FROM `+accessor.dialect.quoteIdentifier("release")+`
+			WHERE repo_id = $1 AND lower_tag_name = $2 AND COALESCE(sha1, '') <> ''`,
+		accessor.repoID, strings.ToLower(tagName)).Scan(&count)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving git tag %s", tagName)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// getReleaseID retrieves the id of the release (or plain git tag) with the given tag name - returns NullID if no such release.
+// Also returns whether the entry found is a plain git tag rather than a release.
+func (accessor *DefaultAccessor) getReleaseID(tagName string) (int64, bool, error) {
+	var releaseID int64 = NullID
+	var isTag bool
+	err := accessor.queryRow(`
+		SELECT id, is_tag FROM `+accessor.dialect.quoteIdentifier("release")+` WHERE repo_id = $1 AND lower_tag_name = $2`,
+		accessor.repoID, strings.ToLower(tagName)).Scan(&releaseID, &isTag)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving id of release %s", tagName)
+		return NullID, false, err
+	}
+
+	return releaseID, isTag, nil
+}
+
+// updateRelease updates an existing release, or turns a plain git tag into a release.
+func (accessor *DefaultAccessor) updateRelease(releaseID int64, release *Release) error {
+	_, err := accessor.exec(`
+		UPDATE `+accessor.dialect.quoteIdentifier("release")+`
+			SET publisher_id=$1, title=$2, note=$3, is_draft=$4, is_tag=$5, created_unix=$6 WHERE id=$7`,
+		release.PublisherID, release.Title, release.Description, release.IsDraft, false, release.Created, releaseID)
+	if err != nil {
+		err = errors.Wrapf(err, "updating release %s", release.TagName)
+		return err
+	}
+
+	log.Debug("updated release %s (id %d)", release.TagName, releaseID)
+
+	return nil
+}
+
+// insertRelease inserts a new release, returns release id.
+// A release inserted here has no git tag: Gitea creates the tag when the release is published.
+func (accessor *DefaultAccessor) insertRelease(release *Release) (int64, error) {
+	releaseID, err := accessor.insert(`
+		INSERT INTO `+accessor.dialect.quoteIdentifier("release")+`(repo_id, publisher_id, tag_name, lower_tag_name, title, sha1, note, is_draft, is_prerelease, is_tag, created_unix)
+			VALUES($1, $2, $3, $4, $5, '', $6, $7, $8, $9, $10)`,
+		accessor.repoID, release.PublisherID, release.TagName, strings.ToLower(release.TagName), release.Title, release.Description, release.IsDraft, false, false, release.Created)
+	if err != nil {
+		err = errors.Wrapf(err, "adding release %s", release.TagName)
+		return NullID, err
+	}
+
+	log.Debug("added release %s (id %d)", release.TagName, releaseID)
+
+	return releaseID, nil
+}
+
+// AddRelease adds a release to Gitea, returns id of created release.
+// A release for an existing plain git tag is attached to that tag.
+func (accessor *DefaultAccessor) AddRelease(release *Release) (int64, error) {
+	releaseID, isTag, err := accessor.getReleaseID(release.TagName)
+	if err != nil {
+		return NullID, err
+	}
+
+	if releaseID == NullID {
+		return accessor.insertRelease(release)
+	}
+
+	if isTag || accessor.overwrite {
+		err = accessor.updateRelease(releaseID, release)
+		if err != nil {
+			return NullID, err
+		}
+	} else {
+		log.Debug("release %s already exists - ignored", release.TagName)
+	}
+
+	return releaseID, nil
+}
@@ -13,7 +13,7 @@ import (
 
 func (accessor *DefaultAccessor) getRepoID(userName string, repoName string) (int64, error) {
 	var id int64 = NullID
-	err := accessor.db.QueryRow(`SELECT r.id FROM repository r, user u WHERE r.owner_id =
+	err := accessor.queryRow(`SELECT r.id FROM repository r, `+accessor.dialect.quoteIdentifier("user")+` u WHERE r.owner_id =
 			u.id AND u.name = $1 AND r.name = $2`, userName, repoName).Scan(&id)
 	if err != nil && err != sql.ErrNoRows {
 		err = errors.Wrapf(err, "retrieving id of repository %s for user %s", repoName, userName)
@@ -25,10 +25,10 @@ func (accessor *DefaultAccessor) getRepoID(userName string, repoName string) (in
 
 // UpdateRepoIssueCounts updates issue counts for our chosen Gitea repository.
 func (accessor *DefaultAccessor) UpdateRepoIssueCounts() error {
-	_, err := accessor.db.Exec(`
+	_, err := accessor.exec(`
 		UPDATE repository SET 
 			num_issues = (SELECT COUNT(id) FROM issue),
-			num_closed_issues = (SELECT COUNT(id) FROM issue WHERE is_closed=1)
+			num_closed_issues = (SELECT COUNT(id) FROM issue WHERE is_closed=TRUE)
 			WHERE id = $1`, accessor.repoID)
 	if err != nil {
 		err = errors.Wrapf(err, "updating number of issues for repository %d", accessor.repoID)
@@ -40,10 +40,10 @@ func (accessor *DefaultAccessor) UpdateRepoIssueCounts() error {
 
 // UpdateRepoMilestoneCounts updates milestone counts for our chosen Gitea repository.
 func (accessor *DefaultAccessor) UpdateRepoMilestoneCounts() error {
-	_, err := accessor.db.Exec(`
+	_, err := accessor.exec(`
 		UPDATE repository SET 
 			num_milestones = (SELECT COUNT(id) FROM milestone),
-			num_closed_milestones = (SELECT COUNT(id) FROM milestone WHERE is_closed=1)
+			num_closed_milestones = (SELECT COUNT(id) FROM milestone WHERE is_closed=TRUE)
 			WHERE id = $1`, accessor.repoID)
 	if err != nil {
 		err = errors.Wrapf(err, "updating number of milestones for repository %d", accessor.repoID)
@@ -53,6 +53,17 @@ func (accessor *DefaultAccessor) UpdateRepoMilestoneCounts() error {
 	return nil
 }
 
+// SetRepoOriginalServiceType records the type of the git service from which our chosen Gitea repository was migrated.
+func (accessor *DefaultAccessor) SetRepoOriginalServiceType(serviceType int64) error {
+	_, err := accessor.exec(`UPDATE repository SET original_service_type = $1 WHERE id = $2`, serviceType, accessor.repoID)
+	if err != nil {
+		err = errors.Wrapf(err, "setting original service type of repository %d", accessor.repoID)
+		return err
+	}
+
+	return nil
+}
+
 // GetCommitURL retrieves the URL for viewing a given commit in the current repository
 func (accessor *DefaultAccessor) GetCommitURL(commitID string) string {
 	repoURL := accessor.getUserRepoURL()
@@ -0,0 +1,257 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// Gitea repository unit types (code, issues, pull requests, releases, wiki) to which imported teams are given access
+var teamUnitTypes = []int{1, 2, 3, 4, 5}
+
+// getOrganizationID retrieves the id of the organization owning our repository, returns an error if the repository is owned by a user.
+func (accessor *DefaultAccessor) getOrganizationID() (int64, error) {
+	ownerID, isOrganization, err := accessor.getRepoOwner()
+	if err != nil {
+		return NullID, err
+	}
+	if !isOrganization {
+		return NullID, fmt.Errorf("repository %s of %s is not owned by an organization", accessor.repoName, accessor.userName)
+	}
+
+	return ownerID, nil
+}
+
+// getTeamID retrieves the id of a named team within an organization, returns NullID if no such team
+func (accessor *DefaultAccessor) getTeamID(orgID int64, teamName string) (int64, error) {
+	var teamID = NullID
+	err := accessor.queryRow(`
+		SELECT id FROM team WHERE org_id = $1 AND lower_name = $2
+		`, orgID, strings.ToLower(teamName)).Scan(&teamID)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving id of team %s", teamName)
+		return NullID, err
+	}
+
+	return teamID, nil
+}
+
+// updateTeam updates an existing team
+func (accessor *DefaultAccessor) updateTeam(teamID int64, team *Team) error {
+	_, err := accessor.exec(`UPDATE team SET description=$1, authorize=$2 WHERE id=$3`,
+		team.Description, team.Access, teamID)
+	if err == nil {
+		_, err = accessor.exec(`UPDATE team_unit SET access_mode=$1 WHERE team_id=$2`, team.Access, teamID)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "updating team %s", team.Name)
+		return err
+	}
+
+	log.Debug("updated team %s (id %d)", team.Name, teamID)
+
+	return nil
+}
+
+// insertTeam creates a new team within an organization
+func (accessor *DefaultAccessor) insertTeam(orgID int64, team *Team) (int64, error) {
+	teamID, err := accessor.insert(`
+		INSERT INTO team(org_id, lower_name, name, description, authorize, num_repos, num_members, includes_all_repositories, can_create_org_repo)
+			VALUES ($1, $2, $3, $4, $5, 0, 0, FALSE, FALSE)`,
+		orgID, strings.ToLower(team.Name), team.Name, team.Description, team.Access)
+	if err != nil {
+		err = errors.Wrapf(err, "adding team %s", team.Name)
+		return NullID, err
+	}
+
+	for _, unitType := range teamUnitTypes {
+		_, err = accessor.exec(`
+			INSERT INTO team_unit(org_id, team_id, type, access_mode) VALUES ($1, $2, $3, $4)`,
+			orgID, teamID, unitType, team.Access)
+		if err != nil {
+			err = errors.Wrapf(err, "adding unit %d to team %s", unitType, team.Name)
+			return NullID, err
+		}
+	}
+
+	_, err = accessor.exec(`
+		UPDATE `+accessor.dialect.quoteIdentifier("user")+` SET num_teams = (SELECT COUNT(id) FROM team WHERE org_id = $1) WHERE id = $1`,
+		orgID)
+	if err != nil {
+		err = errors.Wrapf(err, "updating number of teams for organization %d", orgID)
+		return NullID, err
+	}
+
+	log.Debug("added team %s (id %d)", team.Name, teamID)
+
+	return teamID, nil
+}
+
+// getTeamAccess retrieves the access given to our repository by a team
+func (accessor *DefaultAccessor) getTeamAccess(teamID int64) (AccessMode, error) {
+	var access AccessMode = NoAccess
+	err := accessor.queryRow(`SELECT authorize FROM team WHERE id = $1`, teamID).Scan(&access)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving access of team %d", teamID)
+		return NoAccess, err
+	}
+
+	return access, nil
+}
+
+// addTeamRepo gives a team access to our repository, if it does not already have it
+func (accessor *DefaultAccessor) addTeamRepo(orgID int64, teamID int64) error {
+	var teamRepoID = NullID
+	err := accessor.queryRow(`
+		SELECT id FROM team_repo WHERE team_id = $1 AND repo_id = $2
+		`, teamID, accessor.repoID).Scan(&teamRepoID)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving access of team %d to repository %d", teamID, accessor.repoID)
+		return err
+	}
+	if teamRepoID == NullID {
+		_, err = accessor.exec(`INSERT INTO team_repo(org_id, team_id, repo_id) VALUES ($1, $2, $3)`, orgID, teamID, accessor.repoID)
+		if err == nil {
+			_, err = accessor.exec(`UPDATE team SET num_repos = (SELECT COUNT(id) FROM team_repo WHERE team_id = $1) WHERE id = $1`, teamID)
+		}
+		if err != nil {
+			err = errors.Wrapf(err, "giving team %d access to repository %d", teamID, accessor.repoID)
+			return err
+		}
+	}
+
+	// existing members of the team gain access to our repository
+	access, err := accessor.getTeamAccess(teamID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := accessor.query(`SELECT uid FROM team_user WHERE team_id = $1`, teamID)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving members of team %d", teamID)
+		return err
+	}
+	userIDs := []int64{}
+	for rows.Next() {
+		var userID int64
+		if err = rows.Scan(&userID); err != nil {
+			err = errors.Wrapf(err, "retrieving member of team %d", teamID)
+			return err
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	for _, userID := range userIDs {
+		if err = accessor.grantAccess(userID, access); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddTeam adds a team to the organization owning our repository, or finds an existing team of the same name, and gives it access to the repository.
+// Returns the id of the team.
+func (accessor *DefaultAccessor) AddTeam(team *Team) (int64, error) {
+	orgID, err := accessor.getOrganizationID()
+	if err != nil {
+		return NullID, err
+	}
+
+	teamID, err := accessor.getTeamID(orgID, team.Name)
+	if err != nil {
+		return NullID, err
+	}
+
+	if teamID == NullID {
+		teamID, err = accessor.insertTeam(orgID, team)
+	} else if accessor.overwrite {
+		err = accessor.updateTeam(teamID, team)
+	} else {
+		log.Debug("team %s already exists - extending it with repository %d", team.Name, accessor.repoID)
+	}
+	if err != nil {
+		return NullID, err
+	}
+
+	if err = accessor.addTeamRepo(orgID, teamID); err != nil {
+		return NullID, err
+	}
+
+	return teamID, nil
+}
+
+// addOrganizationMember makes a user a member of an organization, if they are not already one
+func (accessor *DefaultAccessor) addOrganizationMember(orgID int64, userID int64) error {
+	var orgUserID = NullID
+	err := accessor.queryRow(`
+		SELECT id FROM org_user WHERE org_id = $1 AND uid = $2
+		`, orgID, userID).Scan(&orgUserID)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving membership of user %d in organization %d", userID, orgID)
+		return err
+	}
+	if orgUserID != NullID {
+		return nil
+	}
+
+	_, err = accessor.exec(`INSERT INTO org_user(uid, org_id, is_public) VALUES ($1, $2, FALSE)`, userID, orgID)
+	if err == nil {
+		_, err = accessor.exec(`
+			UPDATE `+accessor.dialect.quoteIdentifier("user")+` SET num_members = (SELECT COUNT(id) FROM org_user WHERE org_id = $1) WHERE id = $1`,
+			orgID)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "adding user %d to organization %d", userID, orgID)
+		return err
+	}
+
+	return nil
+}
+
+// AddTeamMember adds a user to a team of the organization owning our repository.
+func (accessor *DefaultAccessor) AddTeamMember(teamID int64, userID int64) error {
+	orgID, err := accessor.getOrganizationID()
+	if err != nil {
+		return err
+	}
+
+	var teamUserID = NullID
+	err = accessor.queryRow(`
+		SELECT id FROM team_user WHERE team_id = $1 AND uid = $2
+		`, teamID, userID).Scan(&teamUserID)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving membership of user %d in team %d", userID, teamID)
+		return err
+	}
+
+	if teamUserID == NullID {
+		_, err = accessor.exec(`INSERT INTO team_user(org_id, team_id, uid) VALUES ($1, $2, $3)`, orgID, teamID, userID)
+		if err == nil {
+			_, err = accessor.exec(`UPDATE team SET num_members = (SELECT COUNT(id) FROM team_user WHERE team_id = $1) WHERE id = $1`, teamID)
+		}
+		if err != nil {
+			err = errors.Wrapf(err, "adding user %d to team %d", userID, teamID)
+			return err
+		}
+		log.Debug("added user %d to team %d", userID, teamID)
+	}
+
+	if err = accessor.addOrganizationMember(orgID, userID); err != nil {
+		return err
+	}
+
+	access, err := accessor.getTeamAccess(teamID)
+	if err != nil {
+		return err
+	}
+
+	return accessor.grantAccess(userID, access)
+}
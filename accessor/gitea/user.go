@@ -8,8 +8,10 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
 )
 
 // GetUserID retrieves the id of a named Gitea user - returns NullID if no such user.
@@ -19,7 +21,7 @@ func (accessor *DefaultAccessor) GetUserID(userName string) (int64, error) {
 	}
 
 	var id int64 = NullID
-	err := accessor.db.QueryRow(`SELECT id FROM user WHERE lower_name = $1 or email = $1`, userName).Scan(&id)
+	err := accessor.queryRow(`SELECT id FROM `+accessor.dialect.quoteIdentifier("user")+` WHERE lower_name = $1 or email = $1`, userName).Scan(&id)
 	if err != nil && err != sql.ErrNoRows {
 		err = errors.Wrapf(err, "retrieving id of user %s", userName)
 		return NullID, err
@@ -31,7 +33,7 @@ func (accessor *DefaultAccessor) GetUserID(userName string) (int64, error) {
 // GetUserEMailAddress retrieves the email address of a given user
 func (accessor *DefaultAccessor) GetUserEMailAddress(userName string) (string, error) {
 	var emailAddress string = ""
-	err := accessor.db.QueryRow(`SELECT email FROM user WHERE lower_name = $1`, userName).Scan(&emailAddress)
+	err := accessor.queryRow(`SELECT email FROM `+accessor.dialect.quoteIdentifier("user")+` WHERE lower_name = $1`, userName).Scan(&emailAddress)
 	if err != nil && err != sql.ErrNoRows {
 		err = errors.Wrapf(err, "retrieving email address of user %s", userName)
 		return "", err
@@ -50,8 +52,8 @@ func (accessor *DefaultAccessor) getUserRepoURL() string {
 func (accessor *DefaultAccessor) MatchUser(userName string, userEmail string) (string, error) {
 	var matchedUserName = ""
 	lcUserName := strings.ToLower(userName)
-	err := accessor.db.QueryRow(`
-		SELECT lower_name FROM user 
+	err := accessor.queryRow(`
+		SELECT lower_name FROM `+accessor.dialect.quoteIdentifier("user")+`
 		WHERE lower_name = $1 
 		OR full_name = $2 
 		OR email = $3`, lcUserName, userName, userEmail).Scan(&matchedUserName)
@@ -62,3 +64,120 @@ func (accessor *DefaultAccessor) MatchUser(userName string, userEmail string) (s
 
 	return matchedUserName, nil
 }
+
+// GetUsers retrieves all Gitea users (excluding organizations), passing each one to the provided "handler" function.
+func (accessor *DefaultAccessor) GetUsers(handlerFn func(user *User) error) error {
+	rows, err := accessor.query(`
+		SELECT name, COALESCE(full_name, ''), COALESCE(email, '') FROM ` + accessor.dialect.quoteIdentifier("user") + ` WHERE type = 0`)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving users")
+		return err
+	}
+
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.Name, &user.FullName, &user.Email); err != nil {
+			err = errors.Wrapf(err, "retrieving user")
+			return err
+		}
+
+		if err = handlerFn(&user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetExternalUserID retrieves the id of the Gitea user who has linked the account with a given external id from a given login source
+// - returns NullID if no such user.
+func (accessor *DefaultAccessor) GetExternalUserID(loginSourceID int64, externalID string) (int64, error) {
+	var id int64 = NullID
+	err := accessor.queryRow(`
+		SELECT user_id FROM external_login_user WHERE login_source_id = $1 AND external_id = $2
+		`, loginSourceID, externalID).Scan(&id)
+	if err != nil && err != sql.ErrNoRows {
+		err = errors.Wrapf(err, "retrieving user linked to external id %s of login source %d", externalID, loginSourceID)
+		return NullID, err
+	}
+
+	return id, nil
+}
+
+// gitServiceTypes maps the names of the git services from which Gitea can migrate repositories onto Gitea's codes for them:
+// when a user links an account from a login source named after one of these services, Gitea hands over to them the content of repositories
+// migrated from that service whose original author id is the external id of the account.
+var gitServiceTypes = map[string]int64{
+	"github": 2, "gitea": 3, "gitlab": 4, "gogs": 5, "onedev": 6, "gitbucket": 7, "codebase": 8, "codecommit": 9,
+}
+
+// GetLoginSourceServiceType retrieves the type of the git service after which a given login source is named
+// - returns 0 if the login source is not named after a git service from which Gitea can migrate repositories.
+func (accessor *DefaultAccessor) GetLoginSourceServiceType(loginSourceID int64) (int64, error) {
+	var name string
+	err := accessor.queryRow(`SELECT name FROM login_source WHERE id = $1`, loginSourceID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("cannot find login source %d", loginSourceID)
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving name of login source %d", loginSourceID)
+		return 0, err
+	}
+
+	return gitServiceTypes[strings.ToLower(name)], nil
+}
+
+// noReplyEMailAddress returns the no-reply email address used for a Gitea user with no email address of their own
+func (accessor *DefaultAccessor) noReplyEMailAddress(userName string) string {
+	noReplyAddress := accessor.GetStringConfig("service", "NO_REPLY_ADDRESS")
+	if noReplyAddress == "" {
+		domain := accessor.GetStringConfig("server", "DOMAIN")
+		if domain == "" {
+			domain = "localhost"
+		}
+		noReplyAddress = "noreply." + domain
+	}
+
+	return strings.ToLower(userName) + "@" + noReplyAddress
+}
+
+// AddPlaceholderUser creates a restricted Gitea user who cannot log in, to stand in for a Trac user with no Gitea equivalent.
+// A no-reply email address is used if the user has none. Returns the id of the created user.
+// The user is created as a local user (so that an administrator can later enable their login)
+// and their email address is only added to the separate table of email addresses if this version of Gitea has one.
+func (accessor *DefaultAccessor) AddPlaceholderUser(user *User) (int64, error) {
+	email := user.Email
+	if email == "" {
+		email = accessor.noReplyEMailAddress(user.Name)
+	}
+
+	now := time.Now().Unix()
+	userID, err := accessor.insert(`
+		INSERT INTO `+accessor.dialect.quoteIdentifier("user")+`(
+			lower_name, name, full_name, email, keep_email_private, passwd, login_type, login_source, login_name,
+			type, is_active, is_admin, is_restricted, prohibit_login, avatar_email, created_unix, updated_unix)
+			VALUES ($1, $2, $3, $4, TRUE, '', 1, 0, '', 0, TRUE, FALSE, TRUE, TRUE, $4, $5, $5)`,
+		strings.ToLower(user.Name), user.Name, user.FullName, email, now)
+	if err != nil {
+		err = errors.Wrapf(err, "adding placeholder user %s", user.Name)
+		return NullID, err
+	}
+
+	hasEMailAddressTable, err := accessor.hasTable("email_address")
+	if err != nil {
+		return NullID, err
+	}
+	if hasEMailAddressTable {
+		_, err = accessor.exec(`
+			INSERT INTO email_address(uid, email, lower_email, is_activated, is_primary) VALUES ($1, $2, $3, TRUE, TRUE)`,
+			userID, email, strings.ToLower(email))
+		if err != nil {
+			err = errors.Wrapf(err, "adding email address %s for placeholder user %s", email, user.Name)
+			return NullID, err
+		}
+	}
+
+	log.Debug("added placeholder user %s (id %d)", user.Name, userID)
+
+	return userID, nil
+}
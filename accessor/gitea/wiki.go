@@ -5,6 +5,7 @@
 package gitea
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
@@ -18,6 +19,79 @@ import (
 	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
 )
 
+// wikiRepository manages the local clone of the Gitea wiki repository - this is common to all Gitea accessors.
+type wikiRepository struct {
+	userName      string
+	wikiRepoURL   string
+	wikiRepoToken string
+	wikiRepoDir   string
+	wikiRepo      *git.Repository
+	overwrite     bool
+	pushWiki      bool
+}
+
+// createWikiRepository creates a manager for the wiki repository of a Gitea repository.
+// If no explicit wiki repository URL is provided, the URL is derived from the Gitea server root URL.
+// If no explicit directory into which to clone the wiki is provided, the current directory is used.
+func createWikiRepository(
+	rootURL string,
+	giteaUserName string,
+	giteaRepoName string,
+	giteaWikiRepoURL string,
+	giteaWikiRepoToken string,
+	giteaWikiRepoDir string,
+	overwriteData bool,
+	pushWiki bool) (*wikiRepository, error) {
+	wiki := wikiRepository{
+		userName:      giteaUserName,
+		wikiRepoURL:   "",
+		wikiRepoToken: "",
+		wikiRepoDir:   "",
+		wikiRepo:      nil,
+		overwrite:     overwriteData,
+		pushWiki:      pushWiki}
+
+	// find directory into which to clone wiki
+	wikiRepoName := giteaRepoName + ".wiki"
+	if giteaWikiRepoDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			err = errors.Wrapf(err, "getting cwd")
+			return nil, err
+		}
+
+		giteaWikiRepoDir = filepath.Join(cwd, wikiRepoName)
+	}
+	_, err := os.Stat(giteaWikiRepoDir)
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("wiki repository directory %s already exists", giteaWikiRepoDir)
+	}
+	wiki.wikiRepoDir = giteaWikiRepoDir
+
+	// find URL from which clone wiki
+	if giteaWikiRepoURL == "" {
+		if giteaWikiRepoToken != "" {
+			slashSlashPos := strings.Index(rootURL, "//")
+			if slashSlashPos == -1 {
+				return nil, fmt.Errorf("ROOT_URL %s malformed? expecting a '//'", rootURL)
+			}
+
+			// insert username and token into URL - 'http://example.com' should become 'http://<user>:<token>@example.com'
+			rootURL = rootURL[0:slashSlashPos+2] + giteaUserName + ":" + giteaWikiRepoToken + "@" + rootURL[slashSlashPos+2:]
+
+			wiki.wikiRepoToken = giteaWikiRepoToken
+		}
+		if rootURL[len(rootURL)-1:] != "/" {
+			rootURL = rootURL + "/"
+		}
+		giteaWikiRepoURL = fmt.Sprintf("%s%s/%s.git", rootURL, giteaUserName, wikiRepoName)
+	}
+	log.Info("using Wiki repo URL %s", giteaWikiRepoURL)
+	wiki.wikiRepoURL = giteaWikiRepoURL
+
+	return &wiki, nil
+}
+
 // cache of commit message list keyed by page name - use this because retrieving the git commit log is potentially slow
 var commitMessagesByPage map[string][]string
 
@@ -27,37 +101,37 @@ func wikiPageFileName(pageName string) string {
 
 // GetWikiAttachmentRelPath returns the location of an attachment to Trac a wiki page when stored in the Gitea wiki repository.
 // The returned path is relative to the root of the Gitea wiki repository.
-func (accessor *DefaultAccessor) GetWikiAttachmentRelPath(pageName string, filename string) string {
+func (wiki *wikiRepository) GetWikiAttachmentRelPath(pageName string, filename string) string {
 	return filepath.Join("attachments", pageName, filename)
 }
 
 // GetWikiHtdocRelPath returns the location of a given Trac 'htdocs' file when stored in the Gitea wiki repository.
 // The returned path is relative to the root of the Gitea wiki repository.
-func (accessor *DefaultAccessor) GetWikiHtdocRelPath(filename string) string {
+func (wiki *wikiRepository) GetWikiHtdocRelPath(filename string) string {
 	return filepath.Join("htdocs", filename)
 }
 
 // GetWikiFileURL returns a URL for viewing a file stored in the Gitea wiki repository.
-func (accessor *DefaultAccessor) GetWikiFileURL(relpath string) string {
+func (wiki *wikiRepository) GetWikiFileURL(relpath string) string {
 	//FIXME: we want a path to the "raw" wiki repository here - this is my best guess at what this should be but sadly it does not work
 	return "../raw/" + relpath
 }
 
 // CloneWiki clones our wiki repo to the provided directory.
-func (accessor *DefaultAccessor) CloneWiki() error {
+func (wiki *wikiRepository) CloneWiki() error {
 	isBare := false
-	log.Info("cloning wiki repository %s into directory %s", accessor.wikiRepoURL, accessor.wikiRepoDir)
+	log.Info("cloning wiki repository %s into directory %s", wiki.wikiRepoURL, wiki.wikiRepoDir)
 
-	repository, err := git.PlainClone(accessor.wikiRepoDir, isBare, &git.CloneOptions{
-		URL:               accessor.wikiRepoURL,
+	repository, err := git.PlainClone(wiki.wikiRepoDir, isBare, &git.CloneOptions{
+		URL:               wiki.wikiRepoURL,
 		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
 	})
 	if err != nil {
-		err = errors.Wrapf(err, "cloning repository %s into directory %s", accessor.wikiRepoURL, accessor.wikiRepoDir)
+		err = errors.Wrapf(err, "cloning repository %s into directory %s", wiki.wikiRepoURL, wiki.wikiRepoDir)
 		return err
 	}
 
-	accessor.wikiRepo = repository
+	wiki.wikiRepo = repository
 
 	// reset the commit log cache
 	commitMessagesByPage = make(map[string][]string)
@@ -68,8 +142,8 @@ func (accessor *DefaultAccessor) CloneWiki() error {
 // CommitWikiToRepo stages any files added or updated since the last commit then commits them to our cloned wiki repo.
 // We package the staging and commit together here because it is easier than embedding hooks to do the git staging
 // deep into the wiki parsing process where files from the Trac worksapce can get copied over on-the-fly.
-func (accessor *DefaultAccessor) CommitWikiToRepo(author string, authorEMail string, message string) error {
-	worktree, err := accessor.wikiRepo.Worktree()
+func (wiki *wikiRepository) CommitWikiToRepo(author string, authorEMail string, message string) error {
+	worktree, err := wiki.wikiRepo.Worktree()
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving git work tree for cloned wiki")
 		return err
@@ -103,14 +177,14 @@ func (accessor *DefaultAccessor) CommitWikiToRepo(author string, authorEMail str
 }
 
 // CopyFileToWiki copies an external file into the Gitea Wiki, returning a URL through which the file can be viewed/
-func (accessor *DefaultAccessor) CopyFileToWiki(externalFilePath string, giteaWikiRelPath string) error {
+func (wiki *wikiRepository) CopyFileToWiki(externalFilePath string, giteaWikiRelPath string) error {
 	_, err := os.Stat(externalFilePath)
 	if os.IsNotExist(err) {
 		log.Warn("cannot copy non-existant file referenced from Wiki: \"%s\"", externalFilePath)
 		return nil
 	}
 
-	giteaPath := filepath.Join(accessor.wikiRepoDir, giteaWikiRelPath)
+	giteaPath := filepath.Join(wiki.wikiRepoDir, giteaWikiRelPath)
 	giteaDir := path.Dir(giteaPath)
 	err = os.MkdirAll(giteaDir, 0775)
 	if err != nil {
@@ -119,7 +193,7 @@ func (accessor *DefaultAccessor) CopyFileToWiki(externalFilePath string, giteaWi
 	}
 
 	_, err = os.Stat(giteaPath)
-	if accessor.overwrite || !os.IsExist(err) {
+	if wiki.overwrite || !os.IsExist(err) {
 		copyFile(externalFilePath, giteaPath)
 		log.Debug("copied file %s to wiki path %s", externalFilePath, giteaWikiRelPath)
 	}
@@ -128,9 +202,9 @@ func (accessor *DefaultAccessor) CopyFileToWiki(externalFilePath string, giteaWi
 }
 
 // commitLog returns the log of commits for the given file.
-func (accessor *DefaultAccessor) commitLog(pageName string) ([]string, error) {
+func (wiki *wikiRepository) commitLog(pageName string) ([]string, error) {
 	wikiFilename := wikiPageFileName(pageName)
-	wikiFile := filepath.Join(accessor.wikiRepoDir, wikiFilename)
+	wikiFile := filepath.Join(wiki.wikiRepoDir, wikiFilename)
 
 	// if file does not exist then we needn't look for its log...
 	_, err := os.Stat(wikiFile)
@@ -139,7 +213,7 @@ func (accessor *DefaultAccessor) commitLog(pageName string) ([]string, error) {
 		return noCommits, nil
 	}
 
-	commitIter, err := accessor.wikiRepo.Log(&git.LogOptions{FileName: &wikiFilename})
+	commitIter, err := wiki.wikiRepo.Log(&git.LogOptions{FileName: &wikiFilename})
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving git log for file %s", wikiFilename)
 		return nil, err
@@ -155,10 +229,10 @@ func (accessor *DefaultAccessor) commitLog(pageName string) ([]string, error) {
 }
 
 // pageCommitExists determines whether or not a commit of the given page exists with a commit message containing the provided string
-func (accessor *DefaultAccessor) pageCommitExists(pageName string, commitString string) (bool, error) {
+func (wiki *wikiRepository) pageCommitExists(pageName string, commitString string) (bool, error) {
 	commitMessages, haveCommitMessages := commitMessagesByPage[pageName]
 	if !haveCommitMessages {
-		pageCommitMessages, err := accessor.commitLog(pageName)
+		pageCommitMessages, err := wiki.commitLog(pageName)
 		if err != nil {
 			return false, err
 		}
@@ -176,10 +250,10 @@ func (accessor *DefaultAccessor) pageCommitExists(pageName string, commitString
 }
 
 // WriteWikiPage writes (a version of) a wiki page to the checked-out wiki repository, returning the path to the written file.
-func (accessor *DefaultAccessor) WriteWikiPage(pageName string, markdownText string, commitMarker string) (bool, error) {
+func (wiki *wikiRepository) WriteWikiPage(pageName string, markdownText string, commitMarker string) (bool, error) {
 	// if we're not explicitly overwriting, look for conflicting previous commit of wiki page
-	if !accessor.overwrite {
-		hasCommit, err := accessor.pageCommitExists(pageName, commitMarker)
+	if !wiki.overwrite {
+		hasCommit, err := wiki.pageCommitExists(pageName, commitMarker)
 		if err != nil {
 			return false, err
 		}
@@ -188,7 +262,7 @@ func (accessor *DefaultAccessor) WriteWikiPage(pageName string, markdownText str
 		}
 	}
 
-	pagePath := filepath.Join(accessor.wikiRepoDir, wikiPageFileName(pageName))
+	pagePath := filepath.Join(wiki.wikiRepoDir, wikiPageFileName(pageName))
 	pageDir := path.Dir(pagePath)
 	err := os.MkdirAll(pageDir, 0775)
 	if err != nil {
@@ -208,7 +282,7 @@ func (accessor *DefaultAccessor) WriteWikiPage(pageName string, markdownText str
 }
 
 // TranslateWikiPageName translates a Trac wiki page name into a Gitea one
-func (accessor *DefaultAccessor) TranslateWikiPageName(pageName string) string {
+func (wiki *wikiRepository) TranslateWikiPageName(pageName string) string {
 	// special case: Trac "WikiStart" page is Gitea "Home" page...
 	if pageName == "WikiStart" {
 		return "Home"
@@ -219,19 +293,19 @@ func (accessor *DefaultAccessor) TranslateWikiPageName(pageName string) string {
 
 // commitWikiRepo commits all wiki repository changes by pushing all changes to the local wiki repository back to the remote.
 // (Ff pushing the wiki is disabled, the local repository is left and a message is output)
-func (accessor *DefaultAccessor) commitWikiRepo() error {
-	if !accessor.pushWiki {
-		log.Info("wiki updates have been committed to cloned repository %s; please review changes and push back to remote when done.", accessor.wikiRepoDir)
+func (wiki *wikiRepository) commitWikiRepo() error {
+	if !wiki.pushWiki {
+		log.Info("wiki updates have been committed to cloned repository %s; please review changes and push back to remote when done.", wiki.wikiRepoDir)
 		return nil
 	}
 
 	auth := &http.BasicAuth{
-		Username: accessor.userName,
-		Password: accessor.wikiRepoToken,
+		Username: wiki.userName,
+		Password: wiki.wikiRepoToken,
 	}
 
 	log.Debug("pushing wiki to remote")
-	err := accessor.wikiRepo.Push(&git.PushOptions{
+	err := wiki.wikiRepo.Push(&git.PushOptions{
 		RemoteName: "origin",
 		Auth:       auth,
 	})
@@ -240,12 +314,12 @@ func (accessor *DefaultAccessor) commitWikiRepo() error {
 		return err
 	}
 
-	log.Debug("deleting cloned wiki directory %s after pushing it", accessor.wikiRepoDir)
-	return os.RemoveAll(accessor.wikiRepoDir)
+	log.Debug("deleting cloned wiki directory %s after pushing it", wiki.wikiRepoDir)
+	return os.RemoveAll(wiki.wikiRepoDir)
 }
 
 // rollbackWikiRepo rolls back all changes to the wiki repository by deleting the local cloned repository without pushing it.
-func (accessor *DefaultAccessor) rollbackWikiRepo() error {
-	log.Debug("rolling back all wiki repo changes by deleting cloned wiki directory %s", accessor.wikiRepoDir)
-	return os.RemoveAll(accessor.wikiRepoDir)
+func (wiki *wikiRepository) rollbackWikiRepo() error {
+	log.Debug("rolling back all wiki repo changes by deleting cloned wiki directory %s", wiki.wikiRepoDir)
+	return os.RemoveAll(wiki.wikiRepoDir)
 }
@@ -8,6 +8,7 @@ package trac
 type Label struct {
 	Name        string
 	Description string
+	Position    int64 // position of label in Trac's ordering of priorities or severities (from 1), 0 if unordered
 }
 
 // Milestone describes a Trac milestone.
@@ -18,6 +19,13 @@ type Milestone struct {
 	Completed   int64
 }
 
+// Version describes a Trac version defined in the version table.
+type Version struct {
+	Name        string
+	Description string
+	Time        int64 // release time of version, 0 if not yet released
+}
+
 const (
 	// TicketStatusClosed indicates a closed Trac ticket
 	TicketStatusClosed string = "closed"
@@ -26,35 +34,72 @@ const (
 	TicketStatusReopened string = "reopened"
 )
 
+// CustomField describes a Trac custom ticket field, as defined in the [ticket-custom] section of the Trac config.
+type CustomField struct {
+	Name    string
+	Label   string
+	Type    string
+	Options []string
+}
+
+// TicketCustomField describes the value of a custom field on a Trac ticket.
+type TicketCustomField struct {
+	Field *CustomField
+	Value string
+}
+
 // Ticket describes a Trac milestone.
 type Ticket struct {
-	TicketID       int64
-	Summary        string
-	Description    string
-	Owner          string
-	Reporter       string
-	MilestoneName  string
-	ComponentName  string
-	PriorityName   string
-	ResolutionName string
-	SeverityName   string
-	TypeName       string
-	VersionName    string
-	Status         string
-	Created        int64
-	Updated        int64
+	TicketID            int64
+	Summary             string
+	Description         string
+	OriginalDescription string // description as first created - differs from Description if description has been edited
+	Owner               string
+	Reporter            string
+	MilestoneName       string
+	ComponentName       string
+	PriorityName        string
+	ResolutionName      string
+	SeverityName        string
+	TypeName            string
+	VersionName         string
+	Keywords            string
+	Cc                  string
+	Status              string
+	Created             int64
+	Updated             int64
+	CustomFields        []TicketCustomField
+}
+
+// TicketDependency describes a dependency between two Trac tickets, as recorded by the MasterTickets plugin.
+type TicketDependency struct {
+	TicketID         int64 // ticket which is blocked
+	BlockingTicketID int64 // ticket on which it depends
+	Time             int64 // time dependency was added - taken as the creation time of the later ticket if not recorded in the ticket history
 }
 
 // TicketChangeType enumerates the types of ticket change we handle.
 type TicketChangeType string
 
 const (
+	// TicketCcChange denotes a change to a ticket's Cc list.
+	TicketCcChange TicketChangeType = "cc"
+
 	// TicketCommentChange denotes a ticket comment change.
 	TicketCommentChange TicketChangeType = "comment"
 
 	// TicketComponentChange denotes a ticket component change.
 	TicketComponentChange TicketChangeType = "component"
 
+	// TicketCustomFieldChange denotes a change to a ticket custom field.
+	TicketCustomFieldChange TicketChangeType = "custom"
+
+	// TicketDescriptionChange denotes a ticket description change.
+	TicketDescriptionChange TicketChangeType = "description"
+
+	// TicketKeywordsChange denotes a ticket keywords change.
+	TicketKeywordsChange TicketChangeType = "keywords"
+
 	// TicketMilestoneChange denotes a ticket milestone change.
 	TicketMilestoneChange TicketChangeType = "milestone"
 
@@ -84,13 +129,24 @@ const (
 )
 
 // TicketChange describes a change to a Trac ticket.
+// CustomField is only set for changes of type TicketCustomFieldChange.
 type TicketChange struct {
-	TicketID   int64
-	ChangeType TicketChangeType
-	Author     string
-	OldValue   string
-	NewValue   string
-	Time       int64
+	TicketID     int64
+	ChangeType   TicketChangeType
+	CustomField  *CustomField
+	Author       string
+	OldValue     string
+	NewValue     string
+	Time         int64
+	CommentEdits []TicketCommentEdit // edits of a comment change, in the order they were made
+	Initial      bool                // change is a "synthetic" change modelling the initial value of the field rather than a change recorded by Trac
+}
+
+// TicketCommentEdit describes an edit of a Trac ticket comment.
+type TicketCommentEdit struct {
+	Author  string
+	OldText string // comment text before the edit
+	Time    int64
 }
 
 // TicketAttachment describes an attachment to a Trac ticket.
@@ -103,6 +159,13 @@ type TicketAttachment struct {
 	Description string
 }
 
+// UserProfile describes the preferences recorded by Trac for a user.
+type UserProfile struct {
+	UserName string
+	FullName string
+	Email    string
+}
+
 // WikiPage describes a Trac wiki page.
 type WikiPage struct {
 	Name       string
@@ -139,6 +202,18 @@ type Accessor interface {
 	// GetStringConfig retrieves a value from the Trac config as a string.
 	GetStringConfig(sectionName string, configName string) string
 
+	/*
+	 * Custom Fields
+	 */
+	// GetCustomFields retrieves the definitions of all Trac custom ticket fields, passing each one to the provided "handler" function.
+	GetCustomFields(handlerFn func(field *CustomField) error) error
+
+	/*
+	 * Keywords
+	 */
+	// GetKeywords retrieves all keywords used in Trac tickets, passing each one to the provided "handler" function.
+	GetKeywords(handlerFn func(keyword *Label) error) error
+
 	/*
 	 * Milestones
 	 */
@@ -151,10 +226,18 @@ type Accessor interface {
 	// GetFullPath retrieves the absolute path of a path relative to the root of the Trac installation.
 	GetFullPath(element ...string) string
 
+	/*
+	 * Permissions
+	 */
+	// GetPermissions retrieves all Trac permission grants, passing the subject and action of each one to the provided "handler" function.
+	// The subject is a user or group name; the action is either a permission name or, where the subject is a member of a group, the group name.
+	GetPermissions(handlerFn func(subject string, action string) error) error
+
 	/*
 	 * Priorities
 	 */
 	// GetPriorities retrieves all priorities used in Trac tickets, passing each one to the provided "handler" function.
+	// Priorities are retrieved in Trac's order of priority (highest first), followed by any priorities used in tickets but no longer defined by Trac.
 	GetPriorities(handlerFn func(priority *Label) error) error
 
 	/*
@@ -167,8 +250,15 @@ type Accessor interface {
 	 * Severities
 	 */
 	// GetSeverities retrieves all severities used in Trac tickets, passing each one to the provided "handler" function.
+	// Severities are retrieved in Trac's order of severity (highest first), followed by any severities used in tickets but no longer defined by Trac.
 	GetSeverities(handlerFn func(severity *Label) error) error
 
+	/*
+	 * Statuses
+	 */
+	// GetStatuses retrieves all statuses which Trac tickets have or have had, passing each one to the provided "handler" function.
+	GetStatuses(handlerFn func(status *Label) error) error
+
 	/*
 	 * Tickets
 	 */
@@ -184,6 +274,16 @@ type Accessor interface {
 	// GetTicketCommentTime retrieves the timestamp for a given comment for a given Trac ticket
 	GetTicketCommentTime(ticketID int64, changeNum int64) (int64, error)
 
+	// GetTicketComment retrieves a given comment for a given Trac ticket, returns nil if no such comment
+	GetTicketComment(ticketID int64, commentNum int64) (*TicketChange, error)
+
+	/*
+	 * Ticket Dependencies
+	 */
+	// GetTicketDependencies retrieves all dependencies between Trac tickets, passing each one to the provided "handler" function.
+	// There are no dependencies if the MasterTickets plugin has not been used.
+	GetTicketDependencies(handlerFn func(dependency *TicketDependency) error) error
+
 	/*
 	 * Ticket Attachments
 	 */
@@ -205,12 +305,20 @@ type Accessor interface {
 	// GetUserNames retrieves the names of all users mentioned in Trac tickets, wiki pages etc., passing each one to the provided "handler" function.
 	GetUserNames(handlerFn func(userName string) error) error
 
+	// GetUserProfiles retrieves the full name and email address recorded for each Trac user in their session preferences,
+	// passing each one to the provided "handler" function.
+	GetUserProfiles(handlerFn func(profile *UserProfile) error) error
+
 	/*
 	 * Versions
 	 */
 	// GetVersions retrieves all versions used in Trac, passing each one to the provided "handler" function.
 	GetVersions(handlerFn func(version *Label) error) error
 
+	// GetDefinedVersions retrieves all versions defined in Trac (as opposed to merely used by tickets) with their release times,
+	// passing each one to the provided "handler" function.
+	GetDefinedVersions(handlerFn func(version *Version) error) error
+
 	/*
 	 * Wiki
 	 */
@@ -0,0 +1,22 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import "regexp"
+
+// regexp for separators between entries in a Trac ticket Cc list - as with Trac itself, entries can be separated by commas, semicolons or spaces
+var ccSeparatorRegexp = regexp.MustCompile(`[;,\s]+`)
+
+// SplitCc splits the Cc field of a Trac ticket into individual user names or email addresses.
+func SplitCc(cc string) []string {
+	splitCc := []string{}
+	for _, ccEntry := range ccSeparatorRegexp.Split(cc, -1) {
+		if ccEntry != "" {
+			splitCc = append(splitCc, ccEntry)
+		}
+	}
+
+	return splitCc
+}
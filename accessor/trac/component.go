@@ -8,7 +8,7 @@ import "github.com/pkg/errors"
 
 // GetComponents retrieves all Trac components, passing each one to the provided "handler" function.
 func (accessor *DefaultAccessor) GetComponents(handlerFn func(component *Label) error) error {
-	rows, err := accessor.db.Query(`SELECT name, COALESCE(description,'') FROM component`)
+	rows, err := accessor.query(`SELECT name, COALESCE(description,'') FROM component`)
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving Trac components")
 		return err
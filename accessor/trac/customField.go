@@ -0,0 +1,128 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+const customFieldSectionName = "ticket-custom"
+
+// regexp for a valid custom field name - custom field names are used in SQL so we are strict about what we accept
+var customFieldNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// readCustomFields reads the definitions of all custom ticket fields from the [ticket-custom] section of the Trac config.
+func (accessor *DefaultAccessor) readCustomFields() []*CustomField {
+	customFields := []*CustomField{}
+	customFieldOrders := make(map[string]int)
+
+	section := accessor.config.Section(customFieldSectionName)
+	for _, key := range section.Keys() {
+		fieldName := key.Name()
+		if strings.Contains(fieldName, ".") {
+			continue // field attribute, not a field
+		}
+		if !customFieldNameRegexp.MatchString(fieldName) {
+			log.Warn("ignoring Trac custom field with unsupported name \"%s\"", fieldName)
+			continue
+		}
+
+		fieldLabel := section.Key(fieldName + ".label").String()
+		if fieldLabel == "" {
+			// default label as per Trac
+			fieldLabel = strings.ToUpper(fieldName[0:1]) + fieldName[1:]
+		}
+
+		var fieldOptions []string
+		if optionsValue := section.Key(fieldName + ".options").String(); optionsValue != "" {
+			fieldOptions = strings.Split(optionsValue, "|")
+			for optionIndex, option := range fieldOptions {
+				fieldOptions[optionIndex] = strings.TrimSpace(option)
+			}
+		}
+
+		customFieldOrders[fieldName] = section.Key(fieldName + ".order").MustInt(0)
+		customField := CustomField{Name: fieldName, Label: fieldLabel, Type: key.String(), Options: fieldOptions}
+		customFields = append(customFields, &customField)
+	}
+
+	sort.SliceStable(customFields, func(i, j int) bool {
+		iOrder := customFieldOrders[customFields[i].Name]
+		jOrder := customFieldOrders[customFields[j].Name]
+		if iOrder != jOrder {
+			return iOrder < jOrder
+		}
+		return customFields[i].Name < customFields[j].Name
+	})
+
+	return customFields
+}
+
+// getCustomField retrieves the definition of a named custom ticket field - returns nil if no such field.
+func (accessor *DefaultAccessor) getCustomField(fieldName string) *CustomField {
+	for _, customField := range accessor.customFields {
+		if customField.Name == fieldName {
+			return customField
+		}
+	}
+
+	return nil
+}
+
+// customFieldChangeTypes returns the names of all custom ticket fields as ticket change types, for use in retrieving the changes to those fields.
+func (accessor *DefaultAccessor) customFieldChangeTypes() []TicketChangeType {
+	changeTypes := []TicketChangeType{}
+	for _, customField := range accessor.customFields {
+		changeTypes = append(changeTypes, TicketChangeType(customField.Name))
+	}
+
+	return changeTypes
+}
+
+// GetCustomFields retrieves the definitions of all Trac custom ticket fields, passing each one to the provided "handler" function.
+func (accessor *DefaultAccessor) GetCustomFields(handlerFn func(field *CustomField) error) error {
+	for _, customField := range accessor.customFields {
+		if err := handlerFn(customField); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getTicketCustomFieldValues retrieves the values of the custom fields of all Trac tickets, indexed by ticket id then field name.
+func (accessor *DefaultAccessor) getTicketCustomFieldValues() (map[int64]map[string]string, error) {
+	customFieldValues := make(map[int64]map[string]string)
+	if len(accessor.customFields) == 0 {
+		return customFieldValues, nil
+	}
+
+	rows, err := accessor.query(`SELECT ticket, name, COALESCE(value, '') FROM ticket_custom`)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac ticket custom fields")
+		return nil, err
+	}
+
+	for rows.Next() {
+		var ticketID int64
+		var fieldName, value string
+		if err := rows.Scan(&ticketID, &fieldName, &value); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac ticket custom field")
+			return nil, err
+		}
+
+		if customFieldValues[ticketID] == nil {
+			customFieldValues[ticketID] = make(map[string]string)
+		}
+		customFieldValues[ticketID][fieldName] = value
+	}
+
+	return customFieldValues, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+
+	_ "github.com/lib/pq"           // postgres database driver
+	_ "github.com/mattn/go-sqlite3" // sqlite database driver
+)
+
+const (
+	sqliteDatabaseType   = "sqlite"
+	postgresDatabaseType = "postgres"
+	mysqlDatabaseType    = "mysql"
+)
+
+// quotePostgresValue quotes a value for inclusion in a postgres "key=value" connection string.
+func quotePostgresValue(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `'`, `\'`, -1)
+	return "'" + value + "'"
+}
+
+// postgresConnectionString converts a Trac postgres database URL into a connection string for the postgres driver.
+// The Trac URL is of the form: postgres://<user>:<password>@<host>:<port>/<database>?schema=<schema>
+// Any other URL parameters (e.g. 'sslmode' or 'host' for a Unix socket directory) are passed through to the driver.
+func postgresConnectionString(databaseURL *url.URL) string {
+	params := make(map[string]string)
+	params["sslmode"] = "disable"
+	if databaseURL.Hostname() != "" {
+		params["host"] = databaseURL.Hostname()
+	}
+	if databaseURL.Port() != "" {
+		params["port"] = databaseURL.Port()
+	}
+	if databaseURL.User != nil {
+		params["user"] = databaseURL.User.Username()
+		if password, hasPassword := databaseURL.User.Password(); hasPassword {
+			params["password"] = password
+		}
+	}
+	params["dbname"] = strings.TrimPrefix(databaseURL.Path, "/")
+
+	for key, values := range databaseURL.Query() {
+		if len(values) == 0 {
+			continue
+		}
+		if key == "schema" {
+			// Trac puts all of its tables in the named schema so make that the default schema for our connection
+			params["search_path"] = values[0]
+		} else {
+			params[key] = values[0]
+		}
+	}
+
+	// sort keys so that the connection string is deterministic
+	var keys []string
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var connectionParams []string
+	for _, key := range keys {
+		connectionParams = append(connectionParams, key+"="+quotePostgresValue(params[key]))
+	}
+	return strings.Join(connectionParams, " ")
+}
+
+// mysqlConnectionString converts a Trac mysql database URL into a connection string (DSN) for the mysql driver.
+// The Trac URL is of the form: mysql://<user>:<password>@<host>:<port>/<database>
+// A 'unix_socket' URL parameter is used in place of the host and port, other Trac-specific parameters are ignored.
+func mysqlConnectionString(databaseURL *url.URL) string {
+	config := mysql.NewConfig()
+	if databaseURL.User != nil {
+		config.User = databaseURL.User.Username()
+		config.Passwd, _ = databaseURL.User.Password()
+	}
+	config.DBName = strings.TrimPrefix(databaseURL.Path, "/")
+
+	if unixSocket := databaseURL.Query().Get("unix_socket"); unixSocket != "" {
+		config.Net = "unix"
+		config.Addr = unixSocket
+	} else {
+		port := databaseURL.Port()
+		if port == "" {
+			port = "3306"
+		}
+		config.Net = "tcp"
+		config.Addr = databaseURL.Hostname() + ":" + port
+	}
+
+	return config.FormatDSN()
+}
+
+// openDatabase opens the Trac database described by a Trac database connection string (the '[trac] database' entry of trac.ini),
+// returning the database and the SQL dialect to use with it.
+func openDatabase(tracRootDir string, tracDatabaseString string) (*sql.DB, dialect, error) {
+	tracDatabaseSegments := strings.SplitN(tracDatabaseString, ":", 2)
+	if len(tracDatabaseSegments) != 2 {
+		return nil, nil, fmt.Errorf("badly formatted Trac database string \"%s\": expecting '<type>:<details>'", tracDatabaseString)
+	}
+
+	tracDatabaseType := tracDatabaseSegments[0]
+	switch tracDatabaseType {
+	case sqliteDatabaseType:
+		tracDatabasePath := tracDatabaseSegments[1]
+		if !filepath.IsAbs(tracDatabasePath) {
+			tracDatabasePath = filepath.Join(tracRootDir, tracDatabasePath)
+		}
+
+		log.Info("using Trac sqlite database %s", tracDatabasePath)
+		tracDb, err := sql.Open("sqlite3", tracDatabasePath)
+		if err != nil {
+			err = errors.Wrapf(err, "opening Trac sqlite database %s", tracDatabasePath)
+			return nil, nil, err
+		}
+		return tracDb, sqliteDialect{}, nil
+
+	case postgresDatabaseType:
+		databaseURL, err := url.Parse(tracDatabaseString)
+		if err != nil {
+			err = errors.Wrapf(err, "parsing Trac postgres database string")
+			return nil, nil, err
+		}
+
+		// log database without password
+		log.Info("using Trac postgres database %s on host %s", strings.TrimPrefix(databaseURL.Path, "/"), databaseURL.Host)
+		tracDb, err := sql.Open("postgres", postgresConnectionString(databaseURL))
+		if err != nil {
+			err = errors.Wrapf(err, "opening Trac postgres database %s", databaseURL.Path)
+			return nil, nil, err
+		}
+		return tracDb, postgresDialect{}, nil
+
+	case mysqlDatabaseType:
+		databaseURL, err := url.Parse(tracDatabaseString)
+		if err != nil {
+			err = errors.Wrapf(err, "parsing Trac mysql database string")
+			return nil, nil, err
+		}
+
+		// log database without password
+		log.Info("using Trac mysql database %s on host %s", strings.TrimPrefix(databaseURL.Path, "/"), databaseURL.Host)
+		tracDb, err := sql.Open("mysql", mysqlConnectionString(databaseURL))
+		if err != nil {
+			err = errors.Wrapf(err, "opening Trac mysql database %s", databaseURL.Path)
+			return nil, nil, err
+		}
+		return tracDb, mysqlDialect{}, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported Trac database type \"%s\"", tracDatabaseType)
+}
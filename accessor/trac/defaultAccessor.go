@@ -8,21 +8,19 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/pkg/errors"
-	"github.com/stevejefferson/trac2gitea/log"
 
 	"github.com/go-ini/ini"
-	_ "github.com/mattn/go-sqlite3" // sqlite database driver
 )
 
 // DefaultAccessor is the default implementation of the trac Accessor interface, accessing Trac via its database and filestore.
 type DefaultAccessor struct {
-	rootDir string
-	db      *sql.DB
-	config  *ini.File
+	rootDir      string
+	db           *sql.DB
+	dialect      dialect
+	config       *ini.File
+	customFields []*CustomField
 }
 
 // CreateDefaultAccessor creates a new Trac accessor.
@@ -50,23 +48,15 @@ func CreateDefaultAccessor(tracRootDir string) (*DefaultAccessor, error) {
 	}
 
 	accessor := DefaultAccessor{db: nil, rootDir: tracRootDir, config: tracConfig}
+	accessor.customFields = accessor.readCustomFields()
 
-	// extract path to trac DB - currently sqlite-specific...
 	tracDatabaseString := accessor.GetStringConfig("trac", "database")
-	tracDatabaseSegments := strings.SplitN(tracDatabaseString, ":", 2)
-	tracDatabasePath := tracDatabaseSegments[1]
-	if !filepath.IsAbs(tracDatabasePath) {
-		tracDatabasePath = filepath.Join(tracRootDir, tracDatabasePath)
-	}
-
-	log.Info("using trac database %s", tracDatabasePath)
-
-	tracDb, err := sql.Open("sqlite3", tracDatabasePath)
+	tracDb, tracDialect, err := openDatabase(tracRootDir, tracDatabaseString)
 	if err != nil {
-		err = errors.Wrapf(err, "opening Trac sqlite database %s", tracDatabasePath)
 		return nil, err
 	}
 	accessor.db = tracDb
+	accessor.dialect = tracDialect
 
 	return &accessor, nil
 }
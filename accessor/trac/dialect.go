@@ -0,0 +1,104 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import (
+	"database/sql"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// dialect describes the differences between the SQL dialects of the database types supported by Trac.
+// All SQL in this package is written using numbered ('$<n>') placeholders which the dialect converts into the form expected by the database driver.
+type dialect interface {
+	// bindParams converts an SQL query using numbered placeholders, and its parameters, into the form expected by the database driver.
+	bindParams(query string, args []interface{}) (string, []interface{})
+
+	// timestampToSeconds returns an SQL expression converting a Trac timestamp expression (in microseconds) into seconds.
+	timestampToSeconds(expr string) string
+
+	// tableExistsQuery returns an SQL query counting the tables in the Trac database with the name given by its ('$1') parameter.
+	tableExistsQuery() string
+}
+
+// numberedDialect is the common part of the dialects for databases supporting numbered placeholders (sqlite and postgres).
+type numberedDialect struct{}
+
+func (numberedDialect) bindParams(query string, args []interface{}) (string, []interface{}) {
+	return query, args
+}
+
+// timestampToSeconds uses integer division so that timestamps are truncated (rather than rounded) to the second on both sqlite and postgres.
+func (numberedDialect) timestampToSeconds(expr string) string {
+	return "(" + expr + " / 1000000)"
+}
+
+// sqliteDialect is the dialect for sqlite databases.
+type sqliteDialect struct{ numberedDialect }
+
+func (sqliteDialect) tableExistsQuery() string {
+	return `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = $1`
+}
+
+// postgresDialect is the dialect for postgres databases - Trac's tables are in the first schema of the search path.
+type postgresDialect struct{ numberedDialect }
+
+func (postgresDialect) tableExistsQuery() string {
+	return `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1`
+}
+
+// mysqlDialect is the dialect for mysql/mariadb databases.
+type mysqlDialect struct{}
+
+var numberedPlaceholderRegexp = regexp.MustCompile(`\$([[:digit:]]+)`)
+
+// bindParams converts numbered placeholders into positional '?' placeholders.
+// Because a numbered placeholder can be used more than once in a query, the parameters are re-ordered (and duplicated) to match.
+func (mysqlDialect) bindParams(query string, args []interface{}) (string, []interface{}) {
+	var boundArgs []interface{}
+	boundQuery := numberedPlaceholderRegexp.ReplaceAllStringFunc(query, func(placeholder string) string {
+		argNum, _ := strconv.Atoi(placeholder[1:])
+		if argNum >= 1 && argNum <= len(args) {
+			boundArgs = append(boundArgs, args[argNum-1])
+		}
+		return "?"
+	})
+
+	return boundQuery, boundArgs
+}
+
+func (mysqlDialect) timestampToSeconds(expr string) string {
+	return "(" + expr + " DIV 1000000)"
+}
+
+func (mysqlDialect) tableExistsQuery() string {
+	return `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = $1`
+}
+
+// query performs an SQL query against the Trac database, converting it into the dialect of that database.
+func (accessor *DefaultAccessor) query(query string, args ...interface{}) (*sql.Rows, error) {
+	dialectQuery, dialectArgs := accessor.dialect.bindParams(query, args)
+	return accessor.db.Query(dialectQuery, dialectArgs...)
+}
+
+// hasTable determines whether the Trac database contains a table of the given name.
+func (accessor *DefaultAccessor) hasTable(tableName string) (bool, error) {
+	var count int64
+	err := accessor.queryRow(accessor.dialect.tableExistsQuery(), tableName).Scan(&count)
+	if err != nil {
+		err = errors.Wrapf(err, "looking up Trac database table %s", tableName)
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// queryRow performs an SQL query expected to return at most one row against the Trac database, converting it into the dialect of that database.
+func (accessor *DefaultAccessor) queryRow(query string, args ...interface{}) *sql.Row {
+	dialectQuery, dialectArgs := accessor.dialect.bindParams(query, args)
+	return accessor.db.QueryRow(dialectQuery, dialectArgs...)
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestBindParams(t *testing.T) {
+	tests := []struct {
+		name          string
+		dialect       dialect
+		query         string
+		args          []interface{}
+		expectedQuery string
+		expectedArgs  []interface{}
+	}{
+		{
+			name:          "numbered placeholders unchanged",
+			dialect:       sqliteDialect{},
+			query:         "SELECT a FROM t WHERE b = $1 AND c = $2",
+			args:          []interface{}{1, "x"},
+			expectedQuery: "SELECT a FROM t WHERE b = $1 AND c = $2",
+			expectedArgs:  []interface{}{1, "x"},
+		},
+		{
+			name:          "mysql positional placeholders",
+			dialect:       mysqlDialect{},
+			query:         "SELECT a FROM t WHERE b = $1 AND c = $2",
+			args:          []interface{}{1, "x"},
+			expectedQuery: "SELECT a FROM t WHERE b = ? AND c = ?",
+			expectedArgs:  []interface{}{1, "x"},
+		},
+		{
+			name:          "mysql out-of-order placeholders",
+			dialect:       mysqlDialect{},
+			query:         "SELECT a FROM t WHERE b = $2 AND c = $1",
+			args:          []interface{}{1, "x"},
+			expectedQuery: "SELECT a FROM t WHERE b = ? AND c = ?",
+			expectedArgs:  []interface{}{"x", 1},
+		},
+		{
+			name:          "mysql placeholder used twice",
+			dialect:       mysqlDialect{},
+			query:         "SELECT a FROM t WHERE b = $1 AND (c = $2 OR d = $2)",
+			args:          []interface{}{1, "x"},
+			expectedQuery: "SELECT a FROM t WHERE b = ? AND (c = ? OR d = ?)",
+			expectedArgs:  []interface{}{1, "x", "x"},
+		},
+	}
+
+	for _, test := range tests {
+		query, args := test.dialect.bindParams(test.query, test.args)
+		if query != test.expectedQuery {
+			t.Errorf("%s: expecting query \"%s\", got \"%s\"", test.name, test.expectedQuery, query)
+		}
+		if !reflect.DeepEqual(args, test.expectedArgs) {
+			t.Errorf("%s: expecting args %v, got %v", test.name, test.expectedArgs, args)
+		}
+	}
+}
+
+func TestMysqlTimestampToSeconds(t *testing.T) {
+	expr := mysqlDialect{}.timestampToSeconds("time")
+	if expr != "(time DIV 1000000)" {
+		t.Errorf("expecting integer division of timestamp, got \"%s\"", expr)
+	}
+}
+
+func TestSqliteHasMasterTicketsTable(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessor := DefaultAccessor{db: db, dialect: sqliteDialect{}}
+
+	hasTable, err := accessor.hasMasterTicketsTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasTable {
+		t.Errorf("expecting no mastertickets table before the MasterTickets plugin is installed")
+	}
+
+	if _, err = db.Exec("CREATE TABLE mastertickets (source INTEGER, dest INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	hasTable, err = accessor.hasMasterTicketsTable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasTable {
+		t.Errorf("expecting mastertickets table once the MasterTickets plugin is installed")
+	}
+
+	// failures to access the database are not mistaken for a missing table
+	db.Close()
+	if _, err = accessor.hasMasterTicketsTable(); err == nil {
+		t.Errorf("expecting error looking up mastertickets table on a closed database")
+	}
+	if err = accessor.GetTicketDependencies(func(dependency *TicketDependency) error { return nil }); err == nil {
+		t.Errorf("expecting error retrieving ticket dependencies from a closed database")
+	}
+}
+
+func TestSqliteGetTicketDependencyTimes(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	accessor := DefaultAccessor{db: db, dialect: sqliteDialect{}}
+
+	for _, statement := range []string{
+		"CREATE TABLE ticket (id INTEGER, time INTEGER)",
+		"CREATE TABLE ticket_change (ticket INTEGER, time INTEGER, author TEXT, field TEXT, oldvalue TEXT, newvalue TEXT)",
+		"CREATE TABLE mastertickets (source INTEGER, dest INTEGER)",
+		"INSERT INTO ticket VALUES (1, 100000000), (2, 200000000), (3, 300000000)",
+		"INSERT INTO mastertickets VALUES (2, 1), (3, 1)",
+		// dependency of ticket 1 on ticket 2 is added, removed then added again - the change to ticket 2 records the same dependency
+		"INSERT INTO ticket_change VALUES (1, 500000000, 'alice', 'blockedby', '', '2')",
+		"INSERT INTO ticket_change VALUES (1, 600000000, 'alice', 'blockedby', '2', '')",
+		"INSERT INTO ticket_change VALUES (2, 650000000, 'alice', 'blocking', '', '1')",
+		"INSERT INTO ticket_change VALUES (1, 700000000, 'alice', 'blockedby', '', '2')",
+	} {
+		if _, err = db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dependencies := []TicketDependency{}
+	err = accessor.GetTicketDependencies(func(dependency *TicketDependency) error {
+		dependencies = append(dependencies, *dependency)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// dependency with no recorded history is timed at the creation of the later ticket
+	expectedDependencies := []TicketDependency{
+		{TicketID: 1, BlockingTicketID: 2, Time: 700},
+		{TicketID: 1, BlockingTicketID: 3, Time: 300},
+	}
+	if !reflect.DeepEqual(dependencies, expectedDependencies) {
+		t.Errorf("expecting dependencies %v, got %v", expectedDependencies, dependencies)
+	}
+}
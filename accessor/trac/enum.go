@@ -0,0 +1,60 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// getEnumLabels retrieves the items of an ordered Trac enumeration (e.g. priorities) together with any other values of the corresponding ticket column,
+// passing each one to the provided "handler" function.
+// Items of the enumeration are retrieved in order, followed by any other values in alphabetical order.
+func (accessor *DefaultAccessor) getEnumLabels(enumType string, ticketColumn string, handlerFn func(label *Label) error) error {
+	rows, err := accessor.query(`
+		SELECT COALESCE(name, ''), COALESCE(value, '') FROM enum WHERE type = $1
+		UNION
+		SELECT DISTINCT COALESCE(`+ticketColumn+`, ''), '' FROM ticket
+			WHERE COALESCE(`+ticketColumn+`, '') NOT IN (SELECT name FROM enum WHERE type = $1)`, enumType)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac %s values", enumType)
+		return err
+	}
+
+	labels := []*Label{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac %s value", enumType)
+			return err
+		}
+
+		position, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || position < 0 {
+			position = 0
+		}
+		labels = append(labels, &Label{Name: name, Description: "", Position: position})
+	}
+
+	sort.SliceStable(labels, func(i, j int) bool {
+		if labels[i].Position != labels[j].Position {
+			if labels[i].Position == 0 || labels[j].Position == 0 {
+				return labels[j].Position == 0
+			}
+			return labels[i].Position < labels[j].Position
+		}
+		return labels[i].Name < labels[j].Name
+	})
+
+	for _, label := range labels {
+		if err = handlerFn(label); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// regexp for separators between Trac ticket keywords - as with Trac itself, keywords can be separated by spaces or commas
+var keywordSeparatorRegexp = regexp.MustCompile(`[\s,]+`)
+
+// SplitKeywords splits the keywords field of a Trac ticket into individual keywords.
+func SplitKeywords(keywords string) []string {
+	splitKeywords := []string{}
+	for _, keyword := range keywordSeparatorRegexp.Split(keywords, -1) {
+		if keyword != "" {
+			splitKeywords = append(splitKeywords, keyword)
+		}
+	}
+
+	return splitKeywords
+}
+
+// GetKeywords retrieves all keywords used in Trac tickets, passing each one to the provided "handler" function.
+// This includes any keywords which have since been removed from their tickets.
+func (accessor *DefaultAccessor) GetKeywords(handlerFn func(keyword *Label) error) error {
+	rows, err := accessor.query(`
+		SELECT DISTINCT COALESCE(keywords, '') FROM ticket
+		UNION
+		SELECT DISTINCT COALESCE(oldvalue, '') FROM ticket_change WHERE field = '` + string(TicketKeywordsChange) + `'
+		UNION
+		SELECT DISTINCT COALESCE(newvalue, '') FROM ticket_change WHERE field = '` + string(TicketKeywordsChange) + `'`)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac keywords")
+		return err
+	}
+
+	keywordSet := make(map[string]bool)
+	for rows.Next() {
+		var keywords string
+		if err := rows.Scan(&keywords); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac keywords")
+			return err
+		}
+
+		for _, keyword := range SplitKeywords(keywords) {
+			keywordSet[keyword] = true
+		}
+	}
+
+	keywordNames := []string{}
+	for keyword := range keywordSet {
+		keywordNames = append(keywordNames, keyword)
+	}
+	sort.Strings(keywordNames)
+
+	for _, keywordName := range keywordNames {
+		keyword := Label{Name: keywordName, Description: ""}
+		if err = handlerFn(&keyword); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
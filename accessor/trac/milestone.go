@@ -9,8 +9,8 @@ import "github.com/pkg/errors"
 // GetMilestones retrieves all Trac milestones, passing data from each one to the provided "handler" function.
 func (accessor *DefaultAccessor) GetMilestones(handlerFn func(milestone *Milestone) error) error {
 	// NOTE: trac timestamps are to the microseconds, we just need seconds
-	rows, err := accessor.db.Query(`
-		SELECT COALESCE(name,''), COALESCE(description,''), CAST(due*1e-6 AS int8), CAST(completed*1e-6 AS int8)
+	rows, err := accessor.query(`
+		SELECT COALESCE(name,''), COALESCE(description,''), ` + accessor.dialect.timestampToSeconds("due") + `, ` + accessor.dialect.timestampToSeconds("completed") + `
 			FROM milestone UNION
 			SELECT distinct(COALESCE(milestone,'')),'',0,0
 				FROM ticket
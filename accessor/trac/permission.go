@@ -0,0 +1,37 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import "github.com/pkg/errors"
+
+// Trac's built-in permission subjects: these cover all users and all logged-in users respectively.
+const (
+	AnonymousSubject     = "anonymous"
+	AuthenticatedSubject = "authenticated"
+)
+
+// GetPermissions retrieves all Trac permission grants, passing the subject and action of each one to the provided "handler" function.
+// The subject is a user or group name; the action is either a permission name or, where the subject is a member of a group, the group name.
+func (accessor *DefaultAccessor) GetPermissions(handlerFn func(subject string, action string) error) error {
+	rows, err := accessor.query(`SELECT username, action FROM permission ORDER BY username, action`)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac permissions")
+		return err
+	}
+
+	for rows.Next() {
+		var subject, action string
+		if err := rows.Scan(&subject, &action); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac permission")
+			return err
+		}
+
+		if err = handlerFn(subject, action); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -4,29 +4,8 @@
 
 package trac
 
-import "github.com/pkg/errors"
-
 // GetPriorities retrieves all priorities used in Trac tickets, passing each one to the provided "handler" function.
+// Priorities are retrieved in Trac's order of priority (highest first), followed by any priorities used in tickets but no longer defined by Trac.
 func (accessor *DefaultAccessor) GetPriorities(handlerFn func(priority *Label) error) error {
-	rows, err := accessor.db.Query(`SELECT DISTINCT priority FROM ticket`)
-	if err != nil {
-		err = errors.Wrapf(err, "retrieving Trac priorities")
-		return err
-	}
-
-	for rows.Next() {
-		var priorityName string
-		if err := rows.Scan(&priorityName); err != nil {
-			err = errors.Wrapf(err, "retrieving Trac priority")
-			return err
-		}
-
-		priority := Label{Name: priorityName, Description: ""}
-
-		if err = handlerFn(&priority); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return accessor.getEnumLabels("priority", "priority", handlerFn)
 }
@@ -8,7 +8,7 @@ import "github.com/pkg/errors"
 
 // GetResolutions retrieves all resolutions used in Trac tickets, passing each one to the provided "handler" function.
 func (accessor *DefaultAccessor) GetResolutions(handlerFn func(resolution *Label) error) error {
-	rows, err := accessor.db.Query(`SELECT DISTINCT resolution FROM ticket WHERE trim(resolution) != ''`)
+	rows, err := accessor.query(`SELECT DISTINCT resolution FROM ticket WHERE trim(resolution) != ''`)
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving Trac resolutions")
 		return err
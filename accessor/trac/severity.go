@@ -4,28 +4,8 @@
 
 package trac
 
-import "github.com/pkg/errors"
-
 // GetSeverities retrieves all severities used in Trac tickets, passing each one to the provided "handler" function.
+// Severities are retrieved in Trac's order of severity (highest first), followed by any severities used in tickets but no longer defined by Trac.
 func (accessor *DefaultAccessor) GetSeverities(handlerFn func(severity *Label) error) error {
-	rows, err := accessor.db.Query(`SELECT DISTINCT COALESCE(severity,'') FROM ticket`)
-	if err != nil {
-		err = errors.Wrapf(err, "retrieving Trac severities")
-		return err
-	}
-
-	for rows.Next() {
-		var severityName string
-		if err := rows.Scan(&severityName); err != nil {
-			err = errors.Wrapf(err, "retrieving Trac severity")
-			return err
-		}
-
-		severity := Label{Name: severityName, Description: ""}
-		if err = handlerFn(&severity); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return accessor.getEnumLabels("severity", "severity", handlerFn)
 }
@@ -0,0 +1,39 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import "github.com/pkg/errors"
+
+// GetStatuses retrieves all statuses which Trac tickets have or have had, passing each one to the provided "handler" function.
+func (accessor *DefaultAccessor) GetStatuses(handlerFn func(status *Label) error) error {
+	rows, err := accessor.query(`
+		SELECT DISTINCT COALESCE(status, '') FROM ticket
+		UNION
+		SELECT DISTINCT COALESCE(oldvalue, '') FROM ticket_change WHERE field = '` + string(TicketStatusChange) + `'
+		UNION
+		SELECT DISTINCT COALESCE(newvalue, '') FROM ticket_change WHERE field = '` + string(TicketStatusChange) + `'`)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac statuses")
+		return err
+	}
+
+	for rows.Next() {
+		var statusName string
+		if err := rows.Scan(&statusName); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac status")
+			return err
+		}
+
+		if statusName == "" {
+			continue
+		}
+		status := Label{Name: statusName, Description: ""}
+		if err = handlerFn(&status); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
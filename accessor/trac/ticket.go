@@ -8,23 +8,33 @@ import "github.com/pkg/errors"
 
 // GetTickets retrieves all Trac tickets, passing data from each one to the provided "handler" function.
 func (accessor *DefaultAccessor) GetTickets(handlerFn func(ticket *Ticket) error) error {
-	rows, err := accessor.db.Query(`
+	customFieldValues, err := accessor.getTicketCustomFieldValues()
+	if err != nil {
+		return err
+	}
+
+	rows, err := accessor.query(`
 		SELECT
 			t.id,
 			t.type,
-			CAST(t.time*1e-6 AS int8),
-			CAST(t.changetime*1e-6 AS int8),
+			` + accessor.dialect.timestampToSeconds("t.time") + `,
+			` + accessor.dialect.timestampToSeconds("t.changetime") + `,
 			COALESCE(t.component, ''),
 			COALESCE(t.severity,''),
 			COALESCE(t.priority,''),
 			COALESCE(t.owner,''),
 			t.reporter,
 			COALESCE(t.version,''),
+			COALESCE(t.keywords,''),
+			COALESCE(t.cc,''),
 			COALESCE(t.milestone,''),
 			lower(COALESCE(t.status, '')),
 			COALESCE(t.resolution,''),
 			COALESCE(t.summary, ''),
-			COALESCE(t.description, '')
+			COALESCE(t.description, ''),
+			COALESCE((SELECT tc.oldvalue FROM ticket_change tc
+				WHERE tc.ticket = t.id AND tc.field = '` + string(TicketDescriptionChange) + `'
+				ORDER BY tc.time LIMIT 1), t.description, '')
 		FROM ticket t ORDER BY id`)
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving Trac tickets")
@@ -33,16 +43,23 @@ func (accessor *DefaultAccessor) GetTickets(handlerFn func(ticket *Ticket) error
 
 	for rows.Next() {
 		var ticketID, created, updated int64
-		var summary, description, owner, reporter, milestoneName, componentName, priorityName, resolutionName, severityName, typeName, versionName, status string
+		var summary, description, originalDescription, owner, reporter, milestoneName, componentName, priorityName, resolutionName, severityName, typeName, versionName, keywords, cc, status string
 		if err := rows.Scan(&ticketID, &typeName, &created, &updated, &componentName, &severityName, &priorityName, &owner, &reporter,
-			&versionName, &milestoneName, &status, &resolutionName, &summary, &description); err != nil {
+			&versionName, &keywords, &cc, &milestoneName, &status, &resolutionName, &summary, &description, &originalDescription); err != nil {
 			err = errors.Wrapf(err, "retrieving Trac ticket")
 			return err
 		}
 
-		ticket := Ticket{TicketID: ticketID, Summary: summary, Description: description, Owner: owner, Reporter: reporter,
+		ticket := Ticket{TicketID: ticketID, Summary: summary, Description: description, OriginalDescription: originalDescription, Owner: owner, Reporter: reporter,
 			MilestoneName: milestoneName, ComponentName: componentName, PriorityName: priorityName, ResolutionName: resolutionName,
-			SeverityName: severityName, TypeName: typeName, VersionName: versionName, Status: status, Created: created, Updated: updated}
+			SeverityName: severityName, TypeName: typeName, VersionName: versionName, Keywords: keywords, Cc: cc, Status: status, Created: created, Updated: updated,
+			CustomFields: []TicketCustomField{}}
+		for _, customField := range accessor.customFields {
+			value := customFieldValues[ticketID][customField.Name]
+			if value != "" {
+				ticket.CustomFields = append(ticket.CustomFields, TicketCustomField{Field: customField, Value: value})
+			}
+		}
 
 		if err = handlerFn(&ticket); err != nil {
 			return err
@@ -38,8 +38,8 @@ func (accessor *DefaultAccessor) GetTicketAttachmentPath(attachment *TicketAttac
 
 // GetTicketAttachments retrieves all attachments for a given Trac ticket, passing data from each one to the provided "handler" function.
 func (accessor *DefaultAccessor) GetTicketAttachments(ticketID int64, handlerFn func(attachment *TicketAttachment) error) error {
-	rows, err := accessor.db.Query(`
-		SELECT CAST(time*1e-6 AS int8) tim, COALESCE(author, '') author, filename, description, size
+	rows, err := accessor.query(`
+		SELECT `+accessor.dialect.timestampToSeconds("time")+` AS tim, COALESCE(author, '') AS author, filename, description, size
 			FROM attachment
 			WHERE type = 'ticket' AND id = $1
 			ORDER BY time asc`, ticketID)
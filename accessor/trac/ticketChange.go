@@ -7,6 +7,7 @@ package trac
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 
 	"github.com/pkg/errors"
 )
@@ -25,55 +26,98 @@ func sqlForFieldList(fields []TicketChangeType) string {
 }
 
 // sqlForFirstChange returns the SQL for retrieving details of the first change to a ticket
-func sqlForFirstChange() string {
-	// note: this query requires additional protection against NULL for case where no change exists for ticket
+func (accessor *DefaultAccessor) sqlForFirstChange() string {
+	// note: the earliest change is identified by a sub-query rather than by selecting non-aggregated columns alongside MIN(),
+	// the latter only works under sqlite - other databases reject it
 	changeSQL := `
-		SELECT 0 source,
-			COALESCE(chg0.field, '') field,
-			COALESCE(chg0.oldvalue, '') value,
-			COALESCE(chg0.author, '') author,
-			COALESCE(MIN(CAST(chg0.time*1e-6 AS int8)),0) time
+		SELECT 0 AS source,
+			COALESCE(chg0.field, '') AS field,
+			COALESCE(chg0.oldvalue, '') AS value,
+			COALESCE(chg0.author, '') AS author,
+			` + accessor.dialect.timestampToSeconds("chg0.time") + ` AS time
 		FROM ticket_change chg0
 		WHERE chg0.ticket = $1
+		AND chg0.time = (SELECT MIN(chg0a.time) FROM ticket_change chg0a WHERE chg0a.ticket = chg0.ticket)
 		`
 	return changeSQL
 }
 
 // sqlForFirstChangeToEachField returns the SQL for retrieving details of the first change to each of a set of fields of a ticket
-func sqlForFirstChangeToEachField(fields []TicketChangeType) string {
+func (accessor *DefaultAccessor) sqlForFirstChangeToEachField(fields []TicketChangeType) string {
 	changeSQL := `
-		SELECT 1 source,
-			chg1.field field,
-			COALESCE(chg1.oldvalue, '') value,
-			COALESCE(chg1.author, '') author,
-			MIN(CAST(chg1.time*1e-6 AS int8)) time
+		SELECT 1 AS source,
+			chg1.field AS field,
+			COALESCE(chg1.oldvalue, '') AS value,
+			COALESCE(chg1.author, '') AS author,
+			` + accessor.dialect.timestampToSeconds("chg1.time") + ` AS time
 		FROM ticket_change chg1
 		WHERE chg1.ticket = $1
 		AND chg1.field IN ` + sqlForFieldList(fields) + `
-		GROUP BY chg1.field
+		AND chg1.time = (
+			SELECT MIN(chg1a.time) FROM ticket_change chg1a
+			WHERE chg1a.ticket = chg1.ticket
+			AND chg1a.field = chg1.field)
 		`
 
 	return changeSQL
 }
 
 // sqlForTicketTableField returns the SQL for retrieving each of set of fields of a ticket in the same format as the the other queries above
-func sqlForTicketTableField(fieldIndex int, field TicketChangeType) string {
+func (accessor *DefaultAccessor) sqlForTicketTableField(fieldIndex int, field TicketChangeType) string {
 	table := fmt.Sprintf("t%d", fieldIndex)
 	strField := string(field)
 	return `
-		SELECT 2 source,
-			'` + strField + `' field,
-			COALESCE(` + table + `.` + strField + `, '') value,
-			COALESCE(` + table + `.owner, '') author,
-			CAST(` + table + `.time*1e-6 AS int8) time
+		SELECT 2 AS source,
+			'` + strField + `' AS field,
+			COALESCE(` + table + `.` + strField + `, '') AS value,
+			COALESCE(` + table + `.owner, '') AS author,
+			` + accessor.dialect.timestampToSeconds(table+".time") + ` AS time
 		FROM ticket ` + table + `
 		WHERE id=$1
 		`
 }
 
+// sqlForTicketCustomField returns the SQL for retrieving a custom field of a ticket in the same format as the the other queries above
+func (accessor *DefaultAccessor) sqlForTicketCustomField(fieldIndex int, field TicketChangeType) string {
+	table := fmt.Sprintf("tc%d", fieldIndex)
+	customTable := fmt.Sprintf("c%d", fieldIndex)
+	strField := string(field)
+	return `
+		SELECT 2 AS source,
+			'` + strField + `' AS field,
+			COALESCE(` + customTable + `.value, '') AS value,
+			COALESCE(` + table + `.owner, '') AS author,
+			` + accessor.dialect.timestampToSeconds(table+".time") + ` AS time
+		FROM ticket ` + table + `
+		LEFT JOIN ticket_custom ` + customTable + ` ON ` + customTable + `.ticket = ` + table + `.id AND ` + customTable + `.name = '` + strField + `'
+		WHERE ` + table + `.id=$1
+		`
+}
+
+// createTicketChange creates a change to a given field of a Trac ticket.
+func (accessor *DefaultAccessor) createTicketChange(ticketID int64, field string, author string, oldValue string, newValue string, time int64) *TicketChange {
+	change := TicketChange{
+		TicketID:     ticketID,
+		ChangeType:   TicketChangeType(field),
+		CustomField:  nil,
+		Author:       author,
+		OldValue:     oldValue,
+		NewValue:     newValue,
+		Time:         time,
+		CommentEdits: nil,
+	}
+
+	if customField := accessor.getCustomField(field); customField != nil {
+		change.ChangeType = TicketCustomFieldChange
+		change.CustomField = customField
+	}
+
+	return &change
+}
+
 var initialTicketChangeFields = []TicketChangeType{
-	TicketComponentChange, TicketMilestoneChange, TicketOwnerChange, TicketPriorityChange,
-	TicketResolutionChange, TicketSeverityChange, TicketTypeChange, TicketVersionChange,
+	TicketCcChange, TicketComponentChange, TicketKeywordsChange, TicketMilestoneChange, TicketOwnerChange, TicketPriorityChange,
+	TicketResolutionChange, TicketSeverityChange, TicketStatusChange, TicketTypeChange, TicketVersionChange,
 }
 
 // getInitialTicketChanges generates a set of "synthetic" changes on a Trac ticket to model the assignments of its initial values
@@ -98,13 +142,18 @@ func (accessor *DefaultAccessor) getInitialTicketChanges(ticketID int64, handler
 	initialValueSQL := `
 		SELECT source, field, value, author, time FROM 
 		(`
-	initialValueSQL = initialValueSQL + sqlForFirstChange() + `UNION` + sqlForFirstChangeToEachField(initialTicketChangeFields)
+	customFields := accessor.customFieldChangeTypes()
+	initialChangeFields := append(append([]TicketChangeType{}, initialTicketChangeFields...), customFields...)
+	initialValueSQL = initialValueSQL + accessor.sqlForFirstChange() + `UNION` + accessor.sqlForFirstChangeToEachField(initialChangeFields)
 	for fieldIndex, field := range initialTicketChangeFields {
-		initialValueSQL = initialValueSQL + `UNION` + sqlForTicketTableField(fieldIndex, field)
+		initialValueSQL = initialValueSQL + `UNION` + accessor.sqlForTicketTableField(fieldIndex, field)
 	}
-	initialValueSQL = initialValueSQL + `) ORDER BY source asc`
+	for fieldIndex, field := range customFields {
+		initialValueSQL = initialValueSQL + `UNION` + accessor.sqlForTicketCustomField(fieldIndex, field)
+	}
+	initialValueSQL = initialValueSQL + `) AS initial_values ORDER BY source asc`
 
-	rows, err := accessor.db.Query(initialValueSQL, ticketID)
+	rows, err := accessor.query(initialValueSQL, ticketID)
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving initial changes for ticket %d", ticketID)
 		return err
@@ -156,15 +205,9 @@ func (accessor *DefaultAccessor) getInitialTicketChanges(ticketID int64, handler
 		}
 
 		// record a change from "" to the initial value
-		change := TicketChange{
-			TicketID:   ticketID,
-			ChangeType: TicketChangeType(field),
-			Author:     author,
-			OldValue:   "",
-			NewValue:   value,
-			Time:       time,
-		}
-		if err = handlerFn(&change); err != nil {
+		change := accessor.createTicketChange(ticketID, field, author, "", value, time)
+		change.Initial = true
+		if err = handlerFn(change); err != nil {
 			return err
 		}
 	}
@@ -173,19 +216,26 @@ func (accessor *DefaultAccessor) getInitialTicketChanges(ticketID int64, handler
 }
 
 var recordedTicketChangeFields = []TicketChangeType{
-	TicketComponentChange, TicketMilestoneChange, TicketOwnerChange, TicketPriorityChange, TicketResolutionChange,
-	TicketSeverityChange, TicketStatusChange, TicketSummaryChange, TicketTypeChange, TicketVersionChange,
+	TicketCcChange, TicketComponentChange, TicketDescriptionChange, TicketKeywordsChange, TicketMilestoneChange, TicketOwnerChange,
+	TicketPriorityChange, TicketResolutionChange, TicketSeverityChange, TicketStatusChange, TicketSummaryChange, TicketTypeChange,
+	TicketVersionChange,
 }
 
 // getRecordedTicketChanges retrieves all changes on a given ticket recorded by Trac in ascending time order, passing data from each to a "handler" function.
 func (accessor *DefaultAccessor) getRecordedTicketChanges(ticketID int64, handlerFn func(change *TicketChange) error) error {
-	rows, err := accessor.db.Query(`
-		SELECT field, COALESCE(author, ''), COALESCE(oldvalue, ''), COALESCE(newvalue, ''), CAST(time*1e-6 AS int8)
+	commentEdits, err := accessor.getTicketCommentEdits(ticketID)
+	if err != nil {
+		return err
+	}
+
+	recordedChangeFields := append(append([]TicketChangeType{}, recordedTicketChangeFields...), accessor.customFieldChangeTypes()...)
+	rows, err := accessor.query(`
+		SELECT field, COALESCE(author, ''), COALESCE(oldvalue, ''), COALESCE(newvalue, ''), `+accessor.dialect.timestampToSeconds("time")+`, time
 			FROM ticket_change
 			WHERE ticket = $1
 			AND (
-				(field = '`+string(TicketCommentChange)+`' AND trim(COALESCE(newvalue, ''), ' ') != '')
-				OR field IN `+sqlForFieldList(recordedTicketChangeFields)+`
+				(field = '`+string(TicketCommentChange)+`' AND TRIM(COALESCE(newvalue, '')) != '')
+				OR field IN `+sqlForFieldList(recordedChangeFields)+`
 			)
 			ORDER BY time asc`,
 		ticketID)
@@ -195,22 +245,18 @@ func (accessor *DefaultAccessor) getRecordedTicketChanges(ticketID int64, handle
 	}
 
 	for rows.Next() {
-		var time int64
+		var time, tracTime int64
 		var field, author, oldValue, newValue string
-		if err := rows.Scan(&field, &author, &oldValue, &newValue, &time); err != nil {
+		if err := rows.Scan(&field, &author, &oldValue, &newValue, &time, &tracTime); err != nil {
 			err = errors.Wrapf(err, "retrieving Trac change for ticket %d", ticketID)
 			return err
 		}
 
-		change := TicketChange{
-			TicketID:   ticketID,
-			ChangeType: TicketChangeType(field),
-			Author:     author,
-			OldValue:   oldValue,
-			NewValue:   newValue,
-			Time:       time}
-
-		if err = handlerFn(&change); err != nil {
+		change := accessor.createTicketChange(ticketID, field, author, oldValue, newValue, time)
+		if change.ChangeType == TicketCommentChange {
+			change.CommentEdits = commentEdits[tracTime]
+		}
+		if err = handlerFn(change); err != nil {
 			return err
 		}
 	}
@@ -227,12 +273,26 @@ func (accessor *DefaultAccessor) GetTicketChanges(ticketID int64, handlerFn func
 	return accessor.getRecordedTicketChanges(ticketID, handlerFn)
 }
 
+// sqlForTicketComment returns the SQL condition identifying a given numbered comment of a given ticket.
+// The number of a comment made in reply to another comment is recorded as '<parent-number>.<number>' so the condition takes 3 parameters:
+// the ticket id, the comment number and the comment number as it appears in a reply (see commentNumParams).
+func sqlForTicketComment() string {
+	return `ticket = $1 AND field = '` + string(TicketCommentChange) + `' AND (oldvalue = $2 OR oldvalue LIKE $3)`
+}
+
+// commentNumParams returns the comment number parameters for the SQL produced by sqlForTicketComment
+func commentNumParams(commentNum int64) (string, string) {
+	commentNumStr := strconv.FormatInt(commentNum, 10)
+	return commentNumStr, "%." + commentNumStr
+}
+
 // GetTicketCommentTime retrieves the timestamp for a given comment for a given Trac ticket
 func (accessor *DefaultAccessor) GetTicketCommentTime(ticketID int64, commentNum int64) (int64, error) {
 	timestamp := int64(0)
-	err := accessor.db.QueryRow(`
-		SELECT time FROM ticket_change where ticket = $1 AND oldvalue = $2 AND field = 'comment'`,
-		ticketID, commentNum).Scan(&timestamp)
+	commentNumStr, replyCommentNumStr := commentNumParams(commentNum)
+	err := accessor.queryRow(`
+		SELECT `+accessor.dialect.timestampToSeconds("time")+` FROM ticket_change WHERE `+sqlForTicketComment(),
+		ticketID, commentNumStr, replyCommentNumStr).Scan(&timestamp)
 	if err != nil && err != sql.ErrNoRows {
 		err = errors.Wrapf(err, "retrieving Trac comment number %d for ticket %d", commentNum, ticketID)
 		return 0, err
@@ -240,3 +300,23 @@ func (accessor *DefaultAccessor) GetTicketCommentTime(ticketID int64, commentNum
 
 	return timestamp, nil
 }
+
+// GetTicketComment retrieves a given comment for a given Trac ticket, returns nil if no such comment
+func (accessor *DefaultAccessor) GetTicketComment(ticketID int64, commentNum int64) (*TicketChange, error) {
+	var time int64
+	var author, oldValue, newValue string
+	commentNumStr, replyCommentNumStr := commentNumParams(commentNum)
+	err := accessor.queryRow(`
+		SELECT COALESCE(author, ''), COALESCE(oldvalue, ''), COALESCE(newvalue, ''), `+accessor.dialect.timestampToSeconds("time")+`
+			FROM ticket_change WHERE `+sqlForTicketComment(),
+		ticketID, commentNumStr, replyCommentNumStr).Scan(&author, &oldValue, &newValue, &time)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac comment number %d for ticket %d", commentNum, ticketID)
+		return nil, err
+	}
+
+	return accessor.createTicketChange(ticketID, string(TicketCommentChange), author, oldValue, newValue, time), nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// regexp for the ticket change field recording an edit of a comment: $1=edit number
+var commentEditFieldRegexp = regexp.MustCompile(`^_comment([[:digit:]]+)$`)
+
+// numberedTicketCommentEdit is a ticket comment edit along with its position in the sequence of edits of its comment
+type numberedTicketCommentEdit struct {
+	editNum int
+	edit    TicketCommentEdit
+}
+
+// getTicketCommentEdits retrieves all edits of comments on a given ticket.
+// Trac records each edit of a comment as a '_comment<n>' change sharing the timestamp of the comment itself:
+// the author of the change is the editor, its old value is the comment text before the edit and its new value is the time of the edit.
+// The returned map is keyed by the (unconverted) Trac timestamp of the edited comment, each entry holding the edits of that comment in the order they were made.
+func (accessor *DefaultAccessor) getTicketCommentEdits(ticketID int64) (map[int64][]TicketCommentEdit, error) {
+	rows, err := accessor.query(`
+		SELECT time, field, COALESCE(author, ''), COALESCE(oldvalue, ''), COALESCE(newvalue, '')
+			FROM ticket_change
+			WHERE ticket = $1 AND field LIKE '_comment%'`,
+		ticketID)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac comment edits for ticket %d", ticketID)
+		return nil, err
+	}
+
+	numberedEdits := make(map[int64][]numberedTicketCommentEdit)
+	for rows.Next() {
+		var commentTime int64
+		var field, author, oldText, editTimeStr string
+		if err := rows.Scan(&commentTime, &field, &author, &oldText, &editTimeStr); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac comment edit for ticket %d", ticketID)
+			return nil, err
+		}
+
+		fieldMatch := commentEditFieldRegexp.FindStringSubmatch(field)
+		if fieldMatch == nil {
+			continue
+		}
+		editNum, _ := strconv.Atoi(fieldMatch[1])
+
+		// edit time is held as a string in microseconds - fall back on the time of the comment itself if it cannot be read
+		editTime, err := strconv.ParseInt(editTimeStr, 10, 64)
+		if err != nil {
+			editTime = commentTime
+		}
+
+		edit := TicketCommentEdit{Author: author, OldText: oldText, Time: editTime / 1000000}
+		numberedEdits[commentTime] = append(numberedEdits[commentTime], numberedTicketCommentEdit{editNum: editNum, edit: edit})
+	}
+
+	commentEdits := make(map[int64][]TicketCommentEdit)
+	for commentTime, edits := range numberedEdits {
+		sort.Slice(edits, func(i, j int) bool { return edits[i].editNum < edits[j].editNum })
+		for _, numberedEdit := range edits {
+			commentEdits[commentTime] = append(commentEdits[commentTime], numberedEdit.edit)
+		}
+	}
+
+	return commentEdits, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package trac
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// hasMasterTicketsTable determines whether the Trac database contains the table created by the MasterTickets plugin.
+func (accessor *DefaultAccessor) hasMasterTicketsTable() (bool, error) {
+	return accessor.hasTable("mastertickets")
+}
+
+// regexp for separators between ticket numbers in the MasterTickets plugin's 'blocking' and 'blockedby' fields
+var ticketListSeparatorRegexp = regexp.MustCompile(`[,\s]+`)
+
+// splitTicketList splits the value of a MasterTickets 'blocking' or 'blockedby' field into ticket ids, ignoring anything that is not a ticket number.
+func splitTicketList(ticketList string) map[int64]bool {
+	ticketIDs := make(map[int64]bool)
+	for _, ticketStr := range ticketListSeparatorRegexp.Split(ticketList, -1) {
+		ticketID, err := strconv.ParseInt(strings.TrimPrefix(ticketStr, "#"), 10, 64)
+		if err == nil {
+			ticketIDs[ticketID] = true
+		}
+	}
+
+	return ticketIDs
+}
+
+// getDependencyAddedTimes retrieves the time at which each dependency between Trac tickets was last added,
+// as recorded in the history of the MasterTickets plugin's 'blockedby' and 'blocking' fields.
+// The result is keyed by the blocked ticket then by the ticket blocking it.
+func (accessor *DefaultAccessor) getDependencyAddedTimes() (map[int64]map[int64]int64, error) {
+	rows, err := accessor.query(`
+		SELECT ticket, field, COALESCE(oldvalue, ''), COALESCE(newvalue, ''), ` + accessor.dialect.timestampToSeconds("time") + `
+		FROM ticket_change
+		WHERE field IN ('blockedby', 'blocking')
+		ORDER BY time asc`)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac ticket dependency changes")
+		return nil, err
+	}
+
+	addedTimes := make(map[int64]map[int64]int64)
+	for rows.Next() {
+		var ticketID, time int64
+		var field, oldValue, newValue string
+		if err := rows.Scan(&ticketID, &field, &oldValue, &newValue, &time); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac ticket dependency change")
+			return nil, err
+		}
+
+		oldTicketIDs := splitTicketList(oldValue)
+		for otherTicketID := range splitTicketList(newValue) {
+			if oldTicketIDs[otherTicketID] {
+				continue
+			}
+
+			blockedTicketID, blockingTicketID := ticketID, otherTicketID
+			if field == "blocking" {
+				blockedTicketID, blockingTicketID = otherTicketID, ticketID
+			}
+			if addedTimes[blockedTicketID] == nil {
+				addedTimes[blockedTicketID] = make(map[int64]int64)
+			}
+			if time > addedTimes[blockedTicketID][blockingTicketID] {
+				addedTimes[blockedTicketID][blockingTicketID] = time
+			}
+		}
+	}
+
+	return addedTimes, nil
+}
+
+// GetTicketDependencies retrieves all dependencies between Trac tickets, passing each one to the provided "handler" function.
+// There are no dependencies if the MasterTickets plugin has not been used.
+// Only the dependencies currently recorded by the plugin are retrieved: dependencies which were added then removed are not.
+func (accessor *DefaultAccessor) GetTicketDependencies(handlerFn func(dependency *TicketDependency) error) error {
+	hasMasterTickets, err := accessor.hasMasterTicketsTable()
+	if err != nil {
+		return err
+	}
+	if !hasMasterTickets {
+		log.Debug("no MasterTickets plugin table found in Trac database - no ticket dependencies")
+		return nil
+	}
+
+	addedTimes, err := accessor.getDependencyAddedTimes()
+	if err != nil {
+		return err
+	}
+
+	// in the MasterTickets table, the 'source' ticket blocks the 'dest' ticket
+	rows, err := accessor.query(`
+		SELECT m.dest, m.source,
+			COALESCE(` + accessor.dialect.timestampToSeconds("d.time") + `, 0),
+			COALESCE(` + accessor.dialect.timestampToSeconds("s.time") + `, 0)
+		FROM mastertickets m
+		LEFT JOIN ticket d ON d.id = m.dest
+		LEFT JOIN ticket s ON s.id = m.source
+		ORDER BY m.dest, m.source`)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac ticket dependencies")
+		return err
+	}
+
+	for rows.Next() {
+		var ticketID, blockingTicketID, ticketTime, blockingTicketTime int64
+		if err := rows.Scan(&ticketID, &blockingTicketID, &ticketTime, &blockingTicketTime); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac ticket dependency")
+			return err
+		}
+
+		// use the time the dependency was added if recorded, otherwise the creation time of the later ticket
+		dependencyTime := addedTimes[ticketID][blockingTicketID]
+		if dependencyTime == 0 {
+			dependencyTime = ticketTime
+			if blockingTicketTime > dependencyTime {
+				dependencyTime = blockingTicketTime
+			}
+		}
+
+		dependency := TicketDependency{TicketID: ticketID, BlockingTicketID: blockingTicketID, Time: dependencyTime}
+		if err = handlerFn(&dependency); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -8,7 +8,7 @@ import "github.com/pkg/errors"
 
 // GetTypes retrieves all types used in Trac tickets, passing each one to the provided "handler" function.
 func (accessor *DefaultAccessor) GetTypes(handlerFn func(tracType *Label) error) error {
-	rows, err := accessor.db.Query(`SELECT DISTINCT type FROM ticket`)
+	rows, err := accessor.query(`SELECT DISTINCT type FROM ticket`)
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving Trac types")
 		return err
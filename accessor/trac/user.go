@@ -10,13 +10,16 @@ import "github.com/pkg/errors"
 func (accessor *DefaultAccessor) GetUserNames(handlerFn func(userName string) error) error {
 	// find every conceivable place where a user name may be hiding
 	// some of these may be redundant but it does no harm
-	rows, err := accessor.db.Query(`
+	rows, err := accessor.query(`
 		SELECT owner FROM ticket
 		UNION SELECT author FROM attachment
 		UNION SELECT author FROM ticket_change
 		UNION SELECT oldvalue FROM ticket_change WHERE field='owner' AND oldvalue != ''
 		UNION SELECT newvalue FROM ticket_change WHERE field='owner' AND newvalue != ''
-		UNION SELECT author FROM wiki`)
+		UNION SELECT author FROM wiki
+		UNION SELECT username FROM permission
+			WHERE username NOT IN ('` + AnonymousSubject + `', '` + AuthenticatedSubject + `')
+			AND username NOT IN (SELECT action FROM permission)`)
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving Trac users")
 		return err
@@ -35,5 +38,72 @@ func (accessor *DefaultAccessor) GetUserNames(handlerFn func(userName string) er
 
 	}
 
+	return accessor.getCcUserNames(handlerFn)
+}
+
+// getCcUserNames retrieves the names of all users mentioned in current or past Trac ticket Cc lists, passing each one to the provided "handler" function.
+func (accessor *DefaultAccessor) getCcUserNames(handlerFn func(userName string) error) error {
+	rows, err := accessor.query(`
+		SELECT DISTINCT COALESCE(cc, '') FROM ticket
+		UNION
+		SELECT DISTINCT COALESCE(oldvalue, '') FROM ticket_change WHERE field = '` + string(TicketCcChange) + `'
+		UNION
+		SELECT DISTINCT COALESCE(newvalue, '') FROM ticket_change WHERE field = '` + string(TicketCcChange) + `'`)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac Cc users")
+		return err
+	}
+
+	ccUserNames := make(map[string]bool)
+	for rows.Next() {
+		var cc string
+		if err = rows.Scan(&cc); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac Cc user")
+			return err
+		}
+
+		for _, userName := range SplitCc(cc) {
+			ccUserNames[userName] = true
+		}
+	}
+
+	for userName := range ccUserNames {
+		if err = handlerFn(userName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetUserProfiles retrieves the full name and email address recorded for each Trac user in their session preferences,
+// passing each one to the provided "handler" function.
+func (accessor *DefaultAccessor) GetUserProfiles(handlerFn func(profile *UserProfile) error) error {
+	// only authenticated sessions belong to named users - others are anonymous browser sessions
+	rows, err := accessor.query(`
+		SELECT sid,
+			COALESCE(MAX(CASE WHEN name = 'name' THEN value END), ''),
+			COALESCE(MAX(CASE WHEN name = 'email' THEN value END), '')
+		FROM session_attribute
+		WHERE authenticated = 1 AND name IN ('name', 'email')
+		GROUP BY sid
+		ORDER BY sid`)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac user profiles")
+		return err
+	}
+
+	for rows.Next() {
+		var profile UserProfile
+		if err = rows.Scan(&profile.UserName, &profile.FullName, &profile.Email); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac user profile")
+			return err
+		}
+
+		if err = handlerFn(&profile); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
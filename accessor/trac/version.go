@@ -8,10 +8,10 @@ import "github.com/pkg/errors"
 
 // GetVersions retrieves all versions used in Trac, passing each one to the provided "handler" function.
 func (accessor *DefaultAccessor) GetVersions(handlerFn func(version *Label) error) error {
-	rows, err := accessor.db.Query(`
-		SELECT DISTINCT COALESCE(version,'') name, '' desc FROM ticket
+	rows, err := accessor.query(`
+		SELECT DISTINCT COALESCE(version,'') AS name, '' AS description FROM ticket
 		UNION
-		SELECT COALESCE(name,'') name, COALESCE(description,'') desc FROM version`)
+		SELECT COALESCE(name,'') AS name, COALESCE(description,'') AS description FROM version`)
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving Trac versions")
 		return err
@@ -32,3 +32,32 @@ func (accessor *DefaultAccessor) GetVersions(handlerFn func(version *Label) erro
 
 	return nil
 }
+
+// GetDefinedVersions retrieves all versions defined in Trac (as opposed to merely used by tickets) with their release times,
+// passing each one to the provided "handler" function.
+func (accessor *DefaultAccessor) GetDefinedVersions(handlerFn func(version *Version) error) error {
+	// NOTE: trac timestamps are to the microseconds, we just need seconds
+	rows, err := accessor.query(`
+		SELECT COALESCE(name,''), COALESCE(description,''), ` + accessor.dialect.timestampToSeconds("COALESCE(time,0)") + `
+			FROM version`)
+	if err != nil {
+		err = errors.Wrapf(err, "retrieving Trac versions")
+		return err
+	}
+
+	for rows.Next() {
+		var name, description string
+		var time int64
+		if err := rows.Scan(&name, &description, &time); err != nil {
+			err = errors.Wrapf(err, "retrieving Trac version")
+			return err
+		}
+
+		version := Version{Name: name, Description: description, Time: time}
+		if err = handlerFn(&version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
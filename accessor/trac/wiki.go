@@ -12,7 +12,7 @@ import (
 
 // GetWikiPages retrieves all Trac wiki pages, passing data from each one to the provided "handler" function.
 func (accessor *DefaultAccessor) GetWikiPages(handlerFn func(page *WikiPage) error) error {
-	rows, err := accessor.db.Query(`SELECT name, text, author, comment, version, CAST(time*1e-6 AS int8) FROM wiki`)
+	rows, err := accessor.query(`SELECT name, text, author, comment, version, ` + accessor.dialect.timestampToSeconds("time") + ` FROM wiki`)
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving Trac wiki pages")
 		return err
@@ -52,7 +52,7 @@ func (accessor *DefaultAccessor) GetWikiAttachmentPath(attachment *WikiAttachmen
 
 // GetWikiAttachments retrieves all Trac wiki page attachments, passing data from each one to the provided "handler" function.
 func (accessor *DefaultAccessor) GetWikiAttachments(handlerFn func(attachment *WikiAttachment) error) error {
-	rows, err := accessor.db.Query(`SELECT id, filename FROM attachment WHERE type = 'wiki'`)
+	rows, err := accessor.query(`SELECT id, filename FROM attachment WHERE type = 'wiki'`)
 	if err != nil {
 		err = errors.Wrapf(err, "retrieving attachments to Trac wiki pages")
 		return err
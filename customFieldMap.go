@@ -0,0 +1,85 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/stevejefferson/trac2gitea/importer"
+)
+
+// readCustomFieldMap reads the custom field map from the provided file, if no file provided, import a default map using the provided importer.
+// Blank lines and lines starting with '#' are ignored.
+func readCustomFieldMap(mapFile string, dataImporter *importer.Importer) (map[string]string, error) {
+	if mapFile == "" {
+		return dataImporter.DefaultCustomFieldMap()
+	}
+
+	fd, err := os.Open(mapFile)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	customFieldMap := make(map[string]string)
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		mapLine := scanner.Text()
+		trimmedLine := strings.TrimSpace(mapLine)
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+
+		equalsPos := strings.LastIndex(mapLine, "=")
+		if equalsPos == -1 {
+			return nil, fmt.Errorf("badly formatted custom field map file %s: expecting '=', found %s", mapFile, mapLine)
+		}
+
+		tracFieldOrValue := strings.Trim(mapLine[0:equalsPos], " ")
+		giteaValue := strings.Trim(mapLine[equalsPos+1:], " ")
+		if !strings.Contains(tracFieldOrValue, ":") {
+			switch giteaValue {
+			case importer.CustomFieldLabel, importer.CustomFieldMetadata, importer.CustomFieldIgnore:
+			default:
+				return nil, fmt.Errorf("badly formatted custom field map file %s: expecting '%s', '%s' or '%s' for field %s, found %s",
+					mapFile, importer.CustomFieldLabel, importer.CustomFieldMetadata, importer.CustomFieldIgnore, tracFieldOrValue, giteaValue)
+			}
+		}
+		customFieldMap[tracFieldOrValue] = giteaValue
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return customFieldMap, nil
+}
+
+func writeCustomFieldMapToFile(mapFile string, customFieldMap map[string]string) error {
+	fd, err := os.Create(mapFile)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	// sort entries so that the entries for the values of each field follow the entry for the field itself
+	keys := []string{}
+	for key := range customFieldMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, err := fd.WriteString(key + " = " + customFieldMap[key] + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
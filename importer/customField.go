@@ -0,0 +1,105 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+// Conversions of Trac custom ticket fields.
+// The custom field map maps the name of each custom field onto one of these.
+// For custom fields converted to labels, the custom field map additionally maps "<field-name>:<value>" onto the name of a Gitea label.
+const (
+	// CustomFieldLabel denotes a custom field whose values are converted to Gitea labels.
+	CustomFieldLabel = "label"
+
+	// CustomFieldMetadata denotes a custom field whose value is shown in a metadata table at the top of the Gitea issue.
+	CustomFieldMetadata = "metadata"
+
+	// CustomFieldIgnore denotes a custom field which is not converted.
+	CustomFieldIgnore = "ignore"
+)
+
+// label color for custom field labels
+const customFieldLabelColor = "#5319e7"
+
+// Trac custom field types whose values are drawn from a fixed set
+const (
+	checkboxCustomFieldType = "checkbox"
+	radioCustomFieldType    = "radio"
+	selectCustomFieldType   = "select"
+)
+
+// names of the custom fields used by the MasterTickets plugin - these are imported as issue dependencies rather than converted
+const (
+	blockingCustomFieldName  = "blocking"
+	blockedByCustomFieldName = "blockedby"
+)
+
+// customFieldValueKey returns the key in the custom field map for the label corresponding to a value of a custom field.
+func customFieldValueKey(fieldName string, value string) string {
+	return fieldName + ":" + value
+}
+
+// DefaultCustomFieldMap retrieves the default conversions of Trac custom ticket fields:
+// fields with a fixed set of values are converted to labels, all other fields are shown as issue metadata.
+// The MasterTickets and TimingAndEstimation plugin fields are ignored because the data they record is imported separately.
+func (importer *Importer) DefaultCustomFieldMap() (map[string]string, error) {
+	customFieldMap := make(map[string]string)
+
+	err := importer.tracAccessor.GetCustomFields(func(field *trac.CustomField) error {
+		switch field.Name {
+		case blockingCustomFieldName, blockedByCustomFieldName,
+			hoursCustomFieldName, estimatedHoursCustomFieldName, totalHoursCustomFieldName:
+			customFieldMap[field.Name] = CustomFieldIgnore
+			return nil
+		}
+
+		switch field.Type {
+		case checkboxCustomFieldType:
+			customFieldMap[field.Name] = CustomFieldLabel
+			customFieldMap[customFieldValueKey(field.Name, "1")] = quoteLabelMapTarget(field.Name)
+		case radioCustomFieldType, selectCustomFieldType:
+			customFieldMap[field.Name] = CustomFieldLabel
+			for _, option := range field.Options {
+				if option != "" {
+					customFieldMap[customFieldValueKey(field.Name, option)] = quoteLabelMapTarget(field.Name + "/" + option)
+				}
+			}
+		default:
+			customFieldMap[field.Name] = CustomFieldMetadata
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return customFieldMap, nil
+}
+
+// ImportCustomFieldLabels creates the Gitea labels for the values of all Trac custom fields converted to labels.
+func (importer *Importer) ImportCustomFieldLabels(customFieldMap map[string]string) error {
+	valueKeys := []string{}
+	for key := range customFieldMap {
+		colonPos := strings.Index(key, ":")
+		if colonPos != -1 && customFieldMap[key[0:colonPos]] == CustomFieldLabel {
+			valueKeys = append(valueKeys, key)
+		}
+	}
+	sort.Strings(valueKeys)
+
+	for _, valueKey := range valueKeys {
+		err := importer.importLabels(&trac.Label{Name: valueKey, Description: ""}, customFieldMap, customFieldLabelColor, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -21,6 +21,7 @@ type Importer struct {
 	markdownConverter  markdown.Converter
 	defaultAuthorID    int64
 	convertPredefineds bool
+	loginSourceID      int64
 }
 
 // CreateImporter returns a new Trac to Gitea importer.
@@ -29,13 +30,14 @@ func CreateImporter(
 	gAccessor gitea.Accessor,
 	converter markdown.Converter,
 	dfltAuthor string,
-	convertPredefs bool) (*Importer, error) {
+	convertPredefs bool,
+	loginSrcID int64) (*Importer, error) {
 
 	dfltAuthorID, err := gAccessor.GetUserID(dfltAuthor)
 	if err != nil {
 		return nil, err
 	}
-	importer := Importer{tracAccessor: tAccessor, giteaAccessor: gAccessor, markdownConverter: converter, defaultAuthorID: dfltAuthorID, convertPredefineds: convertPredefs}
+	importer := Importer{tracAccessor: tAccessor, giteaAccessor: gAccessor, markdownConverter: converter, defaultAuthorID: dfltAuthorID, convertPredefineds: convertPredefs, loginSourceID: loginSrcID}
 
 	return &importer, nil
 }
@@ -5,6 +5,9 @@
 package importer
 
 import (
+	"regexp"
+	"strings"
+
 	"github.com/stevejefferson/trac2gitea/accessor/gitea"
 	"github.com/stevejefferson/trac2gitea/accessor/trac"
 	"github.com/stevejefferson/trac2gitea/log"
@@ -13,6 +16,7 @@ import (
 // label colors - courtesy of `trac2gogs`
 const (
 	componentLabelColor  = "#fbca04"
+	keywordLabelColor    = "#d4c5f9"
 	priorityLabelColor   = "#207de5"
 	resolutionLabelColor = "#9e9e9e"
 	severityLabelColor   = "#eb6420"
@@ -20,6 +24,87 @@ const (
 	versionLabelColor    = "#009800"
 )
 
+// regexp for a Gitea label in a label map: $1=label name, $2=label color (optional, as '#rrggbb')
+var labelRegexp = regexp.MustCompile(`^\s*(.*?)(?:\s+(#[[:xdigit:]]{6}))?\s*$`)
+
+// prefixes of label map targets which are not Gitea labels
+const (
+	milestoneLabelMapTarget = "milestone:"
+	assigneeLabelMapTarget  = "assignee:"
+)
+
+// labelMapping is a parsed label map entry: the Gitea labels (each of the form "<label-name> [<label-color>]") for a Trac item,
+// plus the Gitea milestone ("milestone:<milestone-name>") and default assignee ("assignee:<gitea-user>") for the item, if given.
+type labelMapping struct {
+	labels    []string
+	milestone string
+	assignee  string
+}
+
+// SplitLabelMapping splits a label map entry into its comma-separated targets.
+// A target containing a comma can be enclosed in double quotes, within which a double quote is written as two double quotes.
+func SplitLabelMapping(mapping string) []string {
+	targets := []string{}
+	var target strings.Builder
+	inQuotes := false
+	for pos := 0; pos < len(mapping); pos++ {
+		char := mapping[pos]
+		switch {
+		case char == '"' && inQuotes && pos+1 < len(mapping) && mapping[pos+1] == '"':
+			target.WriteByte('"')
+			pos++
+		case char == '"' && (inQuotes || strings.Trim(target.String(), " ") == ""):
+			// quotes are only recognised around the start of a target so that unquoted label names can still contain them
+			inQuotes = !inQuotes
+		case char == ',' && !inQuotes:
+			targets = append(targets, strings.Trim(target.String(), " "))
+			target.Reset()
+		default:
+			target.WriteByte(char)
+		}
+	}
+
+	return append(targets, strings.Trim(target.String(), " "))
+}
+
+// quoteLabelMapTarget encloses a label map target in double quotes if it would otherwise be split at a comma or have quotes removed.
+func quoteLabelMapTarget(target string) string {
+	if !strings.Contains(target, ",") && !strings.HasPrefix(strings.Trim(target, " "), `"`) {
+		return target
+	}
+
+	return `"` + strings.ReplaceAll(target, `"`, `""`) + `"`
+}
+
+// parseLabelMapping parses a label map entry consisting of a comma-separated list of targets for a Trac item.
+func parseLabelMapping(mapping string) *labelMapping {
+	result := labelMapping{labels: []string{}}
+	for _, target := range SplitLabelMapping(mapping) {
+		switch {
+		case target == "":
+			continue
+		case strings.HasPrefix(target, milestoneLabelMapTarget):
+			result.milestone = strings.Trim(target[len(milestoneLabelMapTarget):], " ")
+		case strings.HasPrefix(target, assigneeLabelMapTarget):
+			result.assignee = strings.Trim(target[len(assigneeLabelMapTarget):], " ")
+		default:
+			result.labels = append(result.labels, target)
+		}
+	}
+
+	return &result
+}
+
+// parseLabel parses a Gitea label in a label map of the form "<label-name> [<label-color>]", returns the label name and color ("" if none).
+func parseLabel(label string) (string, string) {
+	match := labelRegexp.FindStringSubmatch(label)
+	if match == nil {
+		return strings.Trim(label, " "), ""
+	}
+
+	return match[1], match[2]
+}
+
 // defaultLabelMap retrieves the default mapping between the Trac items returned by the provided function and Gitea labels
 func (importer *Importer) defaultLabelMap(tracMethod func(tAccessor trac.Accessor, handlerFn func(tracLabel *trac.Label) error) error) (map[string]string, error) {
 	labelMap := make(map[string]string)
@@ -28,7 +113,7 @@ func (importer *Importer) defaultLabelMap(tracMethod func(tAccessor trac.Accesso
 		// only interested in named trac items
 		tracName := tracLabel.Name
 		if tracName != "" {
-			labelMap[tracName] = tracName
+			labelMap[tracName] = quoteLabelMapTarget(tracName)
 		}
 		return nil
 	})
@@ -44,6 +129,11 @@ func (importer *Importer) DefaultComponentLabelMap() (map[string]string, error)
 	return importer.defaultLabelMap(trac.Accessor.GetComponents)
 }
 
+// DefaultKeywordLabelMap retrieves the default mapping between Trac keywords and Gitea labels
+func (importer *Importer) DefaultKeywordLabelMap() (map[string]string, error) {
+	return importer.defaultLabelMap(trac.Accessor.GetKeywords)
+}
+
 // DefaultPriorityLabelMap retrieves the default mapping between Trac priorities and Gitea labels
 func (importer *Importer) DefaultPriorityLabelMap() (map[string]string, error) {
 	return importer.defaultLabelMap(trac.Accessor.GetPriorities)
@@ -69,48 +159,90 @@ func (importer *Importer) DefaultVersionLabelMap() (map[string]string, error) {
 	return importer.defaultLabelMap(trac.Accessor.GetVersions)
 }
 
-// getLabelID retrieves the Gitea label ID corresponding to a Trac label name
-func (importer *Importer) getLabelID(tracName string, labelMap map[string]string) (int64, error) {
-	giteaLabelName := labelMap[tracName]
-	if giteaLabelName == "" {
-		return gitea.NullID, nil
-	}
+// getLabelIDs retrieves the IDs of the Gitea labels corresponding to a Trac label name
+func (importer *Importer) getLabelIDs(tracName string, labelMap map[string]string) ([]int64, error) {
+	labelIDs := []int64{}
+	for _, giteaLabel := range parseLabelMapping(labelMap[tracName]).labels {
+		giteaLabelName, _ := parseLabel(giteaLabel)
+		labelID, err := importer.giteaAccessor.GetLabelID(giteaLabelName)
+		if err != nil {
+			return nil, err
+		}
+		if labelID == gitea.NullID {
+			continue
+		}
 
-	labelID, err := importer.giteaAccessor.GetLabelID(giteaLabelName)
-	if err != nil {
-		return gitea.NullID, err
+		log.Debug("mapped Trac label %s onto Gitea label %s", tracName, giteaLabelName)
+		labelIDs = append(labelIDs, labelID)
 	}
 
-	log.Debug("mapped Trac label %s onto Gitea label %s", tracName, giteaLabelName)
-	return labelID, nil
+	return labelIDs, nil
 }
 
-// importLabels imports a single trac label as a Gitea label - any created label will have the provided color.
-// Returns ID of Gitea label.
-func (importer *Importer) importLabel(tracLabel *trac.Label, labelMap map[string]string, labelColor string) (int64, error) {
+// importLabels imports a single trac label as Gitea labels - any created label will have the color given in the label map or, if none, the provided color.
+// If the label is for a Trac item of which a ticket can have only one (e.g. a priority), a scoped label (e.g. "priority/high") is created as exclusive.
+func (importer *Importer) importLabels(tracLabel *trac.Label, labelMap map[string]string, labelColor string, singleValued bool) error {
 	tracName := tracLabel.Name
 	if tracName == "" {
-		return gitea.NullID, nil // ignore unnamed trac items
+		return nil // ignore unnamed trac items
+	}
+
+	// no labels are created for a Trac item with no mapping
+	for _, giteaLabelSpec := range parseLabelMapping(labelMap[tracName]).labels {
+		giteaLabelName, giteaLabelColor := parseLabel(giteaLabelSpec)
+		if giteaLabelColor == "" {
+			giteaLabelColor = labelColor
+		}
+
+		exclusive := singleValued && strings.Contains(giteaLabelName, "/")
+		giteaLabel := gitea.Label{Name: giteaLabelName, Description: tracLabel.Description, Color: giteaLabelColor, Exclusive: exclusive}
+		_, err := importer.giteaAccessor.AddLabel(&giteaLabel)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importVersionMilestone creates the Gitea milestone onto which a Trac version is mapped, if it does not already exist.
+func (importer *Importer) importVersionMilestone(version *trac.Label, versionMap map[string]string) error {
+	milestoneName := parseLabelMapping(versionMap[version.Name]).milestone
+	if milestoneName == "" {
+		return nil
 	}
 
-	giteaLabelName := labelMap[tracName]
-	if giteaLabelName == "" {
-		return gitea.NullID, nil // if no mapping provided, do not create a label
+	// milestone may be a Trac milestone, in which case leave it as it is
+	milestoneID, err := importer.giteaAccessor.GetMilestoneID(milestoneName)
+	if err != nil {
+		return err
+	}
+	if milestoneID != gitea.NullID {
+		return nil
 	}
 
-	giteaLabel := gitea.Label{Name: giteaLabelName, Description: tracLabel.Description, Color: labelColor}
-	labelID, err := importer.giteaAccessor.AddLabel(&giteaLabel)
+	giteaMilestone := gitea.Milestone{Name: milestoneName, Description: version.Description}
+	milestoneID, err = importer.giteaAccessor.AddMilestone(&giteaMilestone)
 	if err != nil {
-		return gitea.NullID, err
+		return err
 	}
 
-	return labelID, nil
+	log.Debug("added milestone (id %d) %s for Trac version %s", milestoneID, milestoneName, version.Name)
+	return nil
 }
 
 // ImportComponents imports Trac components as Gitea labels.
 func (importer *Importer) ImportComponents(componentNameMap map[string]string) error {
 	return importer.tracAccessor.GetComponents(func(component *trac.Label) error {
-		_, err := importer.importLabel(component, componentNameMap, componentLabelColor)
+		err := importer.importLabels(component, componentNameMap, componentLabelColor, true)
+		return err
+	})
+}
+
+// ImportKeywords imports Trac keywords as Gitea labels.
+func (importer *Importer) ImportKeywords(keywordNameMap map[string]string) error {
+	return importer.tracAccessor.GetKeywords(func(keyword *trac.Label) error {
+		err := importer.importLabels(keyword, keywordNameMap, keywordLabelColor, false)
 		return err
 	})
 }
@@ -118,7 +250,7 @@ func (importer *Importer) ImportComponents(componentNameMap map[string]string) e
 // ImportPriorities imports Trac priorities as Gitea labels.
 func (importer *Importer) ImportPriorities(priorityNameMap map[string]string) error {
 	return importer.tracAccessor.GetPriorities(func(priority *trac.Label) error {
-		_, err := importer.importLabel(priority, priorityNameMap, priorityLabelColor)
+		err := importer.importLabels(priority, priorityNameMap, priorityLabelColor, true)
 		return err
 	})
 }
@@ -126,7 +258,7 @@ func (importer *Importer) ImportPriorities(priorityNameMap map[string]string) er
 // ImportResolutions imports Trac resolutions as Gitea labels.
 func (importer *Importer) ImportResolutions(resolutionNameMap map[string]string) error {
 	return importer.tracAccessor.GetResolutions(func(resolution *trac.Label) error {
-		_, err := importer.importLabel(resolution, resolutionNameMap, resolutionLabelColor)
+		err := importer.importLabels(resolution, resolutionNameMap, resolutionLabelColor, true)
 		return err
 	})
 }
@@ -134,7 +266,7 @@ func (importer *Importer) ImportResolutions(resolutionNameMap map[string]string)
 // ImportSeverities imports Trac severities as Gitea labels.
 func (importer *Importer) ImportSeverities(severityNameMap map[string]string) error {
 	return importer.tracAccessor.GetSeverities(func(severity *trac.Label) error {
-		_, err := importer.importLabel(severity, severityNameMap, severityLabelColor)
+		err := importer.importLabels(severity, severityNameMap, severityLabelColor, true)
 		return err
 	})
 }
@@ -142,15 +274,19 @@ func (importer *Importer) ImportSeverities(severityNameMap map[string]string) er
 // ImportTypes imports Trac types as Gitea labels.
 func (importer *Importer) ImportTypes(typeNameMap map[string]string) error {
 	return importer.tracAccessor.GetTypes(func(tracType *trac.Label) error {
-		_, err := importer.importLabel(tracType, typeNameMap, typeLabelColor)
+		err := importer.importLabels(tracType, typeNameMap, typeLabelColor, true)
 		return err
 	})
 }
 
-// ImportVersions imports Trac versions as Gitea labels.
+// ImportVersions imports Trac versions as Gitea labels and creates any Gitea milestones onto which versions are mapped.
 func (importer *Importer) ImportVersions(versionNameMap map[string]string) error {
 	return importer.tracAccessor.GetVersions(func(version *trac.Label) error {
-		_, err := importer.importLabel(version, versionNameMap, versionLabelColor)
-		return err
+		err := importer.importLabels(version, versionNameMap, versionLabelColor, true)
+		if err != nil {
+			return err
+		}
+
+		return importer.importVersionMilestone(version, versionNameMap)
 	})
 }
@@ -20,9 +20,11 @@ var (
 	tracRenamedLabel   *trac.Label
 	tracRemovedLabel   *trac.Label
 	tracUnnamedLabel   *trac.Label
+	tracScopedLabel    *trac.Label
 
 	giteaUnchangedLabel *gitea.Label
 	giteaRenamedLabel   *gitea.Label
+	giteaScopedLabel    *gitea.Label
 )
 
 func createTracLabel(name string, description string) *trac.Label {
@@ -51,10 +53,16 @@ func setUpLabels(t *testing.T) {
 	giteaUnchangedLabel = createGiteaLabel(tracUnchangedLabel.Name, tracUnchangedLabel.Description)
 	giteaRenamedLabel = createGiteaLabel("not-"+tracRenamedLabel.Name, tracRenamedLabel.Description)
 
+	// scoped label with its own color: created as exclusive only where a ticket can have a single value
+	tracScopedLabel = createTracLabel("scoped", "scoped-description")
+	giteaScopedLabel = createGiteaLabel("scope/"+tracScopedLabel.Name, tracScopedLabel.Description)
+	giteaScopedLabel.Color = "#b60205"
+
 	labelMap = make(map[string]string)
 	labelMap[tracUnchangedLabel.Name] = giteaUnchangedLabel.Name
 	labelMap[tracRenamedLabel.Name] = giteaRenamedLabel.Name
 	labelMap[tracRemovedLabel.Name] = ""
+	labelMap[tracScopedLabel.Name] = giteaScopedLabel.Name + " " + giteaScopedLabel.Color
 }
 
 func expectToReturnTracComponents(t *testing.T, components ...*trac.Label) {
@@ -129,6 +137,18 @@ func expectToReturnTracVersions(t *testing.T, versions ...*trac.Label) {
 		})
 }
 
+func expectToReturnTracKeywords(t *testing.T, keywords ...*trac.Label) {
+	mockTracAccessor.
+		EXPECT().
+		GetKeywords(gomock.Any()).
+		DoAndReturn(func(handlerFn func(label *trac.Label) error) error {
+			for _, keyword := range keywords {
+				handlerFn(keyword)
+			}
+			return nil
+		})
+}
+
 // gomock Matcher for Gitea label names
 type giteaLabelNameMatcher struct{ name string }
 
@@ -151,12 +171,18 @@ func expectToAddGiteaLabels(t *testing.T, giteaLabels ...*gitea.Label) {
 	for _, giteaLabel := range giteaLabels {
 		giteaLabelName := giteaLabel.Name
 		giteaLabelDescription := giteaLabel.Description
+		giteaLabelColor := giteaLabel.Color
+		giteaLabelExclusive := giteaLabel.Exclusive
 		mockGiteaAccessor.
 			EXPECT().
 			AddLabel(isGiteaLabel(giteaLabelName)).
 			DoAndReturn(func(label *gitea.Label) (int64, error) {
 				assertEquals(t, giteaLabelName, label.Name)
 				assertEquals(t, giteaLabelDescription, label.Description)
+				assertEquals(t, giteaLabelExclusive, label.Exclusive)
+				if giteaLabelColor != "" {
+					assertEquals(t, giteaLabelColor, label.Color)
+				}
 				giteaLabelID++
 				return giteaLabelID, nil
 			})
@@ -222,3 +248,121 @@ func TestImportVersions(t *testing.T) {
 
 	dataImporter.ImportVersions(labelMap)
 }
+
+func TestImportKeywords(t *testing.T) {
+	setUpLabels(t)
+	defer tearDown(t)
+
+	expectToReturnTracKeywords(t, tracUnchangedLabel, tracRenamedLabel, tracRemovedLabel, tracUnnamedLabel)
+	expectToAddGiteaLabels(t, giteaUnchangedLabel, giteaRenamedLabel)
+
+	dataImporter.ImportKeywords(labelMap)
+}
+
+func TestImportScopedPriorities(t *testing.T) {
+	setUpLabels(t)
+	defer tearDown(t)
+
+	giteaScopedLabel.Exclusive = true
+	expectToReturnTracPriorities(t, tracUnchangedLabel, tracScopedLabel)
+	expectToAddGiteaLabels(t, giteaUnchangedLabel, giteaScopedLabel)
+
+	dataImporter.ImportPriorities(labelMap)
+}
+
+func TestImportScopedKeywords(t *testing.T) {
+	setUpLabels(t)
+	defer tearDown(t)
+
+	// a ticket can have many keywords so keyword labels are never exclusive
+	expectToReturnTracKeywords(t, tracUnchangedLabel, tracScopedLabel)
+	expectToAddGiteaLabels(t, giteaUnchangedLabel, giteaScopedLabel)
+
+	dataImporter.ImportKeywords(labelMap)
+}
+
+func TestImportComponentsWithMultipleLabels(t *testing.T) {
+	setUpLabels(t)
+	defer tearDown(t)
+
+	giteaExtraLabel := createGiteaLabel("extra", tracRenamedLabel.Description)
+	labelMap[tracRenamedLabel.Name] = giteaRenamedLabel.Name + ", " + giteaExtraLabel.Name
+
+	expectToReturnTracComponents(t, tracUnchangedLabel, tracRenamedLabel)
+	expectToAddGiteaLabels(t, giteaUnchangedLabel, giteaRenamedLabel, giteaExtraLabel)
+
+	dataImporter.ImportComponents(labelMap)
+}
+
+func TestImportComponentsWithQuotedLabels(t *testing.T) {
+	setUpLabels(t)
+	defer tearDown(t)
+
+	// quoted label names can contain commas and (doubled) quotes, and can be followed by a color
+	giteaCommaLabel := createGiteaLabel("parser, lexer", tracRenamedLabel.Description)
+	giteaCommaLabel.Color = "#fbca04"
+	giteaQuoteLabel := createGiteaLabel(`the "core", team`, tracRenamedLabel.Description)
+	labelMap[tracRenamedLabel.Name] = `"parser, lexer" #fbca04, "the ""core"", team"`
+
+	expectToReturnTracComponents(t, tracUnchangedLabel, tracRenamedLabel)
+	expectToAddGiteaLabels(t, giteaUnchangedLabel, giteaCommaLabel, giteaQuoteLabel)
+
+	dataImporter.ImportComponents(labelMap)
+}
+
+func TestDefaultComponentLabelMapQuotesNamesWithCommas(t *testing.T) {
+	setUpLabels(t)
+	defer tearDown(t)
+
+	tracCommaLabel := createTracLabel("parser, lexer", "comma-description")
+	giteaCommaLabel := createGiteaLabel(tracCommaLabel.Name, tracCommaLabel.Description)
+
+	// default map should quote the name containing a comma so that it maps onto a single label
+	expectToReturnTracComponents(t, tracUnchangedLabel, tracCommaLabel)
+	defaultMap, err := dataImporter.DefaultComponentLabelMap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, defaultMap[tracUnchangedLabel.Name], tracUnchangedLabel.Name)
+	assertEquals(t, defaultMap[tracCommaLabel.Name], `"parser, lexer"`)
+
+	expectToReturnTracComponents(t, tracCommaLabel)
+	expectToAddGiteaLabels(t, giteaCommaLabel)
+
+	dataImporter.ImportComponents(defaultMap)
+}
+
+func expectToAddGiteaMilestone(t *testing.T, milestoneName string, existingMilestoneID int64, description string) {
+	mockGiteaAccessor.
+		EXPECT().
+		GetMilestoneID(gomock.Eq(milestoneName)).
+		Return(existingMilestoneID, nil)
+	if existingMilestoneID != gitea.NullID {
+		return
+	}
+
+	mockGiteaAccessor.
+		EXPECT().
+		AddMilestone(gomock.Any()).
+		DoAndReturn(func(milestone *gitea.Milestone) (int64, error) {
+			assertEquals(t, milestone.Name, milestoneName)
+			assertEquals(t, milestone.Description, description)
+			return int64(777), nil
+		})
+}
+
+func TestImportVersionsWithMilestones(t *testing.T) {
+	setUpLabels(t)
+	defer tearDown(t)
+
+	// one version mapped onto a new milestone as well as a label, one onto an existing milestone only
+	labelMap[tracUnchangedLabel.Name] = giteaUnchangedLabel.Name + ", milestone:v-" + tracUnchangedLabel.Name
+	labelMap[tracRenamedLabel.Name] = "milestone:v-" + tracRenamedLabel.Name
+
+	expectToReturnTracVersions(t, tracUnchangedLabel, tracRenamedLabel)
+	expectToAddGiteaLabels(t, giteaUnchangedLabel)
+	expectToAddGiteaMilestone(t, "v-"+tracUnchangedLabel.Name, gitea.NullID, tracUnchangedLabel.Description)
+	expectToAddGiteaMilestone(t, "v-"+tracRenamedLabel.Name, int64(888), "")
+
+	dataImporter.ImportVersions(labelMap)
+}
@@ -10,32 +10,99 @@ import (
 	"github.com/stevejefferson/trac2gitea/log"
 )
 
-// ImportMilestones imports Trac milestones as Gitea milestones.
-func (importer *Importer) ImportMilestones() error {
+// DefaultMilestoneMap retrieves the default mapping between Trac milestones and Gitea milestones: each named Trac milestone maps onto a Gitea milestone of the same name.
+func (importer *Importer) DefaultMilestoneMap() (map[string]string, error) {
+	milestoneMap := make(map[string]string)
+	err := importer.tracAccessor.GetMilestones(func(tracMilestone *trac.Milestone) error {
+		if tracMilestone.Name != "" {
+			milestoneMap[tracMilestone.Name] = tracMilestone.Name
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return milestoneMap, nil
+}
+
+// mergeMilestone merges a Gitea milestone converted from a Trac milestone into a Gitea milestone onto which several Trac milestones are mapped:
+// the Gitea milestone has the descriptions of all of its Trac milestones, is due when the last of them is due and is only closed once all of them are completed.
+func mergeMilestone(giteaMilestone *gitea.Milestone, mergedMilestone *gitea.Milestone) {
+	if mergedMilestone.Description != "" {
+		if giteaMilestone.Description != "" {
+			giteaMilestone.Description = giteaMilestone.Description + "\n\n"
+		}
+		giteaMilestone.Description = giteaMilestone.Description + mergedMilestone.Description
+	}
+	if mergedMilestone.DueTime > giteaMilestone.DueTime {
+		giteaMilestone.DueTime = mergedMilestone.DueTime
+	}
+	giteaMilestone.Closed = giteaMilestone.Closed && mergedMilestone.Closed
+	if !giteaMilestone.Closed {
+		giteaMilestone.ClosedTime = 0
+	} else if mergedMilestone.ClosedTime > giteaMilestone.ClosedTime {
+		giteaMilestone.ClosedTime = mergedMilestone.ClosedTime
+	}
+}
+
+// getGiteaMilestones retrieves the Gitea milestones onto which the Trac milestones are mapped by the milestone map, with their descriptions converted to Gitea markdown.
+// Trac milestones mapped onto the same Gitea milestone are merged and Trac milestones with no mapping are omitted.
+func (importer *Importer) getGiteaMilestones(milestoneMap map[string]string) ([]*gitea.Milestone, error) {
+	giteaMilestones := []*gitea.Milestone{}
+	giteaMilestonesByName := make(map[string]*gitea.Milestone)
 	err := importer.tracAccessor.GetMilestones(func(tracMilestone *trac.Milestone) error {
 		if tracMilestone.Name == "" {
 			log.Debug("skipping unnamed Trac milestone...")
 			return nil
 		}
 
-		giteaMilestone := gitea.Milestone{
-			Name:        tracMilestone.Name,
-			Description: tracMilestone.Description,
+		giteaMilestoneName := milestoneMap[tracMilestone.Name]
+		if giteaMilestoneName == "" {
+			log.Debug("skipping Trac milestone %s: not mapped onto a Gitea milestone", tracMilestone.Name)
+			return nil
+		}
+
+		giteaMilestone := &gitea.Milestone{
+			Name:        giteaMilestoneName,
+			Description: importer.markdownConverter.MilestoneConvert(tracMilestone.Name, tracMilestone.Description),
 			Closed:      tracMilestone.Completed != 0,
 			DueTime:     tracMilestone.Due,
 			ClosedTime:  tracMilestone.Completed}
-
-		milestoneID, err := importer.giteaAccessor.AddMilestone(&giteaMilestone)
-		if err != nil {
-			return err
+		if existingMilestone := giteaMilestonesByName[giteaMilestoneName]; existingMilestone != nil {
+			log.Debug("merging Trac milestone %s into milestone %s", tracMilestone.Name, giteaMilestoneName)
+			mergeMilestone(existingMilestone, giteaMilestone)
+			return nil
 		}
 
-		log.Debug("added milestone (id %d) %s", milestoneID, tracMilestone.Name)
+		giteaMilestones = append(giteaMilestones, giteaMilestone)
+		giteaMilestonesByName[giteaMilestoneName] = giteaMilestone
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return giteaMilestones, nil
+}
+
+// ImportMilestones imports Trac milestones as Gitea milestones.
+// Trac milestones are renamed according to the milestone map, Trac milestones mapped onto the same Gitea milestone are merged
+// and Trac milestones with no mapping are not imported.
+func (importer *Importer) ImportMilestones(milestoneMap map[string]string) error {
+	giteaMilestones, err := importer.getGiteaMilestones(milestoneMap)
 	if err != nil {
 		return err
 	}
 
+	for _, giteaMilestone := range giteaMilestones {
+		milestoneID, err := importer.giteaAccessor.AddMilestone(giteaMilestone)
+		if err != nil {
+			return err
+		}
+
+		log.Debug("added milestone (id %d) %s", milestoneID, giteaMilestone.Name)
+	}
+
 	return importer.giteaAccessor.UpdateRepoMilestoneCounts()
 }
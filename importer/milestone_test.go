@@ -60,6 +60,10 @@ func setUpMilestones(t *testing.T) {
 		Due:         uncompletedMilestoneDueTime,
 		Completed:   uncompletedMilestoneCompletedTime}
 
+	milestoneMap = make(map[string]string)
+	milestoneMap[completedMilestoneName] = completedMilestoneName
+	milestoneMap[uncompletedMilestoneName] = uncompletedMilestoneName
+
 	// expect trac accessor to return each of our trac milestones
 	mockTracAccessor.
 		EXPECT().
@@ -72,6 +76,15 @@ func setUpMilestones(t *testing.T) {
 		})
 }
 
+func expectMilestoneDescriptionConversion(t *testing.T, milestoneName string, description string) string {
+	convertedDescription := "converted " + description
+	mockMarkdownConverter.
+		EXPECT().
+		MilestoneConvert(gomock.Eq(milestoneName), gomock.Eq(description)).
+		Return(convertedDescription)
+	return convertedDescription
+}
+
 // gomock Matcher for milestone names
 type milestoneNameMatcher struct{ name string }
 
@@ -92,11 +105,13 @@ func TestMilestones(t *testing.T) {
 	setUpMilestones(t)
 	defer tearDown(t)
 
+	convertedCompletedDescription := expectMilestoneDescriptionConversion(t, completedMilestoneName, completedMilestoneDescription)
+	convertedUncompletedDescription := expectMilestoneDescriptionConversion(t, uncompletedMilestoneName, uncompletedMilestoneDescription)
 	mockGiteaAccessor.
 		EXPECT().
 		AddMilestone(isMilestone(completedMilestoneName)).
 		DoAndReturn(func(giteaMilestone *gitea.Milestone) (int64, error) {
-			assertEquals(t, giteaMilestone.Description, completedMilestoneDescription)
+			assertEquals(t, giteaMilestone.Description, convertedCompletedDescription)
 			assertEquals(t, giteaMilestone.Closed, true)
 			assertEquals(t, giteaMilestone.DueTime, completedMilestoneDueTime)
 			assertEquals(t, giteaMilestone.ClosedTime, completedMilestoneCompletedTime)
@@ -106,7 +121,7 @@ func TestMilestones(t *testing.T) {
 		EXPECT().
 		AddMilestone(isMilestone(uncompletedMilestoneName)).
 		DoAndReturn(func(giteaMilestone *gitea.Milestone) (int64, error) {
-			assertEquals(t, giteaMilestone.Description, uncompletedMilestoneDescription)
+			assertEquals(t, giteaMilestone.Description, convertedUncompletedDescription)
 			assertEquals(t, giteaMilestone.Closed, false)
 			assertEquals(t, giteaMilestone.DueTime, uncompletedMilestoneDueTime)
 			assertEquals(t, giteaMilestone.ClosedTime, uncompletedMilestoneCompletedTime)
@@ -117,5 +132,59 @@ func TestMilestones(t *testing.T) {
 		UpdateRepoMilestoneCounts().
 		Return(nil)
 
-	dataImporter.ImportMilestones()
+	dataImporter.ImportMilestones(milestoneMap)
+}
+
+func TestRenamedMilestones(t *testing.T) {
+	setUpMilestones(t)
+	defer tearDown(t)
+
+	// rename one milestone and do not import the other
+	milestoneMap[completedMilestoneName] = "renamed"
+	milestoneMap[uncompletedMilestoneName] = ""
+
+	// description is converted in the context of the Trac milestone
+	convertedDescription := expectMilestoneDescriptionConversion(t, completedMilestoneName, completedMilestoneDescription)
+	mockGiteaAccessor.
+		EXPECT().
+		AddMilestone(isMilestone("renamed")).
+		DoAndReturn(func(giteaMilestone *gitea.Milestone) (int64, error) {
+			assertEquals(t, giteaMilestone.Description, convertedDescription)
+			assertEquals(t, giteaMilestone.Closed, true)
+			return completedMilestoneID, nil
+		})
+	mockGiteaAccessor.
+		EXPECT().
+		UpdateRepoMilestoneCounts().
+		Return(nil)
+
+	dataImporter.ImportMilestones(milestoneMap)
+}
+
+func TestMergedMilestones(t *testing.T) {
+	setUpMilestones(t)
+	defer tearDown(t)
+
+	milestoneMap[completedMilestoneName] = "merged"
+	milestoneMap[uncompletedMilestoneName] = "merged"
+	convertedCompletedDescription := expectMilestoneDescriptionConversion(t, completedMilestoneName, completedMilestoneDescription)
+	convertedUncompletedDescription := expectMilestoneDescriptionConversion(t, uncompletedMilestoneName, uncompletedMilestoneDescription)
+
+	// merged milestone has both descriptions, the latest due time and is open because one of its Trac milestones is not completed
+	mockGiteaAccessor.
+		EXPECT().
+		AddMilestone(isMilestone("merged")).
+		DoAndReturn(func(giteaMilestone *gitea.Milestone) (int64, error) {
+			assertEquals(t, giteaMilestone.Description, convertedCompletedDescription+"\n\n"+convertedUncompletedDescription)
+			assertEquals(t, giteaMilestone.Closed, false)
+			assertEquals(t, giteaMilestone.DueTime, uncompletedMilestoneDueTime)
+			assertEquals(t, giteaMilestone.ClosedTime, int64(0))
+			return completedMilestoneID, nil
+		})
+	mockGiteaAccessor.
+		EXPECT().
+		UpdateRepoMilestoneCounts().
+		Return(nil)
+
+	dataImporter.ImportMilestones(milestoneMap)
 }
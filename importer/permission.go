@@ -0,0 +1,294 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// Conversions of Trac permissions.
+// The permission map maps the name of each Trac permission onto one of these Gitea repository access levels.
+// Where the Gitea repository is owned by an organization, the permission map additionally maps "group:<trac-group>" onto the name of
+// the Gitea team into which the members of that group are placed - groups mapped onto an empty team name are not converted to teams.
+const (
+	// PermissionRead denotes a Trac permission conferring read access to the Gitea repository.
+	PermissionRead = "read"
+
+	// PermissionWrite denotes a Trac permission conferring write access to the Gitea repository.
+	PermissionWrite = "write"
+
+	// PermissionAdmin denotes a Trac permission conferring administrative access to the Gitea repository.
+	PermissionAdmin = "admin"
+
+	// PermissionNone denotes a Trac permission conferring no access to the Gitea repository.
+	PermissionNone = "none"
+)
+
+var permissionAccessModes = map[string]gitea.AccessMode{
+	PermissionRead:  gitea.ReadAccess,
+	PermissionWrite: gitea.WriteAccess,
+	PermissionAdmin: gitea.AdminAccess,
+	PermissionNone:  gitea.NoAccess,
+}
+
+// regexps for assigning default Gitea access levels to Trac permissions
+var (
+	adminPermissionRegexp = regexp.MustCompile(`^(?:.*_ADMIN|PERMISSION_.*)$`)
+	writePermissionRegexp = regexp.MustCompile(`^(?:.*_(?:MODIFY|DELETE|CHGPROP)|(?:WIKI|MILESTONE|REPORT)_CREATE|TICKET_EDIT_.*)$`)
+	readPermissionRegexp  = regexp.MustCompile(`^(?:.*_VIEW|TICKET_CREATE|TICKET_APPEND)$`)
+)
+
+// permissionGroupKey returns the key in the permission map for the team corresponding to a Trac group.
+func permissionGroupKey(groupName string) string {
+	return "group:" + groupName
+}
+
+// tracPermissions holds the Trac permission grants, organised by subject (user or group).
+type tracPermissions struct {
+	actions map[string][]string // permissions granted directly to each subject
+	groups  map[string][]string // groups of which each subject is a direct member
+	isGroup map[string]bool
+}
+
+// readTracPermissions reads all Trac permission grants.
+// As in Trac itself, an action which is also a subject or is not an upper case permission name is taken to be a group.
+func (importer *Importer) readTracPermissions() (*tracPermissions, error) {
+	type grant struct{ subject, action string }
+	grants := []grant{}
+	subjects := make(map[string]bool)
+	err := importer.tracAccessor.GetPermissions(func(subject string, action string) error {
+		grants = append(grants, grant{subject: subject, action: action})
+		subjects[subject] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := tracPermissions{actions: make(map[string][]string), groups: make(map[string][]string), isGroup: make(map[string]bool)}
+	for _, grant := range grants {
+		if subjects[grant.action] || strings.ToUpper(grant.action) != grant.action {
+			permissions.groups[grant.subject] = append(permissions.groups[grant.subject], grant.action)
+			permissions.isGroup[grant.action] = true
+		} else {
+			permissions.actions[grant.subject] = append(permissions.actions[grant.subject], grant.action)
+		}
+	}
+
+	return &permissions, nil
+}
+
+// allGroups returns the groups of which a subject is a member, either directly or through membership of other groups.
+func (permissions *tracPermissions) allGroups(subject string, groups map[string]bool) {
+	for _, group := range permissions.groups[subject] {
+		if !groups[group] {
+			groups[group] = true
+			permissions.allGroups(group, groups)
+		}
+	}
+}
+
+// memberSubjects returns the subjects whose permissions a subject has: the subject itself and its groups and,
+// for a user, Trac's built-in "anonymous" and "authenticated" subjects (and their groups) whose permissions every logged-in user has.
+func (permissions *tracPermissions) memberSubjects(subject string) map[string]bool {
+	subjects := map[string]bool{subject: true}
+	permissions.allGroups(subject, subjects)
+	if !permissions.isGroup[subject] {
+		for _, builtInSubject := range []string{trac.AnonymousSubject, trac.AuthenticatedSubject} {
+			subjects[builtInSubject] = true
+			permissions.allGroups(builtInSubject, subjects)
+		}
+	}
+
+	return subjects
+}
+
+// access returns the Gitea access level conferred on a subject by its Trac permissions, including those it has through its groups
+// and, for a user, those of every logged-in user.
+func (permissions *tracPermissions) access(subject string, permissionMap map[string]string) gitea.AccessMode {
+	subjects := permissions.memberSubjects(subject)
+
+	access := gitea.NoAccess
+	for grantee := range subjects {
+		for _, action := range permissions.actions[grantee] {
+			if actionAccess := permissionAccessModes[permissionMap[action]]; actionAccess > access {
+				access = actionAccess
+			}
+		}
+	}
+
+	return access
+}
+
+// users returns the sorted names of all Trac users with permissions, excluding Trac's built-in subjects.
+func (permissions *tracPermissions) users() []string {
+	users := []string{}
+	addUser := func(subject string) {
+		if !permissions.isGroup[subject] && subject != trac.AnonymousSubject && subject != trac.AuthenticatedSubject {
+			users = append(users, subject)
+		}
+	}
+	for subject := range permissions.actions {
+		addUser(subject)
+	}
+	for subject := range permissions.groups {
+		if _, found := permissions.actions[subject]; !found {
+			addUser(subject)
+		}
+	}
+	sort.Strings(users)
+
+	return users
+}
+
+// sortedGroups returns the sorted names of all Trac groups.
+func (permissions *tracPermissions) sortedGroups() []string {
+	groups := []string{}
+	for group := range permissions.isGroup {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	return groups
+}
+
+// DefaultPermissionMap retrieves the default conversions of Trac permissions:
+// administrative permissions confer admin access, permissions to change data confer write access and permissions to view data confer read access.
+// Each Trac group is converted to a Gitea team of the same name.
+func (importer *Importer) DefaultPermissionMap() (map[string]string, error) {
+	permissions, err := importer.readTracPermissions()
+	if err != nil {
+		return nil, err
+	}
+
+	permissionMap := make(map[string]string)
+	for _, actions := range permissions.actions {
+		for _, action := range actions {
+			switch {
+			case adminPermissionRegexp.MatchString(action):
+				permissionMap[action] = PermissionAdmin
+			case readPermissionRegexp.MatchString(action):
+				permissionMap[action] = PermissionRead
+			case writePermissionRegexp.MatchString(action):
+				permissionMap[action] = PermissionWrite
+			default:
+				permissionMap[action] = PermissionNone
+			}
+		}
+	}
+	for group := range permissions.isGroup {
+		permissionMap[permissionGroupKey(group)] = group
+	}
+
+	return permissionMap, nil
+}
+
+// importTeams converts Trac groups into teams of the Gitea organization owning our repository.
+// Returns the access each user has been given through their teams.
+func (importer *Importer) importTeams(permissions *tracPermissions, userMap map[string]string, permissionMap map[string]string) (map[string]gitea.AccessMode, error) {
+	teamAccess := make(map[string]gitea.AccessMode)
+	users := permissions.users()
+	for _, group := range permissions.sortedGroups() {
+		teamName := permissionMap[permissionGroupKey(group)]
+		if teamName == "" {
+			continue
+		}
+
+		access := permissions.access(group, permissionMap)
+		if access == gitea.NoAccess {
+			log.Debug("Trac group %s confers no access to Gitea repository - not converted to team", group)
+			continue
+		}
+
+		team := gitea.Team{Name: teamName, Description: "Imported from Trac group " + group, Access: access}
+		teamID, err := importer.giteaAccessor.AddTeam(&team)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, user := range users {
+			if !permissions.memberSubjects(user)[group] {
+				continue
+			}
+
+			userID, err := importer.getUserID(user, userMap)
+			if err != nil {
+				return nil, err
+			}
+			if userID == gitea.NullID {
+				log.Warn("cannot find Gitea user for member %s of Trac group %s - not added to team %s", user, group, teamName)
+				continue
+			}
+
+			if err = importer.giteaAccessor.AddTeamMember(teamID, userID); err != nil {
+				return nil, err
+			}
+			if access > teamAccess[user] {
+				teamAccess[user] = access
+			}
+		}
+
+		log.Info("imported Trac group %s as team %s", group, teamName)
+	}
+
+	return teamAccess, nil
+}
+
+// ImportPermissions imports Trac permissions as access to our Gitea repository.
+// If the repository is owned by an organization, Trac groups are converted into teams of that organization.
+// Any users not given their full access through a team are made collaborators on the repository.
+// The permissions of Trac's built-in "anonymous" and "authenticated" subjects are not imported as such (these correspond to the visibility of the repository)
+// but are included in the access of each user.
+func (importer *Importer) ImportPermissions(userMap map[string]string, permissionMap map[string]string) error {
+	permissions, err := importer.readTracPermissions()
+	if err != nil {
+		return err
+	}
+
+	isOrganization, err := importer.giteaAccessor.IsRepoOwnedByOrganization()
+	if err != nil {
+		return err
+	}
+
+	teamAccess := make(map[string]gitea.AccessMode)
+	if isOrganization {
+		teamAccess, err = importer.importTeams(permissions, userMap, permissionMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, user := range permissions.users() {
+		access := permissions.access(user, permissionMap)
+		if access <= teamAccess[user] {
+			continue
+		}
+
+		userID, err := importer.getUserID(user, userMap)
+		if err != nil {
+			return err
+		}
+		if userID == gitea.NullID {
+			log.Warn("cannot find Gitea user for Trac user %s - permissions not imported", user)
+			continue
+		}
+		if userID == importer.defaultAuthorID {
+			// the repository owner already has full access
+			continue
+		}
+
+		if err = importer.giteaAccessor.AddRepoCollaborator(userID, access); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/importer"
+)
+
+const (
+	tracGroup = "developers"
+	giteaTeam = "gitea-developers"
+	teamID    = int64(321)
+
+	tracGroupMember   = "trac-group-member"
+	giteaGroupMember  = "gitea-group-member"
+	groupMemberUserID = int64(411)
+
+	tracAdminUser   = "trac-admin-user"
+	giteaAdminUser  = "gitea-admin-user"
+	adminUserUserID = int64(412)
+
+	tracViewerUser   = "trac-viewer-user"
+	giteaViewerUser  = "gitea-viewer-user"
+	viewerUserUserID = int64(413)
+
+	unmappedPermissionUser = "trac-unmapped-permission-user"
+
+	tracAuthenticatedUser   = "trac-authenticated-user"
+	giteaAuthenticatedUser  = "gitea-authenticated-user"
+	authenticatedUserUserID = int64(414)
+)
+
+var permissionMap map[string]string
+
+func setUpPermissions(t *testing.T) {
+	setUp(t)
+
+	userMap[tracGroupMember] = giteaGroupMember
+	userMap[tracAdminUser] = giteaAdminUser
+	userMap[tracViewerUser] = giteaViewerUser
+	userMap[unmappedPermissionUser] = ""
+	userMap[tracAuthenticatedUser] = giteaAuthenticatedUser
+
+	permissionMap = map[string]string{
+		"TRAC_ADMIN":           importer.PermissionAdmin,
+		"TICKET_MODIFY":        importer.PermissionWrite,
+		"WIKI_VIEW":            importer.PermissionRead,
+		"TICKET_CREATE":        importer.PermissionRead,
+		"CUSTOM_PLUGIN_ACTION": importer.PermissionNone,
+		"group:" + tracGroup:   giteaTeam,
+	}
+}
+
+func expectTracPermissionRetrievals(t *testing.T) {
+	permissions := [][]string{
+		{trac.AnonymousSubject, "WIKI_VIEW"},
+		{trac.AuthenticatedSubject, "TICKET_CREATE"},
+		{tracGroup, "TICKET_MODIFY"},
+		{tracGroupMember, tracGroup},
+		{tracAdminUser, tracGroup},
+		{tracAdminUser, "TRAC_ADMIN"},
+		{tracAdminUser, "WIKI_CREATE"},
+		{tracViewerUser, "WIKI_VIEW"},
+		{tracViewerUser, "CUSTOM_PLUGIN_ACTION"},
+		{unmappedPermissionUser, "TICKET_MODIFY"},
+		{tracAuthenticatedUser, "CUSTOM_PLUGIN_ACTION"},
+	}
+
+	mockTracAccessor.
+		EXPECT().
+		GetPermissions(gomock.Any()).
+		DoAndReturn(func(handlerFn func(subject string, action string) error) error {
+			for _, permission := range permissions {
+				handlerFn(permission[0], permission[1])
+			}
+			return nil
+		})
+}
+
+func expectPermissionUserLookups(t *testing.T) {
+	lookups := map[string]int64{giteaGroupMember: groupMemberUserID, giteaAdminUser: adminUserUserID, giteaViewerUser: viewerUserUserID, giteaAuthenticatedUser: authenticatedUserUserID}
+	for giteaUser, userID := range lookups {
+		mockGiteaAccessor.
+			EXPECT().
+			GetUserID(gomock.Eq(giteaUser)).
+			Return(userID, nil).
+			AnyTimes()
+	}
+}
+
+func expectRepoOwnership(t *testing.T, isOrganization bool) {
+	mockGiteaAccessor.
+		EXPECT().
+		IsRepoOwnedByOrganization().
+		Return(isOrganization, nil)
+}
+
+func expectRepoCollaborator(t *testing.T, userID int64, access gitea.AccessMode) {
+	mockGiteaAccessor.
+		EXPECT().
+		AddRepoCollaborator(gomock.Eq(userID), gomock.Eq(access)).
+		Return(nil)
+}
+
+func TestDefaultPermissionMap(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	expectTracPermissionRetrievals(t)
+
+	defaultPermissionMap, err := dataImporter.DefaultPermissionMap()
+	assertTrue(t, err == nil)
+	assertEquals(t, defaultPermissionMap["TRAC_ADMIN"], importer.PermissionAdmin)
+	assertEquals(t, defaultPermissionMap["TICKET_MODIFY"], importer.PermissionWrite)
+	assertEquals(t, defaultPermissionMap["TICKET_CREATE"], importer.PermissionRead)
+	assertEquals(t, defaultPermissionMap["WIKI_CREATE"], importer.PermissionWrite)
+	assertEquals(t, defaultPermissionMap["WIKI_VIEW"], importer.PermissionRead)
+	assertEquals(t, defaultPermissionMap["CUSTOM_PLUGIN_ACTION"], importer.PermissionNone)
+	assertEquals(t, defaultPermissionMap["group:"+tracGroup], tracGroup)
+	assertEquals(t, len(defaultPermissionMap), 7)
+}
+
+func TestImportPermissionsAsCollaborators(t *testing.T) {
+	setUpPermissions(t)
+	defer tearDown(t)
+
+	expectTracPermissionRetrievals(t)
+	expectPermissionUserLookups(t)
+	expectRepoOwnership(t, false)
+
+	// expect each mapped user to become a collaborator with the highest access conferred by their own and their groups' permissions
+	// - the viewer's custom plugin permission confers nothing, the unmapped user is skipped and no teams are created
+	expectRepoCollaborator(t, groupMemberUserID, gitea.WriteAccess)
+	expectRepoCollaborator(t, adminUserUserID, gitea.AdminAccess)
+	expectRepoCollaborator(t, viewerUserUserID, gitea.ReadAccess)
+
+	// expect user with no permissions of their own to have those of every logged-in user
+	expectRepoCollaborator(t, authenticatedUserUserID, gitea.ReadAccess)
+
+	dataImporter.ImportPermissions(userMap, permissionMap)
+}
+
+func TestImportPermissionsAsTeams(t *testing.T) {
+	setUpPermissions(t)
+	defer tearDown(t)
+
+	expectTracPermissionRetrievals(t)
+	expectPermissionUserLookups(t)
+	expectRepoOwnership(t, true)
+
+	// expect Trac group to become a team with the access conferred by the group's permissions
+	mockGiteaAccessor.
+		EXPECT().
+		AddTeam(gomock.Any()).
+		DoAndReturn(func(team *gitea.Team) (int64, error) {
+			assertEquals(t, team.Name, giteaTeam)
+			assertEquals(t, team.Access, gitea.WriteAccess)
+			return teamID, nil
+		})
+
+	// expect both members of group to be added to team
+	mockGiteaAccessor.
+		EXPECT().
+		AddTeamMember(gomock.Eq(teamID), gomock.Eq(groupMemberUserID)).
+		Return(nil)
+	mockGiteaAccessor.
+		EXPECT().
+		AddTeamMember(gomock.Eq(teamID), gomock.Eq(adminUserUserID)).
+		Return(nil)
+
+	// expect only users with access beyond that conferred by their teams to become collaborators
+	expectRepoCollaborator(t, adminUserUserID, gitea.AdminAccess)
+	expectRepoCollaborator(t, viewerUserUserID, gitea.ReadAccess)
+	expectRepoCollaborator(t, authenticatedUserUserID, gitea.ReadAccess)
+
+	dataImporter.ImportPermissions(userMap, permissionMap)
+}
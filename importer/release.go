@@ -0,0 +1,100 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"time"
+
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// importRelease imports a single Gitea release, tagged with the release name, with a description already converted to Gitea markdown.
+// A release is only published if it has been released and there is a git tag of the same name for it to be attached to, otherwise it is created as a draft.
+func (importer *Importer) importRelease(name string, description string, releaseTime int64, draft bool) error {
+	isDraft := draft
+	if !isDraft && releaseTime == 0 {
+		log.Warn("%s has not been released - creating draft release rather than published release", name)
+		isDraft = true
+	}
+	if !isDraft {
+		hasTag, err := importer.giteaAccessor.HasTag(name)
+		if err != nil {
+			return err
+		}
+		if !hasTag {
+			log.Warn("no git tag %s found - creating draft release rather than published release %s", name, name)
+			isDraft = true
+		}
+	}
+
+	created := releaseTime
+	if created == 0 {
+		created = time.Now().Unix()
+	}
+
+	release := gitea.Release{
+		TagName:     name,
+		Title:       name,
+		Description: description,
+		PublisherID: importer.defaultAuthorID,
+		IsDraft:     isDraft,
+		Created:     created}
+	releaseID, err := importer.giteaAccessor.AddRelease(&release)
+	if err != nil {
+		return err
+	}
+
+	log.Debug("added release (id %d) %s", releaseID, name)
+	return nil
+}
+
+// ImportReleases imports each version defined in Trac as a Gitea release dated at the release time of the version.
+// If "milestoneReleases" is set, each completed Trac milestone is also imported as a release (under its mapped Gitea milestone name) dated at its completion time
+// - a milestone is skipped if there is already a version of the same name.
+// Releases are created as drafts if "draft" is set, otherwise they are published where possible.
+func (importer *Importer) ImportReleases(draft bool, milestoneReleases bool, milestoneMap map[string]string) error {
+	releaseNames := make(map[string]bool)
+	err := importer.tracAccessor.GetDefinedVersions(func(version *trac.Version) error {
+		if version.Name == "" {
+			return nil
+		}
+
+		releaseNames[version.Name] = true
+		description := importer.markdownConverter.ReleaseConvert(version.Description)
+		return importer.importRelease(version.Name, description, version.Time, draft)
+	})
+	if err != nil {
+		return err
+	}
+
+	if !milestoneReleases {
+		return nil
+	}
+
+	giteaMilestones, err := importer.getGiteaMilestones(milestoneMap)
+	if err != nil {
+		return err
+	}
+
+	for _, giteaMilestone := range giteaMilestones {
+		if !giteaMilestone.Closed {
+			continue
+		}
+		if releaseNames[giteaMilestone.Name] {
+			log.Debug("skipping release for milestone %s: already released as a version", giteaMilestone.Name)
+			continue
+		}
+
+		releaseNames[giteaMilestone.Name] = true
+		err = importer.importRelease(giteaMilestone.Name, giteaMilestone.Description, giteaMilestone.ClosedTime, draft)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+const (
+	taggedVersionName     = "1.0"
+	untaggedVersionName   = "1.1"
+	unreleasedVersionName = "2.0"
+
+	taggedVersionDescription     = "first release"
+	untaggedVersionDescription   = "bug fix release"
+	unreleasedVersionDescription = "next release"
+
+	taggedVersionTime   = int64(100000)
+	untaggedVersionTime = int64(200000)
+
+	taggedReleaseID     = int64(11)
+	untaggedReleaseID   = int64(12)
+	unreleasedReleaseID = int64(13)
+	milestoneReleaseID  = int64(14)
+)
+
+var (
+	tracUnnamedVersion    trac.Version
+	tracTaggedVersion     trac.Version
+	tracUntaggedVersion   trac.Version
+	tracUnreleasedVersion trac.Version
+)
+
+func setUpReleases(t *testing.T) {
+	setUp(t)
+
+	tracUnnamedVersion = trac.Version{Name: "", Description: "n/a", Time: 12345}
+	tracTaggedVersion = trac.Version{Name: taggedVersionName, Description: taggedVersionDescription, Time: taggedVersionTime}
+	tracUntaggedVersion = trac.Version{Name: untaggedVersionName, Description: untaggedVersionDescription, Time: untaggedVersionTime}
+	tracUnreleasedVersion = trac.Version{Name: unreleasedVersionName, Description: unreleasedVersionDescription, Time: 0}
+
+	// expect trac accessor to return each of our trac versions
+	mockTracAccessor.
+		EXPECT().
+		GetDefinedVersions(gomock.Any()).
+		DoAndReturn(func(handlerFn func(version *trac.Version) error) error {
+			handlerFn(&tracUnnamedVersion)
+			handlerFn(&tracTaggedVersion)
+			handlerFn(&tracUntaggedVersion)
+			handlerFn(&tracUnreleasedVersion)
+			return nil
+		})
+}
+
+func expectReleaseDescriptionConversion(t *testing.T, description string) string {
+	convertedDescription := "converted " + description
+	mockMarkdownConverter.
+		EXPECT().
+		ReleaseConvert(gomock.Eq(description)).
+		Return(convertedDescription)
+	return convertedDescription
+}
+
+func expectTagLookup(t *testing.T, tagName string, hasTag bool) {
+	mockGiteaAccessor.
+		EXPECT().
+		HasTag(gomock.Eq(tagName)).
+		Return(hasTag, nil)
+}
+
+// gomock Matcher for release tags
+type releaseTagMatcher struct{ tagName string }
+
+func isRelease(tagName string) gomock.Matcher {
+	return releaseTagMatcher{tagName: tagName}
+}
+
+func (matcher releaseTagMatcher) Matches(arg interface{}) bool {
+	giteaRelease := arg.(*gitea.Release)
+	return giteaRelease.TagName == matcher.tagName
+}
+
+func (matcher releaseTagMatcher) String() string {
+	return "is Gitea release " + matcher.tagName
+}
+
+func expectReleaseCreation(t *testing.T, name string, convertedDescription string, releaseTime int64, isDraft bool, releaseID int64) {
+	mockGiteaAccessor.
+		EXPECT().
+		AddRelease(isRelease(name)).
+		DoAndReturn(func(giteaRelease *gitea.Release) (int64, error) {
+			assertEquals(t, giteaRelease.Title, name)
+			assertEquals(t, giteaRelease.Description, convertedDescription)
+			assertEquals(t, giteaRelease.PublisherID, defaultUserID)
+			assertEquals(t, giteaRelease.IsDraft, isDraft)
+			if releaseTime != 0 {
+				assertEquals(t, giteaRelease.Created, releaseTime)
+			} else {
+				assertTrue(t, giteaRelease.Created != 0)
+			}
+			return releaseID, nil
+		})
+}
+
+func expectVersionReleaseCreation(t *testing.T, name string, description string, releaseTime int64, isDraft bool, releaseID int64) {
+	convertedDescription := expectReleaseDescriptionConversion(t, description)
+	expectReleaseCreation(t, name, convertedDescription, releaseTime, isDraft, releaseID)
+}
+
+func TestPublishedReleases(t *testing.T) {
+	setUpReleases(t)
+	defer tearDown(t)
+
+	// released versions are only published if they have a git tag, unreleased versions are always drafts
+	expectTagLookup(t, taggedVersionName, true)
+	expectVersionReleaseCreation(t, taggedVersionName, taggedVersionDescription, taggedVersionTime, false, taggedReleaseID)
+	expectTagLookup(t, untaggedVersionName, false)
+	expectVersionReleaseCreation(t, untaggedVersionName, untaggedVersionDescription, untaggedVersionTime, true, untaggedReleaseID)
+	expectVersionReleaseCreation(t, unreleasedVersionName, unreleasedVersionDescription, 0, true, unreleasedReleaseID)
+
+	dataImporter.ImportReleases(false, false, milestoneMap)
+}
+
+func TestDraftReleases(t *testing.T) {
+	setUpReleases(t)
+	defer tearDown(t)
+
+	expectVersionReleaseCreation(t, taggedVersionName, taggedVersionDescription, taggedVersionTime, true, taggedReleaseID)
+	expectVersionReleaseCreation(t, untaggedVersionName, untaggedVersionDescription, untaggedVersionTime, true, untaggedReleaseID)
+	expectVersionReleaseCreation(t, unreleasedVersionName, unreleasedVersionDescription, 0, true, unreleasedReleaseID)
+
+	dataImporter.ImportReleases(true, false, milestoneMap)
+}
+
+func TestMilestoneReleases(t *testing.T) {
+	setUpReleases(t)
+	defer tearDown(t)
+
+	// only completed milestones are released - under their mapped names and at their completion times
+	mockTracAccessor.
+		EXPECT().
+		GetMilestones(gomock.Any()).
+		DoAndReturn(func(handlerFn func(milestone *trac.Milestone) error) error {
+			handlerFn(&trac.Milestone{Name: "m1", Description: "first milestone", Completed: 300000})
+			handlerFn(&trac.Milestone{Name: "m2", Description: "second milestone", Completed: 0})
+			handlerFn(&trac.Milestone{Name: "m3", Description: "milestone released as version", Completed: 400000})
+			return nil
+		})
+	releaseMilestoneMap := map[string]string{"m1": "Milestone 1", "m2": "Milestone 2", "m3": taggedVersionName}
+
+	expectVersionReleaseCreation(t, taggedVersionName, taggedVersionDescription, taggedVersionTime, true, taggedReleaseID)
+	expectVersionReleaseCreation(t, untaggedVersionName, untaggedVersionDescription, untaggedVersionTime, true, untaggedReleaseID)
+	expectVersionReleaseCreation(t, unreleasedVersionName, unreleasedVersionDescription, 0, true, unreleasedReleaseID)
+	// milestone releases have the milestone descriptions converted in the context of their Trac milestones
+	expectMilestoneDescriptionConversion(t, "m2", "second milestone")
+	expectMilestoneDescriptionConversion(t, "m3", "milestone released as version")
+	convertedDescription := expectMilestoneDescriptionConversion(t, "m1", "first milestone")
+	expectReleaseCreation(t, "Milestone 1", convertedDescription, 300000, true, milestoneReleaseID)
+
+	dataImporter.ImportReleases(true, true, releaseMilestoneMap)
+}
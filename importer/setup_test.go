@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
 	"github.com/stevejefferson/trac2gitea/accessor/mock_gitea"
 	"github.com/stevejefferson/trac2gitea/accessor/mock_trac"
 	"github.com/stevejefferson/trac2gitea/importer"
@@ -41,8 +42,8 @@ func setUp(t *testing.T) {
 
 	// create importers to be tested - as part of this we must expect the default user to be validated
 	expectLookupOfDefaultUser(t)
-	dataImporter, _ = importer.CreateImporter(mockTracAccessor, mockGiteaAccessor, mockMarkdownConverter, defaultUser, false)
-	predefinedPageDataImporter, _ = importer.CreateImporter(mockTracAccessor, mockGiteaAccessor, mockMarkdownConverter, defaultUser, true)
+	dataImporter, _ = importer.CreateImporter(mockTracAccessor, mockGiteaAccessor, mockMarkdownConverter, defaultUser, false, gitea.NullID)
+	predefinedPageDataImporter, _ = importer.CreateImporter(mockTracAccessor, mockGiteaAccessor, mockMarkdownConverter, defaultUser, true, gitea.NullID)
 }
 
 func tearDown(t *testing.T) {
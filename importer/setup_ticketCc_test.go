@@ -0,0 +1,68 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+/*
+ * Set up for ticket/issue Cc parts of ticket tests.
+ * Contains:
+ * - ticket Cc users
+ * - expectations for use with ticket Cc lists and their changes.
+ */
+
+var (
+	ccUser1        *TicketUserImport
+	ccUser2        *TicketUserImport
+	ccUser3        *TicketUserImport
+	unmappedCcUser *TicketUserImport
+)
+
+var (
+	ccChangeAuthor *TicketUserImport
+)
+
+var (
+	ccTicketChange *TicketChangeImport
+)
+
+func createCcTicketChangeImport(author *TicketUserImport, prevCc string, cc string) *TicketChangeImport {
+	return &TicketChangeImport{
+		tracChangeType: trac.TicketCcChange,
+		issueCommentID: allocateID(),
+		author:         author,
+		prevValue:      prevCc,
+		value:          cc,
+		time:           allocateUnixTime(),
+	}
+}
+
+func setUpTicketCc(t *testing.T) {
+	ccUser1 = createTicketUserImport("trac-cc-user1", "gitea-cc-user1")
+	ccUser2 = createTicketUserImport("trac-cc-user2", "gitea-cc-user2")
+	ccUser3 = createTicketUserImport("trac-cc-user3", "gitea-cc-user3")
+	unmappedCcUser = createTicketUserImport("unmapped-cc-user@example.com", "")
+
+	ccChangeAuthor = createTicketUserImport("trac-cc-change-author", "gitea-cc-change-author")
+
+	// change removes user 1, retains user 2 and adds user 3 and an unmapped user - using several of Trac's Cc separators
+	ccTicketChange = createCcTicketChangeImport(ccChangeAuthor,
+		ccUser1.tracUser+", "+ccUser2.tracUser,
+		ccUser2.tracUser+"; "+ccUser3.tracUser+" "+unmappedCcUser.tracUser)
+}
+
+func expectIssueWatchToBeSet(t *testing.T, ticket *TicketImport, user *TicketUserImport, isWatching bool, time int64) {
+	expectUserLookup(t, user)
+
+	mockGiteaAccessor.
+		EXPECT().
+		SetIssueWatch(gomock.Eq(ticket.issueID), gomock.Eq(user.giteaUserID), gomock.Eq(isWatching), gomock.Eq(time)).
+		Return(nil)
+}
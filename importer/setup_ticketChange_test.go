@@ -32,8 +32,13 @@ type TicketChangeImport struct {
 	isClose        bool
 	prevSummary    string
 	summary        string
+	customField    *trac.CustomField
+	prevValue      string
+	value          string
 	text           string
 	markdownText   string
+	commentEdits   []trac.TicketCommentEdit
+	initial        bool
 	time           int64
 }
 
@@ -50,6 +55,7 @@ func createTracTicketChange(ticket *TicketImport, ticketChange *TicketChangeImpo
 	newValue := ""
 	switch ticketChange.tracChangeType {
 	case trac.TicketCommentChange:
+		oldValue = ticketChange.prevValue
 		newValue = ticketChange.text
 	case trac.TicketComponentChange:
 		fallthrough
@@ -71,7 +77,10 @@ func createTracTicketChange(ticket *TicketImport, ticketChange *TicketChangeImpo
 		oldValue = ticketChange.prevOwner.tracUser
 		newValue = ticketChange.owner.tracUser
 	case trac.TicketStatusChange:
-		if ticketChange.isClose {
+		if ticketChange.label != nil {
+			oldValue = tracTicketChangeLabelName(ticketChange.prevLabel)
+			newValue = ticketChange.label.tracName
+		} else if ticketChange.isClose {
 			newValue = trac.TicketStatusClosed
 		} else {
 			newValue = trac.TicketStatusReopened
@@ -79,14 +88,26 @@ func createTracTicketChange(ticket *TicketImport, ticketChange *TicketChangeImpo
 	case trac.TicketSummaryChange:
 		oldValue = ticketChange.prevSummary
 		newValue = ticketChange.summary
+	case trac.TicketCcChange:
+		fallthrough
+	case trac.TicketDescriptionChange:
+		fallthrough
+	case trac.TicketKeywordsChange:
+		fallthrough
+	case trac.TicketCustomFieldChange:
+		oldValue = ticketChange.prevValue
+		newValue = ticketChange.value
 	}
 	tracChange := trac.TicketChange{
-		TicketID:   ticket.ticketID,
-		ChangeType: ticketChange.tracChangeType,
-		Author:     ticketChange.author.tracUser,
-		OldValue:   oldValue,
-		NewValue:   newValue,
-		Time:       ticketChange.time,
+		TicketID:     ticket.ticketID,
+		ChangeType:   ticketChange.tracChangeType,
+		CustomField:  ticketChange.customField,
+		Author:       ticketChange.author.tracUser,
+		OldValue:     oldValue,
+		NewValue:     newValue,
+		Time:         ticketChange.time,
+		CommentEdits: ticketChange.commentEdits,
+		Initial:      ticketChange.initial,
 	}
 
 	return &tracChange
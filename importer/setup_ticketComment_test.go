@@ -27,6 +27,8 @@ var (
 	openTicketComment2Author            *TicketUserImport
 	noTracUserTicketCommentAuthor       *TicketUserImport
 	unmappedTracUserTicketCommentAuthor *TicketUserImport
+	numericTracUserTicketCommentAuthor  *TicketUserImport
+	ticketCommentEditor                 *TicketUserImport
 )
 
 func setUpTicketCommentUsers(t *testing.T) {
@@ -36,6 +38,8 @@ func setUpTicketCommentUsers(t *testing.T) {
 	openTicketComment2Author = createTicketUserImport("trac-open-ticket-comment2-author", "gitea-open-ticket-comment2-author")
 	noTracUserTicketCommentAuthor = createTicketUserImport("", "")
 	unmappedTracUserTicketCommentAuthor = createTicketUserImport("trac-unmapped-user-ticket-comment-author", "")
+	numericTracUserTicketCommentAuthor = createTicketUserImport("4321", "")
+	ticketCommentEditor = createTicketUserImport("trac-ticket-comment-editor", "gitea-ticket-comment-editor")
 }
 
 var (
@@ -45,6 +49,15 @@ var (
 	openTicketComment2            *TicketChangeImport
 	noTracUserTicketComment       *TicketChangeImport
 	unmappedTracUserTicketComment *TicketChangeImport
+	numericTracUserTicketComment  *TicketChangeImport
+	editedTicketComment           *TicketChangeImport
+	replyTicketComment            *TicketChangeImport
+	tracReplyTicketComment        *TicketChangeImport
+)
+
+const (
+	originalCommentText         = "original ticket comment text"
+	originalCommentMarkdownText = "original ticket comment text after conversion to markdown"
 )
 
 func createCommentTicketChangeImport(prefix string, author *TicketUserImport) *TicketChangeImport {
@@ -70,15 +83,44 @@ func setUpTicketComments(t *testing.T) {
 
 	noTracUserTicketComment = createCommentTicketChangeImport("no-trac-user-ticket-comment", noTracUserTicketCommentAuthor)
 	unmappedTracUserTicketComment = createCommentTicketChangeImport("unmapped-trac-user-ticket-comment", unmappedTracUserTicketCommentAuthor)
+	numericTracUserTicketComment = createCommentTicketChangeImport("numeric-trac-user-ticket-comment", numericTracUserTicketCommentAuthor)
+
+	editedTicketComment = createCommentTicketChangeImport("edited-ticket-comment", openTicketComment1Author)
+	editedTicketComment.commentEdits = []trac.TicketCommentEdit{
+		{Author: ticketCommentEditor.tracUser, OldText: originalCommentText, Time: allocateUnixTime()},
+	}
+
+	// replies to comment 1 (which is openTicketComment1) - one without and one with the reply text Trac adds itself
+	openTicketComment1.prevValue = "1"
+	replyTicketComment = createCommentTicketChangeImport("reply-ticket-comment", openTicketComment2Author)
+	replyTicketComment.prevValue = "1.2"
+	tracReplyTicketComment = createCommentTicketChangeImport("trac-reply-ticket-comment", openTicketComment2Author)
+	tracReplyTicketComment.prevValue = "1.3"
+	tracReplyTicketComment.text = "Replying to [comment:1 " + openTicketComment1Author.tracUser + "]:\n> " + openTicketComment1.text + "\n\n" + tracReplyTicketComment.text
+}
+
+func expectTracCommentRetrieval(t *testing.T, ticket *TicketImport, commentNum int64, ticketComment *TicketChangeImport) {
+	mockTracAccessor.
+		EXPECT().
+		GetTicketComment(gomock.Eq(ticket.ticketID), gomock.Eq(commentNum)).
+		Return(createTracTicketChange(ticket, ticketComment), nil)
 }
 
 func expectIssueCommentCreationForComment(t *testing.T, ticket *TicketImport, ticketComment *TicketChangeImport) {
+	// expect to record original trac user (and any numeric original author id) where comment author has no Gitea mapping
+	originalAuthorName := ""
+	if ticketComment.author.giteaUser == "" {
+		originalAuthorName = ticketComment.author.tracUser
+	}
+
 	mockGiteaAccessor.
 		EXPECT().
 		AddIssueComment(gomock.Eq(ticket.issueID), gomock.Any()).
 		DoAndReturn(func(issueID int64, issueComment *gitea.IssueComment) (int64, error) {
 			assertEquals(t, issueComment.CommentType, gitea.CommentIssueCommentType)
 			assertEquals(t, issueComment.AuthorID, ticketComment.author.giteaUserID)
+			assertEquals(t, issueComment.OriginalAuthorName, originalAuthorName)
+			assertEquals(t, issueComment.OriginalAuthorID, originalAuthorID(originalAuthorName))
 			assertEquals(t, issueComment.Text, ticketComment.markdownText)
 			assertEquals(t, issueComment.Time, ticketComment.time)
 			return ticketComment.issueCommentID, nil
@@ -0,0 +1,125 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/importer"
+)
+
+/*
+ * Set up for ticket/issue custom field parts of ticket tests.
+ * Contains:
+ * - custom fields and associated data (users, labels etc.)
+ * - expectations for use with ticket custom fields and their changes.
+ */
+
+var (
+	labelCustomField    *trac.CustomField
+	metadataCustomField *trac.CustomField
+	ignoredCustomField  *trac.CustomField
+)
+
+var (
+	customFieldLabel1 *TicketLabelImport
+	customFieldLabel2 *TicketLabelImport
+)
+
+var (
+	customFieldChangeAuthor *TicketUserImport
+)
+
+var (
+	labelCustomFieldTicketChange           *TicketChangeImport
+	metadataCustomFieldTicketChange        *TicketChangeImport
+	metadataCustomFieldInitialTicketChange *TicketChangeImport
+	ignoredCustomFieldTicketChange         *TicketChangeImport
+)
+
+const (
+	labelCustomFieldValue1 = "label-value1"
+	labelCustomFieldValue2 = "label-value2"
+	metadataFieldValue1    = "metadata-value1"
+	metadataFieldValue2    = "metadata-value2"
+	ignoredFieldValue      = "ignored-value"
+)
+
+func createCustomFieldLabelImport(field *trac.CustomField, value string) *TicketLabelImport {
+	ticketLabel := TicketLabelImport{
+		tracName:          field.Name + ":" + value,
+		giteaLabelName:    field.Name + "/" + value,
+		giteaLabelID:      allocateID(),
+		giteaIssueLabelID: allocateID(),
+	}
+
+	customFieldMap[ticketLabel.tracName] = ticketLabel.giteaLabelName
+	return &ticketLabel
+}
+
+func createCustomFieldTicketChangeImport(author *TicketUserImport, field *trac.CustomField, prevValue string, value string) *TicketChangeImport {
+	return &TicketChangeImport{
+		tracChangeType: trac.TicketCustomFieldChange,
+		issueCommentID: allocateID(),
+		author:         author,
+		customField:    field,
+		prevValue:      prevValue,
+		value:          value,
+		time:           allocateUnixTime(),
+	}
+}
+
+func setUpTicketCustomFields(t *testing.T) {
+	labelCustomField = &trac.CustomField{Name: "labelfield", Label: "Label Field", Type: "select", Options: []string{labelCustomFieldValue1, labelCustomFieldValue2}}
+	metadataCustomField = &trac.CustomField{Name: "metadatafield", Label: "Metadata Field", Type: "text"}
+	ignoredCustomField = &trac.CustomField{Name: "ignoredfield", Label: "Ignored Field", Type: "text"}
+
+	customFieldMap[labelCustomField.Name] = importer.CustomFieldLabel
+	customFieldMap[metadataCustomField.Name] = importer.CustomFieldMetadata
+	customFieldMap[ignoredCustomField.Name] = importer.CustomFieldIgnore
+
+	customFieldLabel1 = createCustomFieldLabelImport(labelCustomField, labelCustomFieldValue1)
+	customFieldLabel2 = createCustomFieldLabelImport(labelCustomField, labelCustomFieldValue2)
+
+	customFieldChangeAuthor = createTicketUserImport("trac-custom-field-change-author", "gitea-custom-field-change-author")
+
+	labelCustomFieldTicketChange = createCustomFieldTicketChangeImport(customFieldChangeAuthor, labelCustomField, labelCustomFieldValue1, labelCustomFieldValue2)
+	labelCustomFieldTicketChange.prevLabel = customFieldLabel1
+	labelCustomFieldTicketChange.label = customFieldLabel2
+	metadataCustomFieldTicketChange = createCustomFieldTicketChangeImport(customFieldChangeAuthor, metadataCustomField, metadataFieldValue1, metadataFieldValue2)
+	metadataCustomFieldInitialTicketChange = createCustomFieldTicketChangeImport(customFieldChangeAuthor, metadataCustomField, "", metadataFieldValue1)
+	metadataCustomFieldInitialTicketChange.initial = true
+	ignoredCustomFieldTicketChange = createCustomFieldTicketChangeImport(customFieldChangeAuthor, ignoredCustomField, "", ignoredFieldValue)
+}
+
+// addTicketCustomFields adds values for all of our custom fields to a ticket
+func addTicketCustomFields(ticket *TicketImport) {
+	ticket.customFields = []trac.TicketCustomField{
+		{Field: labelCustomField, Value: labelCustomFieldValue1},
+		{Field: metadataCustomField, Value: metadataFieldValue1},
+		{Field: ignoredCustomField, Value: ignoredFieldValue},
+	}
+	ticket.metadataTable = "| Field | Value |\n| --- | --- |\n| " + metadataCustomField.Label + " | " + metadataFieldValue1 + " |\n\n"
+}
+
+func expectIssueCommentCreationForCustomFieldMetadataChange(t *testing.T, ticket *TicketImport, ticketChange *TicketChangeImport, expectedText string) {
+	mockGiteaAccessor.
+		EXPECT().
+		AddIssueComment(gomock.Eq(ticket.issueID), gomock.Any()).
+		DoAndReturn(func(issueID int64, issueComment *gitea.IssueComment) (int64, error) {
+			assertEquals(t, issueComment.CommentType, gitea.CommentIssueCommentType)
+			assertEquals(t, issueComment.AuthorID, ticketChange.author.giteaUserID)
+			assertEquals(t, issueComment.Text, expectedText)
+			assertEquals(t, issueComment.Time, ticketChange.time)
+			return ticketChange.issueCommentID, nil
+		})
+
+	if ticketChange.author.giteaUser != "" {
+		expectIssueParticipantToBeAdded(t, ticket, ticketChange.author)
+	}
+}
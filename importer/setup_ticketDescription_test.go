@@ -0,0 +1,75 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+/*
+ * Set up for ticket/issue description parts of ticket tests.
+ * Contains:
+ * - ticket description changes and associated data (users etc.)
+ * - expectations for use with ticket description edit history.
+ */
+
+var (
+	descriptionChangeAuthor *TicketUserImport
+)
+
+var (
+	descriptionTicketChange *TicketChangeImport
+)
+
+const (
+	originalDescription         = "original description"
+	originalDescriptionMarkdown = "original description markdown"
+	editedDescription           = "edited description"
+	editedDescriptionMarkdown   = "edited description markdown"
+)
+
+func createDescriptionTicketChangeImport(author *TicketUserImport, prevDescription string, description string) *TicketChangeImport {
+	return &TicketChangeImport{
+		tracChangeType: trac.TicketDescriptionChange,
+		issueCommentID: allocateID(),
+		author:         author,
+		prevValue:      prevDescription,
+		value:          description,
+		time:           allocateUnixTime(),
+	}
+}
+
+func setUpTicketDescriptions(t *testing.T) {
+	descriptionChangeAuthor = createTicketUserImport("trac-description-change-author", "gitea-description-change-author")
+
+	descriptionTicketChange = createDescriptionTicketChangeImport(descriptionChangeAuthor, originalDescription, editedDescription)
+}
+
+// expectTextMarkdownConversion expects a specific piece of ticket text to be converted to markdown
+// - this must be set up before any more general expectations of ticket markdown conversion
+func expectTextMarkdownConversion(t *testing.T, ticket *TicketImport, text string, markdownText string) {
+	mockMarkdownConverter.
+		EXPECT().
+		TicketConvert(gomock.Eq(ticket.ticketID), gomock.Eq(text)).
+		Return(markdownText)
+}
+
+func expectIssueContentHistoryCreation(t *testing.T, ticket *TicketImport, commentID int64, author *TicketUserImport, text string, time int64, isFirstCreated bool) {
+	mockGiteaAccessor.
+		EXPECT().
+		AddIssueContentHistory(gomock.Eq(ticket.issueID), gomock.Any()).
+		DoAndReturn(func(issueID int64, history *gitea.IssueContentHistory) (int64, error) {
+			assertEquals(t, history.CommentID, commentID)
+			assertEquals(t, history.AuthorID, author.giteaUserID)
+			assertEquals(t, history.Text, text)
+			assertEquals(t, history.Time, time)
+			assertEquals(t, history.IsFirstCreated, isFirstCreated)
+			return allocateID(), nil
+		})
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+/*
+ * Set up for ticket/issue keyword parts of ticket tests.
+ * Contains:
+ * - ticket keywords and associated data (users, labels etc.)
+ * - expectations for use with ticket keyword changes.
+ */
+
+var (
+	keywordLabel1 *TicketLabelImport
+	keywordLabel2 *TicketLabelImport
+	keywordLabel3 *TicketLabelImport
+)
+
+var (
+	keywordsChangeAuthor *TicketUserImport
+)
+
+var (
+	keywordsTicketChange *TicketChangeImport
+)
+
+func createKeywordsTicketChangeImport(author *TicketUserImport, prevKeywords string, keywords string) *TicketChangeImport {
+	return &TicketChangeImport{
+		tracChangeType: trac.TicketKeywordsChange,
+		issueCommentID: allocateID(),
+		author:         author,
+		prevValue:      prevKeywords,
+		value:          keywords,
+		time:           allocateUnixTime(),
+	}
+}
+
+func setUpTicketKeywords(t *testing.T) {
+	keywordLabel1 = createTicketLabelImport("keyword1", keywordMap)
+	keywordLabel2 = createTicketLabelImport("keyword2", keywordMap)
+	keywordLabel3 = createTicketLabelImport("keyword3", keywordMap)
+
+	keywordsChangeAuthor = createTicketUserImport("trac-keywords-change-author", "gitea-keywords-change-author")
+
+	// change removes keyword 1, retains keyword 2 and adds keyword 3 - using both of Trac's keyword separators
+	keywordsTicketChange = createKeywordsTicketChangeImport(keywordsChangeAuthor,
+		keywordLabel1.tracName+" "+keywordLabel2.tracName,
+		keywordLabel2.tracName+", "+keywordLabel3.tracName)
+}
+
+func expectAllTicketKeywordsActions(t *testing.T, ticket *TicketImport, keywordsChange *TicketChangeImport, removedLabels []*TicketLabelImport, addedLabels []*TicketLabelImport) {
+	// expect to lookup Gitea equivalent of author of Trac ticket change
+	expectUserLookup(t, keywordsChange.author)
+
+	// expect an issue comment to remove each removed keyword label and to add each added keyword label
+	for _, removedLabel := range removedLabels {
+		expectIssueCommentCreationForLabelChange(t, ticket, keywordsChange, removedLabel, false)
+	}
+	for _, addedLabel := range addedLabels {
+		expectIssueCommentCreationForLabelChange(t, ticket, keywordsChange, addedLabel, true)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+)
+
+/*
+ * Set up for label map targets other than a single label in ticket tests.
+ * Contains:
+ * - additional labels, default assignees and milestones for Trac components and versions
+ * - expectations for use with these.
+ */
+
+var (
+	extraComponentLabel *TicketLabelImport
+
+	component1Assignee *TicketUserImport
+	component2Assignee *TicketUserImport
+
+	version1Milestone *TicketMilestoneImport
+	version2Milestone *TicketMilestoneImport
+)
+
+func setUpTicketLabelMappings(t *testing.T) {
+	extraComponentLabel = createTicketLabelImport("extra-component", make(map[string]string))
+
+	// default assignees are Gitea users: they do not appear in the user map
+	component1Assignee = createTicketUserImport("", "gitea-component1-assignee")
+	component2Assignee = createTicketUserImport("", "gitea-component2-assignee")
+
+	// version milestones are Gitea milestones: they do not appear in the milestone map
+	version1Milestone = &TicketMilestoneImport{milestoneName: "version1-milestone", milestoneID: allocateID()}
+	version2Milestone = &TicketMilestoneImport{milestoneName: "version2-milestone", milestoneID: allocateID()}
+}
+
+// addLabelMapping adds a comma-separated target to the label map entry for a Trac label
+func addLabelMapping(labelMap map[string]string, label *TicketLabelImport, target string) {
+	labelMap[label.tracName] = labelMap[label.tracName] + ", " + target
+}
+
+func expectDefaultAssigneeLookup(t *testing.T, assignee *TicketUserImport) {
+	mockGiteaAccessor.
+		EXPECT().
+		GetUserID(gomock.Eq(assignee.giteaUser)).
+		Return(assignee.giteaUserID, nil).
+		AnyTimes()
+}
+
+func expectDefaultAssigneeToBeAdded(t *testing.T, ticket *TicketImport, assignee *TicketUserImport) {
+	expectDefaultAssigneeLookup(t, assignee)
+	expectIssueAssigneeToBeAdded(t, ticket, assignee)
+	expectIssueParticipantToBeAdded(t, ticket, assignee)
+}
+
+func expectIssueCommentCreationForDefaultAssigneeChange(t *testing.T, ticket *TicketImport, ticketLabelChange *TicketChangeImport, prevAssignee *TicketUserImport, assignee *TicketUserImport) {
+	expectDefaultAssigneeLookup(t, prevAssignee)
+	expectDefaultAssigneeLookup(t, assignee)
+
+	mockGiteaAccessor.
+		EXPECT().
+		AddIssueComment(gomock.Eq(ticket.issueID), gomock.Any()).
+		DoAndReturn(func(issueID int64, issueComment *gitea.IssueComment) (int64, error) {
+			assertEquals(t, issueComment.CommentType, gitea.AssigneeIssueCommentType)
+			assertEquals(t, issueComment.AuthorID, ticketLabelChange.author.giteaUserID)
+			assertEquals(t, issueComment.AssigneeID, assignee.giteaUserID)
+			assertEquals(t, issueComment.Time, ticketLabelChange.time)
+			return ticketLabelChange.issueCommentID, nil
+		})
+
+	if ticketLabelChange.author.giteaUser != "" {
+		expectIssueParticipantToBeAdded(t, ticket, ticketLabelChange.author)
+	}
+}
+
+func expectIssueCommentCreationForVersionMilestoneChange(t *testing.T, ticket *TicketImport, ticketLabelChange *TicketChangeImport, prevMilestone *TicketMilestoneImport, milestone *TicketMilestoneImport) {
+	expectTicketMilestoneRetrieval(t, prevMilestone)
+	expectTicketMilestoneRetrieval(t, milestone)
+
+	mockGiteaAccessor.
+		EXPECT().
+		AddIssueComment(gomock.Eq(ticket.issueID), gomock.Any()).
+		DoAndReturn(func(issueID int64, issueComment *gitea.IssueComment) (int64, error) {
+			assertEquals(t, issueComment.CommentType, gitea.MilestoneIssueCommentType)
+			assertEquals(t, issueComment.AuthorID, ticketLabelChange.author.giteaUserID)
+			assertEquals(t, issueComment.OldMilestoneID, prevMilestone.milestoneID)
+			assertEquals(t, issueComment.MilestoneID, milestone.milestoneID)
+			assertEquals(t, issueComment.Time, ticketLabelChange.time)
+			return ticketLabelChange.issueCommentID, nil
+		})
+
+	if ticketLabelChange.author.giteaUser != "" {
+		expectIssueParticipantToBeAdded(t, ticket, ticketLabelChange.author)
+	}
+}
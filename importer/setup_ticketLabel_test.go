@@ -23,9 +23,11 @@ import (
 // TicketLabelImport holds the data on a label associated with an imported ticket label
 type TicketLabelImport struct {
 	tracName          string
+	tracPosition      int64
 	giteaLabelName    string
 	giteaLabelID      int64
 	giteaIssueLabelID int64
+	issuePriority     int64
 }
 
 func createTicketLabelImport(prefix string, ticketLabelMap map[string]string) *TicketLabelImport {
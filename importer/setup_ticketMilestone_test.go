@@ -34,6 +34,7 @@ type TicketMilestoneImport struct {
 }
 
 func createTicketMilestoneImport(name string) *TicketMilestoneImport {
+	milestoneMap[name] = name
 	return &TicketMilestoneImport{milestoneName: name, milestoneID: allocateID()}
 }
 
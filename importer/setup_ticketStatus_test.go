@@ -10,6 +10,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stevejefferson/trac2gitea/accessor/gitea"
 	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/importer"
 )
 
 /*
@@ -39,15 +40,59 @@ func createCloseTicketChangeImport(author *TicketUserImport, isClose bool) *Tick
 	}
 }
 
+// createTicketStatusImport creates a custom Trac workflow status mapped onto an open or closed Gitea issue with a label
+func createTicketStatusImport(prefix string, closed bool) *TicketLabelImport {
+	statusLabelMap := make(map[string]string)
+	status := createTicketLabelImport(prefix, statusLabelMap)
+	statusMap[status.tracName] = &importer.StatusMapping{Closed: closed, Label: status.giteaLabelName}
+	return status
+}
+
+func createWorkflowTicketChangeImport(author *TicketUserImport, prevStatus *TicketLabelImport, status *TicketLabelImport, isClose bool) *TicketChangeImport {
+	return &TicketChangeImport{
+		tracChangeType: trac.TicketStatusChange,
+		issueCommentID: allocateID(),
+		author:         author,
+		time:           allocateUnixTime(),
+		prevLabel:      prevStatus,
+		label:          status,
+		isClose:        isClose,
+	}
+}
+
+var (
+	acceptedStatus *TicketLabelImport
+	testingStatus  *TicketLabelImport
+	verifiedStatus *TicketLabelImport
+)
+
 var (
 	closeTicketChange  *TicketChangeImport
 	reopenTicketChange *TicketChangeImport
+
+	testingTicketChange  *TicketChangeImport
+	verifiedTicketChange *TicketChangeImport
+
+	initialAcceptedTicketChange *TicketChangeImport
+	initialVerifiedTicketChange *TicketChangeImport
 )
 
 func setUpTicketStatusChanges(t *testing.T) {
 	setUpTicketStatusChangeUsers(t)
 	closeTicketChange = createCloseTicketChangeImport(closeStatusChangeAuthor, true)
 	reopenTicketChange = createCloseTicketChangeImport(reopenStatusChangeAuthor, false)
+
+	acceptedStatus = createTicketStatusImport("accepted", false)
+	testingStatus = createTicketStatusImport("testing", false)
+	verifiedStatus = createTicketStatusImport("verified", true)
+	testingTicketChange = createWorkflowTicketChangeImport(closeStatusChangeAuthor, acceptedStatus, testingStatus, false)
+	verifiedTicketChange = createWorkflowTicketChangeImport(closeStatusChangeAuthor, testingStatus, verifiedStatus, true)
+
+	// synthetic changes giving the initial status of a ticket
+	initialAcceptedTicketChange = createWorkflowTicketChangeImport(closeStatusChangeAuthor, nil, acceptedStatus, false)
+	initialAcceptedTicketChange.initial = true
+	initialVerifiedTicketChange = createWorkflowTicketChangeImport(closeStatusChangeAuthor, nil, verifiedStatus, true)
+	initialVerifiedTicketChange.initial = true
 }
 
 func expectIssueCommentCreationForStatusChange(t *testing.T, ticket *TicketImport, ticketStatus *TicketChangeImport) {
@@ -76,3 +121,17 @@ func expectAllTicketStatusActions(t *testing.T, ticket *TicketImport, ticketStat
 	// expect creation of issue comment for ticket status change
 	expectIssueCommentCreationForStatusChange(t, ticket, ticketStatus)
 }
+
+func expectAllTicketWorkflowStatusActions(t *testing.T, ticket *TicketImport, ticketStatus *TicketChangeImport, isStateChange bool) {
+	// expect to lookup Gitea equivalent of author of Trac ticket change
+	expectUserLookup(t, ticketStatus.author)
+
+	// expect issue to be closed or reopened if the change is between open and closed statuses
+	if isStateChange {
+		expectIssueCommentCreationForStatusChange(t, ticket, ticketStatus)
+	}
+
+	// expect issue comments to remove label of previous status and add label of new status
+	expectIssueCommentCreationForLabelChange(t, ticket, ticketStatus, ticketStatus.prevLabel, false)
+	expectIssueCommentCreationForLabelChange(t, ticket, ticketStatus, ticketStatus.label, true)
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+/*
+ * Set up for ticket/issue time tracking parts of ticket tests.
+ * Contains:
+ * - TimingAndEstimation plugin custom fields and associated data (users etc.)
+ * - expectations for use with ticket time tracking.
+ */
+
+var (
+	hoursCustomField          *trac.CustomField
+	estimatedHoursCustomField *trac.CustomField
+)
+
+var (
+	hoursChangeAuthor *TicketUserImport
+)
+
+var (
+	hoursTicketChange *TicketChangeImport
+)
+
+const (
+	estimatedHours        = "2.5"
+	estimatedHoursSeconds = int64(9000)
+	workedHours           = "1.25"
+	workedHoursSeconds    = int64(4500)
+)
+
+func setUpTicketTimes(t *testing.T) {
+	hoursCustomField = &trac.CustomField{Name: "hours", Label: "Add Hours to Ticket", Type: "text"}
+	estimatedHoursCustomField = &trac.CustomField{Name: "estimatedhours", Label: "Estimated Number of Hours", Type: "text"}
+
+	hoursChangeAuthor = createTicketUserImport("trac-hours-change-author", "gitea-hours-change-author")
+
+	hoursTicketChange = createCustomFieldTicketChangeImport(hoursChangeAuthor, hoursCustomField, "0", workedHours)
+}
+
+func expectIssueTimeEstimateToBeSet(t *testing.T, ticket *TicketImport, seconds int64) {
+	mockGiteaAccessor.
+		EXPECT().
+		SetIssueTimeEstimate(gomock.Eq(ticket.issueID), gomock.Eq(seconds)).
+		Return(nil)
+}
+
+func expectIssueTrackedTimeCreation(t *testing.T, ticket *TicketImport, ticketChange *TicketChangeImport, seconds int64) {
+	mockGiteaAccessor.
+		EXPECT().
+		AddIssueTrackedTime(gomock.Eq(ticket.issueID), gomock.Any()).
+		DoAndReturn(func(issueID int64, trackedTime *gitea.TrackedTime) error {
+			assertEquals(t, trackedTime.UserID, ticketChange.author.giteaUserID)
+			assertEquals(t, trackedTime.Seconds, seconds)
+			assertEquals(t, trackedTime.Time, ticketChange.time)
+			return nil
+		})
+}
@@ -5,6 +5,7 @@
 package importer_test
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -72,3 +73,13 @@ func expectIssueAssigneeToBeAdded(t *testing.T, ticket *TicketImport, user *Tick
 		AddIssueAssignee(gomock.Eq(ticket.issueID), gomock.Eq(user.giteaUserID)).
 		Return(nil)
 }
+
+// originalAuthorID returns the original author id expected to be recorded for an unmapped Trac user: only numeric Trac logins have one.
+func originalAuthorID(tracUser string) int64 {
+	id, err := strconv.ParseInt(tracUser, 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
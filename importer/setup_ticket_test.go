@@ -11,6 +11,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stevejefferson/trac2gitea/accessor/gitea"
 	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/importer"
 )
 
 /*
@@ -57,6 +58,13 @@ var (
 	severityMap   map[string]string
 	typeMap       map[string]string
 	versionMap    map[string]string
+	keywordMap    map[string]string
+
+	customFieldMap map[string]string
+
+	milestoneMap map[string]string
+
+	statusMap map[string]*importer.StatusMapping
 )
 
 func initMaps() {
@@ -66,6 +74,26 @@ func initMaps() {
 	severityMap = make(map[string]string)
 	typeMap = make(map[string]string)
 	versionMap = make(map[string]string)
+	keywordMap = make(map[string]string)
+	customFieldMap = make(map[string]string)
+	milestoneMap = make(map[string]string)
+	statusMap = make(map[string]*importer.StatusMapping)
+}
+
+// ticketMaps returns the maps currently set up for importing tickets.
+func ticketMaps() *importer.TicketMaps {
+	return &importer.TicketMaps{
+		UserMap:        userMap,
+		ComponentMap:   componentMap,
+		PriorityMap:    priorityMap,
+		ResolutionMap:  resolutionMap,
+		SeverityMap:    severityMap,
+		TypeMap:        typeMap,
+		VersionMap:     versionMap,
+		KeywordMap:     keywordMap,
+		CustomFieldMap: customFieldMap,
+		MilestoneMap:   milestoneMap,
+		StatusMap:      statusMap}
 }
 
 var (
@@ -77,6 +105,7 @@ var (
 	noTracUserTicketReporter       *TicketUserImport
 	unmappedTracUserTicketOwner    *TicketUserImport
 	unmappedTracUserTicketReporter *TicketUserImport
+	unmappedReporterTicketOwner    *TicketUserImport
 )
 
 func setUpTicketUsers(t *testing.T) {
@@ -88,6 +117,7 @@ func setUpTicketUsers(t *testing.T) {
 	noTracUserTicketReporter = createTicketUserImport("", "")
 	unmappedTracUserTicketOwner = createTicketUserImport("trac-unmapped-user-ticket-owner", "")
 	unmappedTracUserTicketReporter = createTicketUserImport("trac-unmapped-user-ticket-reporter", "")
+	unmappedReporterTicketOwner = createTicketUserImport("trac-unmapped-reporter-ticket-owner", "gitea-unmapped-reporter-ticket-owner")
 }
 
 var (
@@ -110,6 +140,8 @@ func setUpTicketLabels(t *testing.T) {
 	componentLabel2 = createTicketLabelImport("component2", componentMap)
 	priorityLabel1 = createTicketLabelImport("priority1", priorityMap)
 	priorityLabel2 = createTicketLabelImport("priority2", priorityMap)
+	priorityLabel1.tracPosition, priorityLabel1.issuePriority = 1, 2
+	priorityLabel2.tracPosition, priorityLabel2.issuePriority = 2, 1
 	resolutionLabel1 = createTicketLabelImport("resolution1", resolutionMap)
 	resolutionLabel2 = createTicketLabelImport("resolution2", resolutionMap)
 	severityLabel1 = createTicketLabelImport("severity1", severityMap)
@@ -127,17 +159,25 @@ type TicketImport struct {
 	summary             string
 	description         string
 	descriptionMarkdown string
+	originalDescription string
 	owner               *TicketUserImport
 	reporter            *TicketUserImport
 	milestoneName       string
+	versionMilestone    *TicketMilestoneImport
 	componentLabel      *TicketLabelImport
 	priorityLabel       *TicketLabelImport
 	resolutionLabel     *TicketLabelImport
 	severityLabel       *TicketLabelImport
 	typeLabel           *TicketLabelImport
 	versionLabel        *TicketLabelImport
+	keywords            string
+	cc                  string
+	unmappedCcList      string
+	customFields        []trac.TicketCustomField
+	metadataTable       string
 	closed              bool
 	status              string
+	statusLabel         *TicketLabelImport
 	created             int64
 	updated             int64
 }
@@ -158,6 +198,10 @@ func createTicketImport(
 		status = "closed"
 	}
 
+	// ticket milestones map onto Gitea milestones of the same name
+	milestoneName := prefix + "-milestone"
+	milestoneMap[milestoneName] = milestoneName
+
 	return &TicketImport{
 		ticketID:            allocateID(),
 		issueID:             allocateID(),
@@ -166,7 +210,7 @@ func createTicketImport(
 		descriptionMarkdown: prefix + "-markdown",
 		owner:               owner,
 		reporter:            reporter,
-		milestoneName:       prefix + "-milestone",
+		milestoneName:       milestoneName,
 		componentLabel:      componentLabel,
 		priorityLabel:       priorityLabel,
 		resolutionLabel:     resolutionLabel,
@@ -181,22 +225,32 @@ func createTicketImport(
 }
 
 func createTracTicket(ticket *TicketImport) *trac.Ticket {
+	// unless otherwise specified, ticket description has not been edited
+	originalDescription := ticket.originalDescription
+	if originalDescription == "" {
+		originalDescription = ticket.description
+	}
+
 	return &trac.Ticket{
-		TicketID:       ticket.ticketID,
-		Summary:        ticket.summary,
-		Description:    ticket.description,
-		Owner:          ticket.owner.tracUser,
-		Reporter:       ticket.reporter.tracUser,
-		MilestoneName:  ticket.milestoneName,
-		ComponentName:  ticket.componentLabel.tracName,
-		PriorityName:   ticket.priorityLabel.tracName,
-		ResolutionName: ticket.resolutionLabel.tracName,
-		SeverityName:   ticket.severityLabel.tracName,
-		TypeName:       ticket.typeLabel.tracName,
-		VersionName:    ticket.versionLabel.tracName,
-		Status:         ticket.status,
-		Created:        ticket.created,
-		Updated:        ticket.updated,
+		TicketID:            ticket.ticketID,
+		Summary:             ticket.summary,
+		Description:         ticket.description,
+		OriginalDescription: originalDescription,
+		Owner:               ticket.owner.tracUser,
+		Reporter:            ticket.reporter.tracUser,
+		MilestoneName:       ticket.milestoneName,
+		ComponentName:       ticket.componentLabel.tracName,
+		PriorityName:        ticket.priorityLabel.tracName,
+		ResolutionName:      ticket.resolutionLabel.tracName,
+		SeverityName:        ticket.severityLabel.tracName,
+		TypeName:            ticket.typeLabel.tracName,
+		VersionName:         ticket.versionLabel.tracName,
+		Status:              ticket.status,
+		Created:             ticket.created,
+		Updated:             ticket.updated,
+		Keywords:            ticket.keywords,
+		Cc:                  ticket.cc,
+		CustomFields:        ticket.customFields,
 	}
 }
 
@@ -205,6 +259,7 @@ var (
 	openTicket             *TicketImport
 	noTracUserTicket       *TicketImport
 	unmappedTracUserTicket *TicketImport
+	unmappedReporterTicket *TicketImport
 )
 
 // setUpTickets is the top-level setUp method for the ticket tests.
@@ -221,7 +276,13 @@ func setUpTickets(t *testing.T) {
 	setUpTicketOwnershipChanges(t)
 	setUpTicketStatusChanges(t)
 	setUpTicketSummaryChanges(t)
+	setUpTicketKeywords(t)
+	setUpTicketCc(t)
+	setUpTicketDescriptions(t)
+	setUpTicketTimes(t)
+	setUpTicketCustomFields(t)
 	setUpTicketAttachments(t)
+	setUpTicketLabelMappings(t)
 
 	closedTicket = createTicketImport(
 		"closed", true,
@@ -239,9 +300,24 @@ func setUpTickets(t *testing.T) {
 		"unmappedTracUser", false,
 		unmappedTracUserTicketOwner, unmappedTracUserTicketReporter,
 		componentLabel1, priorityLabel1, resolutionLabel1, severityLabel1, typeLabel1, versionLabel1)
+	unmappedReporterTicket = createTicketImport(
+		"unmappedReporter", false,
+		unmappedReporterTicketOwner, unmappedTracUserTicketReporter,
+		componentLabel1, priorityLabel1, resolutionLabel1, severityLabel1, typeLabel1, versionLabel1)
 }
 
 func expectTracTicketRetrievals(t *testing.T, tickets ...*TicketImport) {
+	// expect trac accessor to return priorities in order to determine issue priorities
+	mockTracAccessor.
+		EXPECT().
+		GetPriorities(gomock.Any()).
+		DoAndReturn(func(handlerFn func(priority *trac.Label) error) error {
+			for _, priorityLabel := range []*TicketLabelImport{priorityLabel1, priorityLabel2} {
+				handlerFn(&trac.Label{Name: priorityLabel.tracName, Position: priorityLabel.tracPosition})
+			}
+			return nil
+		})
+
 	// expect trac accessor to return each of our trac tickets
 	mockTracAccessor.
 		EXPECT().
@@ -266,10 +342,10 @@ func expectDescriptionMarkdownConversion(t *testing.T, ticket *TicketImport) {
 }
 
 func expectIssueCreation(t *testing.T, ticket *TicketImport) {
-	// expect to record original trac user where ticket owner has no Gitea mapping
+	// expect to record original trac user where ticket reporter has no Gitea mapping
 	originalAuthorName := ""
-	if ticket.owner.giteaUser == "" {
-		originalAuthorName = ticket.owner.tracUser
+	if ticket.reporter.giteaUser == "" {
+		originalAuthorName = ticket.reporter.tracUser
 	}
 
 	mockGiteaAccessor.
@@ -278,12 +354,17 @@ func expectIssueCreation(t *testing.T, ticket *TicketImport) {
 		DoAndReturn(func(issue *gitea.Issue) (int64, error) {
 			assertEquals(t, issue.Index, ticket.ticketID)
 			assertEquals(t, issue.Summary, ticket.summary)
-			assertEquals(t, issue.Description, ticket.descriptionMarkdown)
-			assertEquals(t, issue.OriginalAuthorID, gitea.NullID)
+			assertEquals(t, issue.Description, ticket.metadataTable+ticket.descriptionMarkdown+ticket.unmappedCcList)
+			assertEquals(t, issue.OriginalAuthorID, originalAuthorID(originalAuthorName))
 			assertEquals(t, issue.OriginalAuthorName, originalAuthorName)
 			assertEquals(t, issue.ReporterID, ticket.reporter.giteaUserID)
-			assertEquals(t, issue.Milestone, ticket.milestoneName)
+			expectedMilestoneName := milestoneMap[ticket.milestoneName]
+			if expectedMilestoneName == "" && ticket.versionMilestone != nil {
+				expectedMilestoneName = ticket.versionMilestone.milestoneName
+			}
+			assertEquals(t, issue.Milestone, expectedMilestoneName)
 			assertEquals(t, issue.Closed, ticket.closed)
+			assertEquals(t, issue.Priority, ticket.priorityLabel.issuePriority)
 			assertEquals(t, issue.Created, ticket.created)
 			return ticket.issueID, nil
 		})
@@ -350,4 +431,7 @@ func expectAllTicketActions(t *testing.T, ticket *TicketImport) {
 	expectIssueLabelCreation(t, ticket, ticket.severityLabel)
 	expectIssueLabelCreation(t, ticket, ticket.typeLabel)
 	expectIssueLabelCreation(t, ticket, ticket.versionLabel)
+	if ticket.statusLabel != nil {
+		expectIssueLabelCreation(t, ticket, ticket.statusLabel)
+	}
 }
@@ -0,0 +1,62 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+// label color for Trac statuses
+const statusLabelColor = "#c5def5"
+
+// StatusMapping describes the Gitea equivalent of a Trac ticket status.
+type StatusMapping struct {
+	Closed bool   // whether issues with the status are closed
+	Label  string // name of Gitea label given to issues with the status, "" if none
+}
+
+// DefaultStatusMap retrieves the default mapping of Trac ticket statuses onto Gitea:
+// only the Trac "closed" status closes an issue and no statuses have labels.
+func (importer *Importer) DefaultStatusMap() (map[string]*StatusMapping, error) {
+	statusMap := make(map[string]*StatusMapping)
+	err := importer.tracAccessor.GetStatuses(func(status *trac.Label) error {
+		statusMap[status.Name] = &StatusMapping{Closed: status.Name == trac.TicketStatusClosed, Label: ""}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return statusMap, nil
+}
+
+// getStatusMapping retrieves the Gitea equivalent of a Trac ticket status
+// - any status missing from the status map is treated as in the default mapping.
+func getStatusMapping(status string, statusMap map[string]*StatusMapping) *StatusMapping {
+	if statusMapping, found := statusMap[status]; found {
+		return statusMapping
+	}
+
+	return &StatusMapping{Closed: status == trac.TicketStatusClosed, Label: ""}
+}
+
+// statusLabelMap returns the mapping between Trac statuses and Gitea labels contained in a status map.
+func statusLabelMap(statusMap map[string]*StatusMapping) map[string]string {
+	labelMap := make(map[string]string)
+	for status, statusMapping := range statusMap {
+		labelMap[status] = statusMapping.Label
+	}
+
+	return labelMap
+}
+
+// ImportStatuses imports Trac ticket statuses with a Gitea label as Gitea labels.
+func (importer *Importer) ImportStatuses(statusMap map[string]*StatusMapping) error {
+	labelMap := statusLabelMap(statusMap)
+	return importer.tracAccessor.GetStatuses(func(status *trac.Label) error {
+		err := importer.importLabels(status, labelMap, statusLabelColor, true)
+		return err
+	})
+}
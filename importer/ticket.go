@@ -10,37 +10,54 @@ import (
 )
 
 // importTicket imports a Trac ticket as a Gitea issue, returning the id of the created issue or gitea.NullID if the issue was not created.
-func (importer *Importer) importTicket(ticket *trac.Ticket, closed bool, userMap map[string]string) (int64, error) {
-	reporterID, err := importer.getUserID(ticket.Reporter, userMap)
+// A ticket with no owner is assigned to the default assignee of its component and a ticket with no milestone is given the milestone onto which its version is mapped, if any.
+func (importer *Importer) importTicket(ticket *trac.Ticket, closed bool, priority int64, maps *TicketMaps) (int64, error) {
+	reporterID, err := importer.getUserID(ticket.Reporter, maps.UserMap)
 	if err != nil {
 		return gitea.NullID, err
 	}
+	// record Trac reporter as original author if it cannot be mapped onto a Gitea user
+	originalAuthorName := ""
 	if reporterID == gitea.NullID {
 		reporterID = importer.defaultAuthorID
+		originalAuthorName = ticket.Reporter
 	}
 
-	// record Trac owner as original author if it cannot be mapped onto a Gitea user
 	ownerID := gitea.NullID
-	originalAuthorName := ticket.Owner
 	if ticket.Owner != "" {
-		ownerID, err = importer.getUserID(ticket.Owner, userMap)
+		ownerID, err = importer.getUserID(ticket.Owner, maps.UserMap)
 		if err != nil {
 			return gitea.NullID, err
 		}
-		if ownerID != gitea.NullID {
-			originalAuthorName = ""
+	} else {
+		ownerID, err = importer.getDefaultAssigneeID(ticket.ComponentName, maps.ComponentMap)
+		if err != nil {
+			return gitea.NullID, err
 		}
 	}
 
-	convertedDescription := importer.markdownConverter.TicketConvert(ticket.TicketID, ticket.Description)
+	milestoneName := maps.MilestoneMap[ticket.MilestoneName]
+	if milestoneName == "" {
+		milestoneName = parseLabelMapping(maps.VersionMap[ticket.VersionName]).milestone
+	}
+
+	convertedDescription, err := importer.issueDescription(ticket, ticket.Description, maps)
+	if err != nil {
+		return gitea.NullID, err
+	}
 	issue := gitea.Issue{Index: ticket.TicketID, Summary: ticket.Summary, ReporterID: reporterID,
-		Milestone: ticket.MilestoneName, OriginalAuthorID: 0, OriginalAuthorName: originalAuthorName,
-		Closed: closed, Description: convertedDescription, Created: ticket.Created, Updated: ticket.Updated}
+		Milestone: milestoneName, OriginalAuthorID: tracUserOriginalAuthorID(originalAuthorName), OriginalAuthorName: originalAuthorName,
+		Closed: closed, Priority: priority, Description: convertedDescription, Created: ticket.Created, Updated: ticket.Updated}
 	issueID, err := importer.giteaAccessor.AddIssue(&issue)
 	if err != nil {
 		return gitea.NullID, err
 	}
 
+	err = importer.importOriginalDescription(issueID, ticket, reporterID, maps)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
 	// if we have a Gitea user for the Trac ticket owner then assign the Gitea issue to that user
 	if ownerID != gitea.NullID {
 		err = importer.giteaAccessor.AddIssueAssignee(issueID, ownerID)
@@ -64,12 +81,60 @@ func (importer *Importer) importTicket(ticket *trac.Ticket, closed bool, userMap
 	return issueID, nil
 }
 
+// issuePriorities retrieves the Gitea issue priority for each Trac priority, based on Trac's ordering of priorities:
+// the highest Trac priority has the highest Gitea priority and priorities not ordered by Trac have no Gitea priority.
+func (importer *Importer) issuePriorities() (map[string]int64, error) {
+	positions := make(map[string]int64)
+	maxPosition := int64(0)
+	err := importer.tracAccessor.GetPriorities(func(priority *trac.Label) error {
+		if priority.Position > 0 {
+			positions[priority.Name] = priority.Position
+			if priority.Position > maxPosition {
+				maxPosition = priority.Position
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	priorities := make(map[string]int64)
+	for priorityName, position := range positions {
+		priorities[priorityName] = maxPosition + 1 - position
+	}
+
+	return priorities, nil
+}
+
+// TicketMaps holds the maps from Trac names onto Gitea names used when importing tickets.
+type TicketMaps struct {
+	UserMap        map[string]string
+	ComponentMap   map[string]string
+	PriorityMap    map[string]string
+	ResolutionMap  map[string]string
+	SeverityMap    map[string]string
+	TypeMap        map[string]string
+	VersionMap     map[string]string
+	KeywordMap     map[string]string
+	CustomFieldMap map[string]string
+	MilestoneMap   map[string]string
+	StatusMap      map[string]*StatusMapping
+}
+
 // ImportTickets imports Trac tickets as Gitea issues.
-func (importer *Importer) ImportTickets(
-	userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap map[string]string) error {
-	err := importer.tracAccessor.GetTickets(func(ticket *trac.Ticket) error {
-		closed := (ticket.Status == string(trac.TicketStatusClosed))
-		issueID, err := importer.importTicket(ticket, closed, userMap)
+// The milestone map determines the Gitea milestone of each issue and
+// the status map determines whether each Trac ticket status closes a Gitea issue and any label given to issues with that status.
+func (importer *Importer) ImportTickets(maps *TicketMaps) error {
+	priorities, err := importer.issuePriorities()
+	if err != nil {
+		return err
+	}
+
+	statusLabels := statusLabelMap(maps.StatusMap)
+	err = importer.tracAccessor.GetTickets(func(ticket *trac.Ticket) error {
+		closed := getStatusMapping(ticket.Status, maps.StatusMap).Closed
+		issueID, err := importer.importTicket(ticket, closed, priorities[ticket.PriorityName], maps)
 		if err != nil {
 			return err
 		}
@@ -77,42 +142,63 @@ func (importer *Importer) ImportTickets(
 			return nil
 		}
 
-		_, err = importer.importTicketLabel(issueID, ticket.ComponentName, componentMap)
+		_, err = importer.importTicketLabel(issueID, ticket.ComponentName, maps.ComponentMap)
+		if err != nil {
+			return err
+		}
+
+		_, err = importer.importTicketLabel(issueID, ticket.PriorityName, maps.PriorityMap)
 		if err != nil {
 			return err
 		}
 
-		_, err = importer.importTicketLabel(issueID, ticket.PriorityName, priorityMap)
+		_, err = importer.importTicketLabel(issueID, ticket.ResolutionName, maps.ResolutionMap)
 		if err != nil {
 			return err
 		}
 
-		_, err = importer.importTicketLabel(issueID, ticket.ResolutionName, resolutionMap)
+		_, err = importer.importTicketLabel(issueID, ticket.SeverityName, maps.SeverityMap)
 		if err != nil {
 			return err
 		}
 
-		_, err = importer.importTicketLabel(issueID, ticket.SeverityName, severityMap)
+		_, err = importer.importTicketLabel(issueID, ticket.TypeName, maps.TypeMap)
 		if err != nil {
 			return err
 		}
 
-		_, err = importer.importTicketLabel(issueID, ticket.TypeName, typeMap)
+		_, err = importer.importTicketLabel(issueID, ticket.VersionName, maps.VersionMap)
+		if err != nil {
+			return err
+		}
+
+		_, err = importer.importTicketLabel(issueID, ticket.Status, statusLabels)
+		if err != nil {
+			return err
+		}
+
+		for _, keyword := range trac.SplitKeywords(ticket.Keywords) {
+			_, err = importer.importTicketLabel(issueID, keyword, maps.KeywordMap)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = importer.importTicketCustomFieldLabels(issueID, ticket, maps.CustomFieldMap)
 		if err != nil {
 			return err
 		}
 
-		_, err = importer.importTicketLabel(issueID, ticket.VersionName, versionMap)
+		err = importer.importTicketTimeEstimate(issueID, ticket)
 		if err != nil {
 			return err
 		}
 
-		lastUpdate, err := importer.importTicketAttachments(ticket.TicketID, issueID, ticket.Created, userMap)
+		lastUpdate, err := importer.importTicketAttachments(ticket.TicketID, issueID, ticket.Created, maps.UserMap)
 		if err != nil {
 			return err
 		}
-		lastUpdate, err = importer.importTicketChanges(ticket.TicketID, issueID, lastUpdate,
-			userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+		lastUpdate, err = importer.importTicketChanges(ticket, issueID, lastUpdate, maps)
 		if err != nil {
 			return err
 		}
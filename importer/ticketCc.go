@@ -0,0 +1,69 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+// unmappedCcList returns a collapsed markdown list of the entries in a Trac ticket Cc list which cannot be mapped onto Gitea users,
+// returns "" if there are no such entries.
+func (importer *Importer) unmappedCcList(ticket *trac.Ticket, userMap map[string]string) (string, error) {
+	listItems := ""
+	for _, ccEntry := range listDifference(trac.SplitCc(ticket.Cc), []string{}) {
+		userID, err := importer.getUserID(ccEntry, userMap)
+		if err != nil {
+			return "", err
+		}
+		if userID == gitea.NullID {
+			listItems = listItems + "* " + ccEntry + "\n"
+		}
+	}
+	if listItems == "" {
+		return "", nil
+	}
+
+	return "\n\n<details>\n<summary>Cc</summary>\n\n" + listItems + "\n</details>\n", nil
+}
+
+// setIssueWatches sets whether or not each of a list of Trac Cc entries is watching a Gitea issue - entries that cannot be mapped onto Gitea users are ignored.
+func (importer *Importer) setIssueWatches(issueID int64, ccEntries []string, isWatching bool, time int64, userMap map[string]string) error {
+	for _, ccEntry := range ccEntries {
+		userID, err := importer.getUserID(ccEntry, userMap)
+		if err != nil {
+			return err
+		}
+		if userID == gitea.NullID {
+			continue
+		}
+
+		err = importer.giteaAccessor.SetIssueWatch(issueID, userID, isWatching, time)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importCcChange imports a change to a Trac ticket Cc list into Gitea as a set of issue watch changes.
+// No Gitea issue comment is created for this so NullID is always returned.
+func (importer *Importer) importCcChange(issueID int64, change *trac.TicketChange, userMap map[string]string) (int64, error) {
+	oldCc := trac.SplitCc(change.OldValue)
+	newCc := trac.SplitCc(change.NewValue)
+
+	err := importer.setIssueWatches(issueID, listDifference(oldCc, newCc), false, change.Time, userMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	err = importer.setIssueWatches(issueID, listDifference(newCc, oldCc), true, change.Time, userMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	return gitea.NullID, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+)
+
+func TestImportTicketWithUnmappedCc(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	openTicket.cc = ccUser1.tracUser + ", " + unmappedCcUser.tracUser
+	openTicket.unmappedCcList = "\n\n<details>\n<summary>Cc</summary>\n\n* " + unmappedCcUser.tracUser + "\n\n</details>\n"
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect lookup of mapped Cc user when checking for unmapped Cc entries
+	expectUserLookup(t, ccUser1)
+
+	// expect all actions for creating Gitea issue from Trac ticket - this includes checking the unmapped Cc list in the issue description
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us no changes
+	expectTracChangeRetrievals(t, openTicket)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketCcChange(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one Cc change
+	expectTracChangeRetrievals(t, openTicket, ccTicketChange)
+
+	// expect removed user to stop watching issue and added user to start watching it - unmapped user is ignored
+	expectIssueWatchToBeSet(t, openTicket, ccUser1, false, ccTicketChange.time)
+	expectIssueWatchToBeSet(t, openTicket, ccUser3, true, ccTicketChange.time)
+
+	// expect issue update time to be updated - Cc change does not create a comment so does not count
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
@@ -9,6 +9,24 @@ import (
 	"github.com/stevejefferson/trac2gitea/accessor/trac"
 )
 
+// listDifference returns the entries in a list which are not in another list, each entry is returned only once.
+func listDifference(list []string, otherList []string) []string {
+	otherSet := make(map[string]bool)
+	for _, otherEntry := range otherList {
+		otherSet[otherEntry] = true
+	}
+
+	difference := []string{}
+	for _, entry := range list {
+		if !otherSet[entry] {
+			difference = append(difference, entry)
+			otherSet[entry] = true // only report duplicated entries once
+		}
+	}
+
+	return difference
+}
+
 // createIssueComment creates a basic Gitea IssueComment structure to be populated by individual ticket change import functions
 func (importer *Importer) createIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string) (*gitea.IssueComment, error) {
 	originalAuthorName := ""
@@ -31,7 +49,7 @@ func (importer *Importer) createIssueComment(issueID int64, change *trac.TicketC
 	// perform change-specific issue operations
 	issueComment := gitea.IssueComment{
 		AuthorID:           authorID,
-		OriginalAuthorID:   0,
+		OriginalAuthorID:   tracUserOriginalAuthorID(originalAuthorName),
 		OriginalAuthorName: originalAuthorName,
 		LabelID:            0,
 		OldMilestoneID:     0,
@@ -47,37 +65,90 @@ func (importer *Importer) createIssueComment(issueID int64, change *trac.TicketC
 	return &issueComment, nil
 }
 
+// importComponentChange imports a Trac ticket component change into Gitea as label changes and,
+// for a ticket with no owner, a change between the default assignees of the components.
+// Returns id of last created Gitea issue comment or NullID if cannot create comment
+func (importer *Importer) importComponentChange(issueID int64, ticket *trac.Ticket, change *trac.TicketChange, userMap map[string]string, componentMap map[string]string) (int64, error) {
+	issueCommentID, err := importer.importLabelChangeIssueComment(issueID, change, userMap, componentMap)
+	if err != nil || ticket.Owner != "" {
+		return issueCommentID, err
+	}
+
+	assigneeCommentID, err := importer.importDefaultAssigneeIssueComment(issueID, change, userMap, componentMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+	if assigneeCommentID != gitea.NullID {
+		issueCommentID = assigneeCommentID
+	}
+
+	return issueCommentID, nil
+}
+
+// importVersionChange imports a Trac ticket version change into Gitea as label changes and,
+// for a ticket with no (mapped) milestone, a change between the milestones onto which the versions are mapped.
+// Returns id of last created Gitea issue comment or NullID if cannot create comment
+func (importer *Importer) importVersionChange(issueID int64, ticket *trac.Ticket, change *trac.TicketChange, userMap, versionMap, milestoneMap map[string]string) (int64, error) {
+	issueCommentID, err := importer.importLabelChangeIssueComment(issueID, change, userMap, versionMap)
+	if err != nil || milestoneMap[ticket.MilestoneName] != "" {
+		return issueCommentID, err
+	}
+
+	milestoneCommentID, err := importer.importVersionMilestoneIssueComment(issueID, change, userMap, versionMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+	if milestoneCommentID != gitea.NullID {
+		issueCommentID = milestoneCommentID
+	}
+
+	return issueCommentID, nil
+}
+
 // importTicketChange imports a single ticket change from Trac to Gitea, returns ID of created Gitea comment or NullID if comment already exists
 func (importer *Importer) importTicketChange(
 	issueID int64,
+	ticket *trac.Ticket,
 	change *trac.TicketChange,
-	userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap map[string]string) (int64, error) {
+	maps *TicketMaps) (int64, error) {
 	var issueCommentID int64
 	var err error
 
 	switch change.ChangeType {
+	case trac.TicketCcChange:
+		issueCommentID, err = importer.importCcChange(issueID, change, maps.UserMap)
 	case trac.TicketCommentChange:
-		issueCommentID, err = importer.importCommentIssueComment(issueID, change, userMap)
+		issueCommentID, err = importer.importCommentIssueComment(issueID, change, maps.UserMap)
 	case trac.TicketComponentChange:
-		issueCommentID, err = importer.importLabelChangeIssueComment(issueID, change, userMap, componentMap)
+		issueCommentID, err = importer.importComponentChange(issueID, ticket, change, maps.UserMap, maps.ComponentMap)
+	case trac.TicketCustomFieldChange:
+		if change.CustomField.Name == hoursCustomFieldName {
+			issueCommentID, err = importer.importHoursChange(issueID, change, maps.UserMap)
+		} else {
+			issueCommentID, err = importer.importCustomFieldChangeIssueComment(issueID, change, maps.UserMap, maps.CustomFieldMap)
+		}
+	case trac.TicketDescriptionChange:
+		issueCommentID, err = importer.importDescriptionChange(issueID, ticket, change, maps)
+	case trac.TicketKeywordsChange:
+		issueCommentID, err = importer.importKeywordsChangeIssueComment(issueID, change, maps.UserMap, maps.KeywordMap)
 	case trac.TicketMilestoneChange:
-		issueCommentID, err = importer.importMilestoneIssueComment(issueID, change, userMap)
+		issueCommentID, err = importer.importMilestoneIssueComment(issueID, change, maps.UserMap, maps.MilestoneMap)
 	case trac.TicketOwnerChange:
-		issueCommentID, err = importer.importOwnershipIssueComment(issueID, change, userMap)
+		issueCommentID, err = importer.importOwnershipIssueComment(issueID, change, maps.UserMap)
 	case trac.TicketPriorityChange:
-		issueCommentID, err = importer.importLabelChangeIssueComment(issueID, change, userMap, priorityMap)
+		issueCommentID, err = importer.importLabelChangeIssueComment(issueID, change, maps.UserMap, maps.PriorityMap)
 	case trac.TicketResolutionChange:
-		issueCommentID, err = importer.importLabelChangeIssueComment(issueID, change, userMap, resolutionMap)
+		issueCommentID, err = importer.importLabelChangeIssueComment(issueID, change, maps.UserMap, maps.ResolutionMap)
 	case trac.TicketSeverityChange:
-		issueCommentID, err = importer.importLabelChangeIssueComment(issueID, change, userMap, severityMap)
+		issueCommentID, err = importer.importLabelChangeIssueComment(issueID, change, maps.UserMap, maps.SeverityMap)
 	case trac.TicketTypeChange:
-		issueCommentID, err = importer.importLabelChangeIssueComment(issueID, change, userMap, typeMap)
+		issueCommentID, err = importer.importLabelChangeIssueComment(issueID, change, maps.UserMap, maps.TypeMap)
 	case trac.TicketStatusChange:
-		issueCommentID, err = importer.importStatusChangeIssueComment(issueID, change, userMap)
+		issueCommentID, err = importer.importStatusChangeIssueComment(issueID, change, maps.UserMap, maps.StatusMap)
 	case trac.TicketSummaryChange:
-		issueCommentID, err = importer.importSummaryChangeIssueComment(issueID, change, userMap)
+		issueCommentID, err = importer.importSummaryChangeIssueComment(issueID, change, maps.UserMap)
 	case trac.TicketVersionChange:
-		issueCommentID, err = importer.importLabelChangeIssueComment(issueID, change, userMap, versionMap)
+		issueCommentID, err = importer.importVersionChange(issueID, ticket, change, maps.UserMap, maps.VersionMap, maps.MilestoneMap)
 	}
 	if err != nil {
 		return gitea.NullID, err
@@ -87,13 +158,13 @@ func (importer *Importer) importTicketChange(
 }
 
 func (importer *Importer) importTicketChanges(
-	ticketID int64,
+	ticket *trac.Ticket,
 	issueID int64,
 	lastUpdate int64,
-	userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap map[string]string) (int64, error) {
+	maps *TicketMaps) (int64, error) {
 	commentLastUpdate := lastUpdate
-	err := importer.tracAccessor.GetTicketChanges(ticketID, func(change *trac.TicketChange) error {
-		commentID, err := importer.importTicketChange(issueID, change, userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	err := importer.tracAccessor.GetTicketChanges(ticket.TicketID, func(change *trac.TicketChange) error {
+		commentID, err := importer.importTicketChange(issueID, ticket, change, maps)
 		if err != nil {
 			return err
 		}
@@ -5,10 +5,90 @@
 package importer
 
 import (
+	"regexp"
+	"strconv"
+	"strings"
+
 	"github.com/stevejefferson/trac2gitea/accessor/gitea"
 	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/log"
 )
 
+// regexp for the number of a Trac comment: $1=number of comment being replied to (optional), $2=comment number
+var commentNumberRegexp = regexp.MustCompile(`^(?:([[:digit:]]+)\.)?([[:digit:]]+)$`)
+
+// regexp for lines of a Trac comment which are not of interest in an excerpt: the lines Trac itself adds when replying to a comment
+var replyExcerptExcludeRegexp = regexp.MustCompile(`^(?:>|Replying to \[)`)
+
+// maximum length of the excerpt of a Trac comment quoted in a reply to it
+const replyExcerptLength = 200
+
+// replyExcerpt returns an excerpt of the text of a Trac comment for quoting in a reply: the first paragraph of the comment (truncated if necessary)
+// ignoring any text quoted from a comment to which it was itself a reply.
+func replyExcerpt(text string) string {
+	excerpt := ""
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		trimmedLine := strings.TrimSpace(line)
+		if replyExcerptExcludeRegexp.MatchString(trimmedLine) {
+			continue
+		}
+		if trimmedLine == "" {
+			if excerpt != "" {
+				break
+			}
+			continue
+		}
+
+		if excerpt != "" {
+			excerpt = excerpt + " "
+		}
+		excerpt = excerpt + trimmedLine
+	}
+
+	if excerptRunes := []rune(excerpt); len(excerptRunes) > replyExcerptLength {
+		excerpt = strings.TrimSpace(string(excerptRunes[:replyExcerptLength])) + "..."
+	}
+
+	return excerpt
+}
+
+// replyPrefix returns the Trac text to be prepended to a Trac ticket comment made in reply to another comment:
+// this consists of a link to the comment being replied to and a quoted excerpt of it, in the same form as Trac itself uses when replying.
+// Returns "" if the comment is not a reply or if Trac has already included this.
+func (importer *Importer) replyPrefix(change *trac.TicketChange) (string, error) {
+	commentNumberMatch := commentNumberRegexp.FindStringSubmatch(change.OldValue)
+	if commentNumberMatch == nil || commentNumberMatch[1] == "" {
+		return "", nil
+	}
+
+	parentCommentNum, err := strconv.ParseInt(commentNumberMatch[1], 10, 64)
+	if err != nil {
+		return "", err
+	}
+
+	parentLink := "comment:" + commentNumberMatch[1]
+	if strings.HasPrefix(change.NewValue, "Replying to ["+parentLink+" ") || strings.HasPrefix(change.NewValue, "Replying to ["+parentLink+"]") {
+		return "", nil
+	}
+
+	parentComment, err := importer.tracAccessor.GetTicketComment(change.TicketID, parentCommentNum)
+	if err != nil {
+		return "", err
+	}
+	if parentComment == nil {
+		log.Warn("cannot find comment %d of Trac ticket %d being replied to", parentCommentNum, change.TicketID)
+		return "", nil
+	}
+
+	prefix := "Replying to [" + parentLink + " " + parentComment.Author + "]:\n"
+	excerpt := replyExcerpt(parentComment.NewValue)
+	if excerpt != "" {
+		prefix = prefix + "> " + excerpt + "\n"
+	}
+
+	return prefix + "\n", nil
+}
+
 // importCommentIssueComment imports a Trac ticket comment into Gitea, returns id of created Gitea issue comment or NullID if cannot create comment
 func (importer *Importer) importCommentIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string) (int64, error) {
 	issueComment, err := importer.createIssueComment(issueID, change, userMap)
@@ -16,13 +96,74 @@ func (importer *Importer) importCommentIssueComment(issueID int64, change *trac.
 		return gitea.NullID, err
 	}
 
+	replyPrefix, err := importer.replyPrefix(change)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
 	issueComment.CommentType = gitea.CommentIssueCommentType
-	issueComment.Text = importer.markdownConverter.TicketConvert(change.TicketID, change.NewValue)
+	issueComment.Text = importer.markdownConverter.TicketConvert(change.TicketID, replyPrefix+change.NewValue)
 
 	issueCommentID, err := importer.giteaAccessor.AddIssueComment(issueID, issueComment)
 	if err != nil {
 		return gitea.NullID, err
 	}
 
+	err = importer.importCommentEdits(issueID, issueCommentID, issueComment.AuthorID, change, replyPrefix, userMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
 	return issueCommentID, nil
 }
+
+// importCommentEdits imports the edits of a Trac ticket comment as entries in the content history of the corresponding Gitea issue comment.
+// The provided reply prefix is prepended to each version of the comment text in the same way as for the comment itself.
+func (importer *Importer) importCommentEdits(issueID int64, issueCommentID int64, authorID int64, change *trac.TicketChange, replyPrefix string, userMap map[string]string) error {
+	if len(change.CommentEdits) == 0 || issueCommentID == gitea.NullID {
+		return nil
+	}
+
+	// first entry is the comment as originally created, which is the text from before the first edit
+	history := gitea.IssueContentHistory{
+		CommentID:      issueCommentID,
+		AuthorID:       authorID,
+		Text:           importer.markdownConverter.TicketConvert(change.TicketID, replyPrefix+change.CommentEdits[0].OldText),
+		Time:           change.Time,
+		IsFirstCreated: true,
+	}
+	_, err := importer.giteaAccessor.AddIssueContentHistory(issueID, &history)
+	if err != nil {
+		return err
+	}
+
+	// each edit produces the text from before the following edit or, for the final edit, the current comment text
+	for editIndex, edit := range change.CommentEdits {
+		editedText := change.NewValue
+		if editIndex+1 < len(change.CommentEdits) {
+			editedText = change.CommentEdits[editIndex+1].OldText
+		}
+
+		editorID, err := importer.getUserID(edit.Author, userMap)
+		if err != nil {
+			return err
+		}
+		if editorID == gitea.NullID {
+			editorID = importer.defaultAuthorID
+		}
+
+		history := gitea.IssueContentHistory{
+			CommentID:      issueCommentID,
+			AuthorID:       editorID,
+			Text:           importer.markdownConverter.TicketConvert(change.TicketID, replyPrefix+editedText),
+			Time:           edit.Time,
+			IsFirstCreated: false,
+		}
+		_, err = importer.giteaAccessor.AddIssueContentHistory(issueID, &history)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -35,7 +35,7 @@ func TestImportTicketWithComments(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
 }
 
 func TestImportMultipleTicketsWithComments(t *testing.T) {
@@ -74,7 +74,7 @@ func TestImportMultipleTicketsWithComments(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
 }
 
 func TestImportTicketWithCommentButNoTracUser(t *testing.T) {
@@ -105,7 +105,7 @@ func TestImportTicketWithCommentButNoTracUser(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
 }
 
 func TestImportTicketWithCommentButUnmappedTracUser(t *testing.T) {
@@ -136,5 +136,158 @@ func TestImportTicketWithCommentButUnmappedTracUser(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketWithCommentByNumericTracUser(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, unmappedTracUserTicket)
+
+	// expect all actions for creating Gitea issues from Trac tickets
+	expectAllTicketActions(t, unmappedTracUserTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, unmappedTracUserTicket)
+
+	// expect trac to return us a comment change
+	expectTracChangeRetrievals(t, unmappedTracUserTicket, numericTracUserTicketComment)
+
+	// expect all actions for creating Gitea issue comments from Trac ticket comments - the numeric Trac login is recorded as the original author id
+	expectAllTicketCommentActions(t, unmappedTracUserTicket, numericTracUserTicketComment)
+
+	// expect issues update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, unmappedTracUserTicket, numericTracUserTicketComment)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, unmappedTracUserTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketWithEditedComment(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us an edited comment
+	expectTracChangeRetrievals(t, openTicket, editedTicketComment)
+
+	// expect original comment text and comment text resulting from edit to be converted to markdown
+	expectTextMarkdownConversion(t, openTicket, originalCommentText, originalCommentMarkdownText)
+	expectTextMarkdownConversion(t, openTicket, editedTicketComment.text, editedTicketComment.markdownText)
+
+	// expect all actions for creating Gitea issue comment from Trac ticket comment
+	expectAllTicketCommentActions(t, openTicket, editedTicketComment)
+
+	// expect to lookup Gitea equivalent of comment editor
+	expectUserLookup(t, ticketCommentEditor)
+
+	// expect original comment text and edit to be recorded in content history of issue comment
+	expectIssueContentHistoryCreation(t, openTicket, editedTicketComment.issueCommentID,
+		editedTicketComment.author, originalCommentMarkdownText, editedTicketComment.time, true)
+	expectIssueContentHistoryCreation(t, openTicket, editedTicketComment.issueCommentID,
+		ticketCommentEditor, editedTicketComment.markdownText, editedTicketComment.commentEdits[0].Time, false)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, editedTicketComment)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketWithReplyComment(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us a comment and a reply to it
+	expectTracChangeRetrievals(t, openTicket, openTicketComment1, replyTicketComment)
+
+	// expect all actions for creating Gitea issue comment from original Trac ticket comment
+	expectAllTicketCommentActions(t, openTicket, openTicketComment1)
+
+	// expect retrieval of comment being replied to
+	expectTracCommentRetrieval(t, openTicket, 1, openTicketComment1)
+
+	// expect reply to be converted to markdown with a link to and excerpt from the comment being replied to
+	expectTextMarkdownConversion(t, openTicket,
+		"Replying to [comment:1 "+openTicketComment1Author.tracUser+"]:\n> "+openTicketComment1.text+"\n\n"+replyTicketComment.text,
+		replyTicketComment.markdownText)
+
+	// expect all other actions for creating Gitea issue comment from reply
+	expectUserLookup(t, replyTicketComment.author)
+	expectIssueCommentCreationForComment(t, openTicket, replyTicketComment)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, openTicketComment1, replyTicketComment)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketWithTracReplyComment(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us a reply which already includes Trac's reply text
+	expectTracChangeRetrievals(t, openTicket, tracReplyTicketComment)
+
+	// expect reply to be converted to markdown unchanged - there should be no retrieval of the comment being replied to
+	expectTextMarkdownConversion(t, openTicket, tracReplyTicketComment.text, tracReplyTicketComment.markdownText)
+
+	// expect all other actions for creating Gitea issue comment from reply
+	expectUserLookup(t, tracReplyTicketComment.author)
+	expectIssueCommentCreationForComment(t, openTicket, tracReplyTicketComment)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, tracReplyTicketComment)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
 }
@@ -0,0 +1,124 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+// escapeMetadataValue escapes a custom field value for inclusion in a markdown table cell.
+func escapeMetadataValue(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	value = strings.ReplaceAll(value, "\r\n", "<br>")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+// customFieldMetadataTable returns a markdown table of the values of all of the custom fields of a ticket converted to issue metadata,
+// returns "" if there are no such fields.
+func customFieldMetadataTable(ticket *trac.Ticket, customFieldMap map[string]string) string {
+	metadataRows := ""
+	for _, customField := range ticket.CustomFields {
+		if customFieldMap[customField.Field.Name] == CustomFieldMetadata {
+			metadataRows = metadataRows + fmt.Sprintf("| %s | %s |\n", escapeMetadataValue(customField.Field.Label), escapeMetadataValue(customField.Value))
+		}
+	}
+	if metadataRows == "" {
+		return ""
+	}
+
+	return "| Field | Value |\n| --- | --- |\n" + metadataRows + "\n"
+}
+
+// importTicketCustomFieldLabels imports the labels for all custom fields of a Trac ticket converted to labels.
+func (importer *Importer) importTicketCustomFieldLabels(issueID int64, ticket *trac.Ticket, customFieldMap map[string]string) error {
+	for _, customField := range ticket.CustomFields {
+		if customFieldMap[customField.Field.Name] != CustomFieldLabel {
+			continue
+		}
+
+		_, err := importer.importTicketLabel(issueID, customFieldValueKey(customField.Field.Name, customField.Value), customFieldMap)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importCustomFieldMetadataChangeIssueComment imports a change to a Trac custom field converted to issue metadata as a Gitea issue comment describing the change,
+// returns id of created Gitea issue comment or NullID if cannot create comment
+func (importer *Importer) importCustomFieldMetadataChangeIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string) (int64, error) {
+	fieldLabel := change.CustomField.Label
+	var changeText string
+	switch {
+	case change.Initial:
+		// initial value of field is not a change in its own right - the issue metadata table already records the field
+		return gitea.NullID, nil
+	case change.OldValue == change.NewValue:
+		return gitea.NullID, nil
+	case change.OldValue == "":
+		changeText = fmt.Sprintf("**%s** set to *%s*", fieldLabel, change.NewValue)
+	case change.NewValue == "":
+		changeText = fmt.Sprintf("**%s** *%s* deleted", fieldLabel, change.OldValue)
+	default:
+		changeText = fmt.Sprintf("**%s** changed from *%s* to *%s*", fieldLabel, change.OldValue, change.NewValue)
+	}
+
+	issueComment, err := importer.createIssueComment(issueID, change, userMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	issueComment.CommentType = gitea.CommentIssueCommentType
+	issueComment.Text = changeText
+
+	issueCommentID, err := importer.giteaAccessor.AddIssueComment(issueID, issueComment)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	return issueCommentID, nil
+}
+
+// importCustomFieldLabelChangeIssueComment imports a change to a Trac custom field converted to labels as Gitea label change issue comments,
+// returns id of created Gitea issue comment or NullID if cannot create comment
+func (importer *Importer) importCustomFieldLabelChangeIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string, customFieldMap map[string]string) (int64, error) {
+	var err error
+	var issueCommentID int64
+
+	fieldName := change.CustomField.Name
+	if change.OldValue != "" {
+		issueCommentID, err = importer.addLabelChangeIssueComment(issueID, change, customFieldValueKey(fieldName, change.OldValue), false, userMap, customFieldMap)
+		if err != nil {
+			return gitea.NullID, err
+		}
+	}
+
+	if change.NewValue != "" {
+		issueCommentID, err = importer.addLabelChangeIssueComment(issueID, change, customFieldValueKey(fieldName, change.NewValue), true, userMap, customFieldMap)
+		if err != nil {
+			return gitea.NullID, err
+		}
+	}
+
+	return issueCommentID, nil
+}
+
+// importCustomFieldChangeIssueComment imports a change to a Trac custom field into Gitea according to the conversion for that field,
+// returns id of created Gitea issue comment or NullID if cannot create comment
+func (importer *Importer) importCustomFieldChangeIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string, customFieldMap map[string]string) (int64, error) {
+	switch customFieldMap[change.CustomField.Name] {
+	case CustomFieldLabel:
+		return importer.importCustomFieldLabelChangeIssueComment(issueID, change, userMap, customFieldMap)
+	case CustomFieldMetadata:
+		return importer.importCustomFieldMetadataChangeIssueComment(issueID, change, userMap)
+	}
+
+	return gitea.NullID, nil
+}
@@ -0,0 +1,170 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+)
+
+func TestImportTicketWithCustomFields(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	addTicketCustomFields(openTicket)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket - this includes checking the metadata table in the issue description
+	expectAllTicketActions(t, openTicket)
+
+	// expect creation of label for custom field converted to labels
+	expectIssueLabelCreation(t, openTicket, customFieldLabel1)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us no changes
+	expectTracChangeRetrievals(t, openTicket)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketLabelCustomFieldChange(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one change to a custom field converted to labels
+	expectTracChangeRetrievals(t, openTicket, labelCustomFieldTicketChange)
+
+	// expect the custom field change to be handled as a label change
+	expectAllTicketLabelActions(t, openTicket, labelCustomFieldTicketChange)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, labelCustomFieldTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketMetadataCustomFieldChange(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one change to a custom field converted to metadata
+	expectTracChangeRetrievals(t, openTicket, metadataCustomFieldTicketChange)
+
+	// expect to lookup Gitea equivalent of author of Trac ticket change
+	expectUserLookup(t, metadataCustomFieldTicketChange.author)
+
+	// expect a comment describing the change
+	expectIssueCommentCreationForCustomFieldMetadataChange(t, openTicket, metadataCustomFieldTicketChange,
+		"**"+metadataCustomField.Label+"** changed from *"+metadataFieldValue1+"* to *"+metadataFieldValue2+"*")
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, metadataCustomFieldTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketMetadataCustomFieldInitialValue(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	addTicketCustomFields(openTicket)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket - this includes checking the metadata table in the issue description
+	expectAllTicketActions(t, openTicket)
+
+	// expect creation of label for custom field converted to labels
+	expectIssueLabelCreation(t, openTicket, customFieldLabel1)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us only the synthetic change for the initial value of the custom field converted to metadata
+	// - this should produce no comment as the value is already in the metadata table
+	expectTracChangeRetrievals(t, openTicket, metadataCustomFieldInitialTicketChange)
+
+	// expect issue update time to be updated - initial value does not count
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketIgnoredCustomFieldChange(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one change to an ignored custom field - this should produce no Gitea actions
+	expectTracChangeRetrievals(t, openTicket, ignoredCustomFieldTicketChange)
+
+	// expect issue update time to be updated - ignored change does not count
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// ImportTicketDependencies imports dependencies between Trac tickets as dependencies between the corresponding Gitea issues.
+// This must be performed after the tickets themselves have been imported; dependencies involving tickets which have not been imported are skipped.
+func (importer *Importer) ImportTicketDependencies() error {
+	skippedCount := 0
+	err := importer.tracAccessor.GetTicketDependencies(func(dependency *trac.TicketDependency) error {
+		issueID, err := importer.giteaAccessor.GetIssueID(dependency.TicketID)
+		if err != nil {
+			return err
+		}
+		blockingIssueID, err := importer.giteaAccessor.GetIssueID(dependency.BlockingTicketID)
+		if err != nil {
+			return err
+		}
+
+		if issueID == gitea.NullID || blockingIssueID == gitea.NullID {
+			unmigratedTicketID := dependency.TicketID
+			if issueID != gitea.NullID {
+				unmigratedTicketID = dependency.BlockingTicketID
+			}
+			log.Warn("skipping dependency of Trac ticket %d on ticket %d: ticket %d has not been migrated",
+				dependency.TicketID, dependency.BlockingTicketID, unmigratedTicketID)
+			skippedCount++
+			return nil
+		}
+
+		return importer.giteaAccessor.AddIssueDependency(issueID, blockingIssueID, importer.defaultAuthorID, dependency.Time)
+	})
+	if err != nil {
+		return err
+	}
+
+	if skippedCount > 0 {
+		log.Warn("skipped %d Trac ticket dependencies involving tickets which have not been migrated", skippedCount)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+const (
+	blockedTicketID    = int64(101)
+	blockingTicketID   = int64(102)
+	unmigratedTicketID = int64(103)
+
+	blockedIssueID  = int64(201)
+	blockingIssueID = int64(202)
+
+	dependencyTime = int64(123456)
+)
+
+var (
+	tracDependency           trac.TicketDependency
+	tracUnmigratedDependency trac.TicketDependency
+)
+
+func setUpTicketDependencies(t *testing.T) {
+	setUp(t)
+
+	tracDependency = trac.TicketDependency{TicketID: blockedTicketID, BlockingTicketID: blockingTicketID, Time: dependencyTime}
+	tracUnmigratedDependency = trac.TicketDependency{TicketID: blockedTicketID, BlockingTicketID: unmigratedTicketID, Time: dependencyTime}
+}
+
+func expectTracDependencyRetrievals(t *testing.T, dependencies ...*trac.TicketDependency) {
+	mockTracAccessor.
+		EXPECT().
+		GetTicketDependencies(gomock.Any()).
+		DoAndReturn(func(handlerFn func(dependency *trac.TicketDependency) error) error {
+			for _, dependency := range dependencies {
+				handlerFn(dependency)
+			}
+			return nil
+		})
+}
+
+func expectIssueLookup(t *testing.T, ticketID int64, issueID int64) {
+	mockGiteaAccessor.
+		EXPECT().
+		GetIssueID(gomock.Eq(ticketID)).
+		Return(issueID, nil).
+		AnyTimes()
+}
+
+func TestImportTicketDependency(t *testing.T) {
+	setUpTicketDependencies(t)
+	defer tearDown(t)
+
+	expectTracDependencyRetrievals(t, &tracDependency)
+
+	// expect both tickets to be looked up in Gitea
+	expectIssueLookup(t, blockedTicketID, blockedIssueID)
+	expectIssueLookup(t, blockingTicketID, blockingIssueID)
+
+	// expect dependency between corresponding issues to be created by default user
+	mockGiteaAccessor.
+		EXPECT().
+		AddIssueDependency(gomock.Eq(blockedIssueID), gomock.Eq(blockingIssueID), gomock.Eq(defaultUserID), gomock.Eq(dependencyTime)).
+		Return(nil)
+
+	dataImporter.ImportTicketDependencies()
+}
+
+func TestImportTicketDependencyOnUnmigratedTicket(t *testing.T) {
+	setUpTicketDependencies(t)
+	defer tearDown(t)
+
+	expectTracDependencyRetrievals(t, &tracUnmigratedDependency)
+
+	// expect both tickets to be looked up in Gitea - one of which has no issue
+	expectIssueLookup(t, blockedTicketID, blockedIssueID)
+	expectIssueLookup(t, unmigratedTicketID, gitea.NullID)
+
+	// expect no dependency to be created - gomock will fail the test if one is
+
+	dataImporter.ImportTicketDependencies()
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+// issueDescription returns the Gitea issue description for a version of the description of a Trac ticket:
+// the converted description is preceded by the table of the ticket's custom fields shown as metadata and followed by the ticket's unmapped Cc entries
+// so that each version in the content history of the issue differs from the issue description only in the description itself.
+func (importer *Importer) issueDescription(ticket *trac.Ticket, description string, maps *TicketMaps) (string, error) {
+	convertedDescription := importer.markdownConverter.TicketConvert(ticket.TicketID, description)
+
+	// Cc entries with Gitea users become issue watchers (via the ticket's Cc changes) - record any others in the issue description
+	unmappedCc, err := importer.unmappedCcList(ticket, maps.UserMap)
+	if err != nil {
+		return "", err
+	}
+
+	return customFieldMetadataTable(ticket, maps.CustomFieldMap) + convertedDescription + unmappedCc, nil
+}
+
+// importOriginalDescription records the original description of an edited Trac ticket as the first entry in the content history of its Gitea issue.
+// Tickets whose description has never been edited have no content history.
+func (importer *Importer) importOriginalDescription(issueID int64, ticket *trac.Ticket, reporterID int64, maps *TicketMaps) error {
+	if ticket.OriginalDescription == ticket.Description {
+		return nil
+	}
+
+	originalDescription, err := importer.issueDescription(ticket, ticket.OriginalDescription, maps)
+	if err != nil {
+		return err
+	}
+
+	history := gitea.IssueContentHistory{
+		CommentID:      gitea.NullID,
+		AuthorID:       reporterID,
+		Text:           originalDescription,
+		Time:           ticket.Created,
+		IsFirstCreated: true,
+	}
+	_, err = importer.giteaAccessor.AddIssueContentHistory(issueID, &history)
+	return err
+}
+
+// importDescriptionChange imports a Trac ticket description change as an entry in the content history of the Gitea issue.
+// No Gitea issue comment is created for this so NullID is always returned.
+func (importer *Importer) importDescriptionChange(issueID int64, ticket *trac.Ticket, change *trac.TicketChange, maps *TicketMaps) (int64, error) {
+	authorID, err := importer.getUserID(change.Author, maps.UserMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+	if authorID == gitea.NullID {
+		authorID = importer.defaultAuthorID
+	}
+
+	description, err := importer.issueDescription(ticket, change.NewValue, maps)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	history := gitea.IssueContentHistory{
+		CommentID:      gitea.NullID,
+		AuthorID:       authorID,
+		Text:           description,
+		Time:           change.Time,
+		IsFirstCreated: false,
+	}
+	_, err = importer.giteaAccessor.AddIssueContentHistory(issueID, &history)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	return gitea.NullID, nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+)
+
+func TestImportTicketWithEditedDescription(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	openTicket.originalDescription = originalDescription
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect original description to be converted to markdown
+	expectTextMarkdownConversion(t, openTicket, originalDescription, originalDescriptionMarkdown)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect original description to be recorded as first entry in issue content history
+	expectIssueContentHistoryCreation(t, openTicket, gitea.NullID, openTicket.reporter, originalDescriptionMarkdown, openTicket.created, true)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us no changes
+	expectTracChangeRetrievals(t, openTicket)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketDescriptionChange(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect edited description to be converted to markdown
+	expectTextMarkdownConversion(t, openTicket, editedDescription, editedDescriptionMarkdown)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one description change
+	expectTracChangeRetrievals(t, openTicket, descriptionTicketChange)
+
+	// expect to lookup Gitea equivalent of author of Trac ticket change
+	expectUserLookup(t, descriptionTicketChange.author)
+
+	// expect edited description to be recorded in issue content history
+	expectIssueContentHistoryCreation(t, openTicket, gitea.NullID, descriptionTicketChange.author, editedDescriptionMarkdown, descriptionTicketChange.time, false)
+
+	// expect issue update time to be updated - description change does not create a comment so does not count
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketDescriptionHistoryWithMetadataAndUnmappedCc(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	openTicket.originalDescription = originalDescription
+	addTicketCustomFields(openTicket)
+	openTicket.cc = ccUser1.tracUser + ", " + unmappedCcUser.tracUser
+	openTicket.unmappedCcList = "\n\n<details>\n<summary>Cc</summary>\n\n* " + unmappedCcUser.tracUser + "\n\n</details>\n"
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect original and edited descriptions to be converted to markdown
+	expectTextMarkdownConversion(t, openTicket, originalDescription, originalDescriptionMarkdown)
+	expectTextMarkdownConversion(t, openTicket, editedDescription, editedDescriptionMarkdown)
+
+	// expect lookup of mapped Cc user when checking for unmapped Cc entries
+	expectUserLookup(t, ccUser1)
+
+	// expect all actions for creating Gitea issue from Trac ticket - this includes checking the metadata table and unmapped Cc list in the issue description
+	expectAllTicketActions(t, openTicket)
+
+	// expect creation of label for custom field converted to labels
+	expectIssueLabelCreation(t, openTicket, customFieldLabel1)
+
+	// expect original description to be recorded as first entry in issue content history with the same metadata table and unmapped Cc list as the issue description
+	expectIssueContentHistoryCreation(t, openTicket, gitea.NullID, openTicket.reporter,
+		openTicket.metadataTable+originalDescriptionMarkdown+openTicket.unmappedCcList, openTicket.created, true)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one description change
+	expectTracChangeRetrievals(t, openTicket, descriptionTicketChange)
+
+	// expect to lookup Gitea equivalent of author of Trac ticket change
+	expectUserLookup(t, descriptionTicketChange.author)
+
+	// expect edited description to be recorded in issue content history with the same metadata table and unmapped Cc list
+	expectIssueContentHistoryCreation(t, openTicket, gitea.NullID, descriptionTicketChange.author,
+		openTicket.metadataTable+editedDescriptionMarkdown+openTicket.unmappedCcList, descriptionTicketChange.time, false)
+
+	// expect issue update time to be updated - description change does not create a comment so does not count
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+)
+
+func TestImportTicketWithKeywords(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	openTicket.keywords = keywordLabel1.tracName + ", " + keywordLabel2.tracName
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect creation of a label for each keyword
+	expectIssueLabelCreation(t, openTicket, keywordLabel1)
+	expectIssueLabelCreation(t, openTicket, keywordLabel2)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us no changes
+	expectTracChangeRetrievals(t, openTicket)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketKeywordsChange(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one keywords change
+	expectTracChangeRetrievals(t, openTicket, keywordsTicketChange)
+
+	// expect removal of label for removed keyword and addition of label for added keyword
+	expectAllTicketKeywordsActions(t, openTicket, keywordsTicketChange,
+		[]*TicketLabelImport{keywordLabel1}, []*TicketLabelImport{keywordLabel3})
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, keywordsTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
@@ -10,36 +10,38 @@ import (
 	"github.com/stevejefferson/trac2gitea/log"
 )
 
-// importTicketLabel imports a single issue label from Trac into Gitea, returns id of created issue label or gitea.NullID if issue label already exists
+// importTicketLabel imports a single issue label from Trac into Gitea as issue labels for each Gitea label onto which it is mapped,
+// returns id of last created issue label or gitea.NullID if no issue label created
 func (importer *Importer) importTicketLabel(issueID int64, tracName string, labelMap map[string]string) (int64, error) {
-	labelID, err := importer.getLabelID(tracName, labelMap)
+	labelIDs, err := importer.getLabelIDs(tracName, labelMap)
 	if err != nil {
 		return gitea.NullID, err
 	}
-	if labelID == gitea.NullID {
-		return gitea.NullID, nil
-	}
 
-	issueLabelID, err := importer.giteaAccessor.AddIssueLabel(issueID, labelID)
-	if err != nil {
-		return gitea.NullID, err
-	}
+	issueLabelID := gitea.NullID
+	for _, labelID := range labelIDs {
+		issueLabelID, err = importer.giteaAccessor.AddIssueLabel(issueID, labelID)
+		if err != nil {
+			return gitea.NullID, err
+		}
 
-	log.Debug("created issue label (id %d) for issue %d, label %d", issueLabelID, issueID, labelID)
+		log.Debug("created issue label (id %d) for issue %d, label %d", issueLabelID, issueID, labelID)
+	}
 
 	return issueLabelID, nil
 }
 
-// addLabelChangeIssueComment adds a single label change issue comment into Gitea, returns id of created Gitea issue comment or gitea.NullID if cannot create comment
+// addLabelChangeIssueComment adds a label change issue comment into Gitea for each Gitea label onto which a Trac label is mapped,
+// returns id of last created Gitea issue comment or gitea.NullID if cannot create comment
 func (importer *Importer) addLabelChangeIssueComment(issueID int64, change *trac.TicketChange, labelName string, isAdd bool, userMap map[string]string, labelMap map[string]string) (int64, error) {
 	var issueCommentID int64
 
-	labelID, err := importer.getLabelID(labelName, labelMap)
+	labelIDs, err := importer.getLabelIDs(labelName, labelMap)
 	if err != nil {
 		return gitea.NullID, err
 	}
 
-	if labelID != gitea.NullID {
+	for _, labelID := range labelIDs {
 		issueComment, err := importer.createIssueComment(issueID, change, userMap)
 		if err != nil {
 			return gitea.NullID, err
@@ -83,3 +85,27 @@ func (importer *Importer) importLabelChangeIssueComment(issueID int64, change *t
 
 	return issueCommentID, nil
 }
+
+// importKeywordsChangeIssueComment imports a Trac ticket keywords change into Gitea as a set of label changes,
+// returns id of last created Gitea issue comment or NullID if cannot create comment
+func (importer *Importer) importKeywordsChangeIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string, keywordMap map[string]string) (int64, error) {
+	var err error
+	var issueCommentID int64
+
+	// each keyword removed from the ticket is a label removal event and each keyword added to the ticket a label addition event
+	for _, removedKeyword := range listDifference(trac.SplitKeywords(change.OldValue), trac.SplitKeywords(change.NewValue)) {
+		issueCommentID, err = importer.addLabelChangeIssueComment(issueID, change, removedKeyword, false, userMap, keywordMap)
+		if err != nil {
+			return gitea.NullID, err
+		}
+	}
+
+	for _, addedKeyword := range listDifference(trac.SplitKeywords(change.NewValue), trac.SplitKeywords(change.OldValue)) {
+		issueCommentID, err = importer.addLabelChangeIssueComment(issueID, change, addedKeyword, true, userMap, keywordMap)
+		if err != nil {
+			return gitea.NullID, err
+		}
+	}
+
+	return issueCommentID, nil
+}
@@ -0,0 +1,285 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import "testing"
+
+func TestImportTicketWithMultipleComponentLabels(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// map component onto an additional label
+	addLabelMapping(componentMap, openTicket.componentLabel, extraComponentLabel.giteaLabelName)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect issue to also be given the additional label
+	expectIssueLabelCreation(t, openTicket, extraComponentLabel)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us no changes
+	expectTracChangeRetrievals(t, openTicket)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketComponentAmendWithMultipleLabels(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// map new component onto an additional label
+	addLabelMapping(componentMap, componentAmendTicketChange.label, extraComponentLabel.giteaLabelName)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket - issue has new component, which has an additional label
+	expectAllTicketActions(t, openTicket)
+	expectIssueLabelCreation(t, openTicket, extraComponentLabel)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one component amend
+	expectTracChangeRetrievals(t, openTicket, componentAmendTicketChange)
+
+	// expect all actions for creating Gitea comments from Trac ticket label changes, including addition of additional label
+	expectAllTicketLabelActions(t, openTicket, componentAmendTicketChange)
+	expectIssueCommentCreationForLabelChange(t, openTicket, componentAmendTicketChange, extraComponentLabel, true)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, componentAmendTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportUnownedTicketWithComponentDefaultAssignee(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// give component of unowned ticket a default assignee
+	addLabelMapping(componentMap, noTracUserTicket.componentLabel, "assignee:"+component1Assignee.giteaUser)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, noTracUserTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, noTracUserTicket)
+
+	// expect issue to be assigned to default assignee
+	expectDefaultAssigneeToBeAdded(t, noTracUserTicket, component1Assignee)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, noTracUserTicket)
+
+	// expect trac to return us no changes
+	expectTracChangeRetrievals(t, noTracUserTicket)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, noTracUserTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, noTracUserTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportOwnedTicketIgnoresComponentDefaultAssignee(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// give component of owned ticket a default assignee - which should not be looked up
+	addLabelMapping(componentMap, openTicket.componentLabel, "assignee:"+component2Assignee.giteaUser)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one component amend
+	expectTracChangeRetrievals(t, openTicket, componentAmendTicketChange)
+
+	// expect only label actions for component change
+	expectAllTicketLabelActions(t, openTicket, componentAmendTicketChange)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, componentAmendTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportUnownedTicketComponentAmendWithDefaultAssignees(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// give both components default assignees
+	addLabelMapping(componentMap, componentAmendTicketChange.prevLabel, "assignee:"+component1Assignee.giteaUser)
+	addLabelMapping(componentMap, componentAmendTicketChange.label, "assignee:"+component2Assignee.giteaUser)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, noTracUserTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket, including assignment to default assignee
+	expectAllTicketActions(t, noTracUserTicket)
+	expectDefaultAssigneeToBeAdded(t, noTracUserTicket, component1Assignee)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, noTracUserTicket)
+
+	// expect trac to return us one component amend
+	expectTracChangeRetrievals(t, noTracUserTicket, componentAmendTicketChange)
+
+	// expect label actions for component change followed by change of assignee between default assignees
+	expectAllTicketLabelActions(t, noTracUserTicket, componentAmendTicketChange)
+	expectIssueCommentCreationForDefaultAssigneeChange(t, noTracUserTicket, componentAmendTicketChange, component1Assignee, component2Assignee)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, noTracUserTicket, componentAmendTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, noTracUserTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketWithVersionMilestone(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// ticket has no milestone but its version is mapped onto one
+	openTicket.milestoneName = ""
+	openTicket.versionMilestone = version2Milestone
+	addLabelMapping(versionMap, openTicket.versionLabel, "milestone:"+version2Milestone.milestoneName)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us no changes
+	expectTracChangeRetrievals(t, openTicket)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketWithMilestoneIgnoresVersionMilestone(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// ticket has a milestone of its own: this takes precedence over that of its version
+	openTicket.versionMilestone = version2Milestone
+	addLabelMapping(versionMap, openTicket.versionLabel, "milestone:"+version2Milestone.milestoneName)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us no changes
+	expectTracChangeRetrievals(t, openTicket)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketVersionAmendWithVersionMilestones(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// ticket has no milestone but its versions are mapped onto milestones
+	openTicket.milestoneName = ""
+	openTicket.versionMilestone = version2Milestone
+	addLabelMapping(versionMap, versionAmendTicketChange.prevLabel, "milestone:"+version1Milestone.milestoneName)
+	addLabelMapping(versionMap, versionAmendTicketChange.label, "milestone:"+version2Milestone.milestoneName)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one version amend
+	expectTracChangeRetrievals(t, openTicket, versionAmendTicketChange)
+
+	// expect label actions for version change followed by change of milestone between version milestones
+	expectAllTicketLabelActions(t, openTicket, versionAmendTicketChange)
+	expectIssueCommentCreationForVersionMilestoneChange(t, openTicket, versionAmendTicketChange, version1Milestone, version2Milestone)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, versionAmendTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
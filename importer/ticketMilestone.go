@@ -9,15 +9,15 @@ import (
 	"github.com/stevejefferson/trac2gitea/accessor/trac"
 )
 
-// importMilestoneIssueComment imports a Trac ticket milestone change into Gitea, returns id of created Gitea issue comment or gitea.NullID if cannot create comment
-func (importer *Importer) importMilestoneIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string) (int64, error) {
+// addMilestoneIssueComment adds a Gitea issue comment for a change of issue milestone resulting from a Trac ticket change,
+// returns id of created Gitea issue comment or gitea.NullID if cannot create comment
+func (importer *Importer) addMilestoneIssueComment(issueID int64, change *trac.TicketChange, oldMilestone string, milestone string, userMap map[string]string) (int64, error) {
 	issueComment, err := importer.createIssueComment(issueID, change, userMap)
 	if err != nil {
 		return gitea.NullID, err
 	}
 
 	var oldMilestoneID = gitea.NullID
-	oldMilestone := change.OldValue
 	if oldMilestone != "" {
 		oldMilestoneID, err = importer.giteaAccessor.GetMilestoneID(oldMilestone)
 		if err != nil {
@@ -26,7 +26,6 @@ func (importer *Importer) importMilestoneIssueComment(issueID int64, change *tra
 	}
 
 	var milestoneID = gitea.NullID
-	milestone := change.NewValue
 	if milestone != "" {
 		milestoneID, err = importer.giteaAccessor.GetMilestoneID(milestone)
 		if err != nil {
@@ -49,3 +48,27 @@ func (importer *Importer) importMilestoneIssueComment(issueID int64, change *tra
 	}
 	return issueCommentID, nil
 }
+
+// importMilestoneIssueComment imports a Trac ticket milestone change into Gitea as a change between the Gitea milestones onto which the Trac milestones are mapped,
+// returns id of created Gitea issue comment or gitea.NullID if cannot create comment
+func (importer *Importer) importMilestoneIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string, milestoneMap map[string]string) (int64, error) {
+	oldMilestone := milestoneMap[change.OldValue]
+	milestone := milestoneMap[change.NewValue]
+	if oldMilestone == milestone {
+		return gitea.NullID, nil // e.g. change between merged milestones
+	}
+
+	return importer.addMilestoneIssueComment(issueID, change, oldMilestone, milestone, userMap)
+}
+
+// importVersionMilestoneIssueComment imports a Trac ticket version change into Gitea as a change between the milestones onto which the versions are mapped,
+// returns id of created Gitea issue comment or gitea.NullID if cannot create comment
+func (importer *Importer) importVersionMilestoneIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string, versionMap map[string]string) (int64, error) {
+	oldMilestone := parseLabelMapping(versionMap[change.OldValue]).milestone
+	milestone := parseLabelMapping(versionMap[change.NewValue]).milestone
+	if oldMilestone == milestone {
+		return gitea.NullID, nil
+	}
+
+	return importer.addMilestoneIssueComment(issueID, change, oldMilestone, milestone, userMap)
+}
@@ -4,7 +4,11 @@
 
 package importer_test
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
 
 func TestImportTicketMilestone(t *testing.T) {
 	setUpTickets(t)
@@ -34,5 +38,80 @@ func TestImportTicketMilestone(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketRenamedMilestone(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// rename all milestones
+	milestoneMap[openTicket.milestoneName] = "renamed-" + openTicket.milestoneName
+	milestoneMap[milestone1.milestoneName] = "renamed-" + milestone1.milestoneName
+	milestoneMap[milestone2.milestoneName] = "renamed-" + milestone2.milestoneName
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one milestone change
+	expectTracChangeRetrievals(t, openTicket, milestoneTicketChange)
+
+	// expect milestone change to be between renamed milestones
+	expectUserLookup(t, milestoneTicketChange.author)
+	for _, milestone := range []*TicketMilestoneImport{milestone1, milestone2} {
+		mockGiteaAccessor.
+			EXPECT().
+			GetMilestoneID(gomock.Eq("renamed-"+milestone.milestoneName)).
+			Return(milestone.milestoneID, nil)
+	}
+	expectIssueCommentCreationForMilestoneChange(t, openTicket, milestoneTicketChange)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, milestoneTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketMergedMilestoneChange(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// merge milestones of milestone change
+	milestoneMap[milestone1.milestoneName] = "merged"
+	milestoneMap[milestone2.milestoneName] = "merged"
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one milestone change - which does not change the Gitea milestone so is not imported
+	expectTracChangeRetrievals(t, openTicket, milestoneTicketChange)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
 }
@@ -7,6 +7,7 @@ package importer
 import (
 	"github.com/stevejefferson/trac2gitea/accessor/gitea"
 	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/log"
 )
 
 // importOwnershipIssueComment imports a Trac ticket ownership change as a Gitea issue assignee change, returns id of created Gitea issue comment or gitea.NullID if cannot create comment
@@ -54,3 +55,56 @@ func (importer *Importer) importOwnershipIssueComment(issueID int64, change *tra
 
 	return issueCommentID, nil
 }
+
+// getDefaultAssigneeID retrieves the ID of the Gitea user onto which the default assignee of a Trac component is mapped, returns gitea.NullID if none
+func (importer *Importer) getDefaultAssigneeID(componentName string, componentMap map[string]string) (int64, error) {
+	assignee := parseLabelMapping(componentMap[componentName]).assignee
+	if assignee == "" {
+		return gitea.NullID, nil
+	}
+
+	assigneeID, err := importer.giteaAccessor.GetUserID(assignee)
+	if err != nil {
+		return gitea.NullID, err
+	}
+	if assigneeID == gitea.NullID {
+		log.Warn("cannot find Gitea user %s, default assignee for Trac component %s", assignee, componentName)
+	}
+
+	return assigneeID, nil
+}
+
+// importDefaultAssigneeIssueComment imports a Trac ticket component change into Gitea as a change between the default assignees of the components,
+// returns id of created Gitea issue comment or gitea.NullID if cannot create comment
+func (importer *Importer) importDefaultAssigneeIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string, componentMap map[string]string) (int64, error) {
+	prevAssigneeID, err := importer.getDefaultAssigneeID(change.OldValue, componentMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	assigneeID, err := importer.getDefaultAssigneeID(change.NewValue, componentMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	if assigneeID == prevAssigneeID {
+		return gitea.NullID, nil
+	}
+
+	issueComment, err := importer.createIssueComment(issueID, change, userMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	issueComment.CommentType = gitea.AssigneeIssueCommentType
+	issueComment.AssigneeID = assigneeID
+	if assigneeID == gitea.NullID {
+		issueComment.RemovedAssigneeID = prevAssigneeID
+	}
+	issueCommentID, err := importer.giteaAccessor.AddIssueComment(issueID, issueComment)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	return issueCommentID, nil
+}
@@ -34,7 +34,7 @@ func TestImportTicketOwnershipChange(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
 }
 
 func TestImportTicketOwnershipRemoval(t *testing.T) {
@@ -65,5 +65,5 @@ func TestImportTicketOwnershipRemoval(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
 }
@@ -9,27 +9,56 @@ import (
 	"github.com/stevejefferson/trac2gitea/accessor/trac"
 )
 
-// importStatusChangeIssueComment imports a Trac ticket status change into Gitea, returns id of created Gitea issue comment or NullID if cannot create comment
-func (importer *Importer) importStatusChangeIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string) (int64, error) {
-	issueComment, err := importer.createIssueComment(issueID, change, userMap)
-	if err != nil {
-		return gitea.NullID, err
+// importStatusChangeIssueComment imports a Trac ticket status change into Gitea, returns id of last created Gitea issue comment or NullID if cannot create comment.
+// A change between statuses mapped onto open and closed issues closes or reopens the issue,
+// a change between statuses mapped onto different labels removes the label of the old status and adds that of the new one.
+func (importer *Importer) importStatusChangeIssueComment(issueID int64, change *trac.TicketChange, userMap map[string]string, statusMap map[string]*StatusMapping) (int64, error) {
+	issueCommentID := gitea.NullID
+	prevStatusMapping := getStatusMapping(change.OldValue, statusMap)
+	statusMapping := getStatusMapping(change.NewValue, statusMap)
+
+	// reopening a ticket implies that it was previously closed, whatever status we were given for it
+	// - the initial status of a ticket only gives the issue its label: the issue is created open or closed as appropriate
+	prevClosed := prevStatusMapping.Closed || (change.NewValue == trac.TicketStatusReopened && !statusMapping.Closed)
+	if statusMapping.Closed != prevClosed && !change.Initial {
+		issueComment, err := importer.createIssueComment(issueID, change, userMap)
+		if err != nil {
+			return gitea.NullID, err
+		}
+
+		issueComment.CommentType = gitea.ReopenIssueCommentType
+		if statusMapping.Closed {
+			issueComment.CommentType = gitea.CloseIssueCommentType
+		}
+		issueCommentID, err = importer.giteaAccessor.AddIssueComment(issueID, issueComment)
+		if err != nil {
+			return gitea.NullID, err
+		}
 	}
 
-	var giteaCommentType gitea.IssueCommentType
-	switch change.NewValue {
-	case trac.TicketStatusClosed:
-		giteaCommentType = gitea.CloseIssueCommentType
-	case trac.TicketStatusReopened:
-		giteaCommentType = gitea.ReopenIssueCommentType
-	default:
-		return gitea.NullID, nil
+	if prevStatusMapping.Label == statusMapping.Label {
+		return issueCommentID, nil
 	}
 
-	issueComment.CommentType = giteaCommentType
-	issueCommentID, err := importer.giteaAccessor.AddIssueComment(issueID, issueComment)
-	if err != nil {
-		return gitea.NullID, err
+	labelMap := statusLabelMap(statusMap)
+	if change.OldValue != "" {
+		labelCommentID, err := importer.addLabelChangeIssueComment(issueID, change, change.OldValue, false, userMap, labelMap)
+		if err != nil {
+			return gitea.NullID, err
+		}
+		if labelCommentID != gitea.NullID {
+			issueCommentID = labelCommentID
+		}
 	}
+	if change.NewValue != "" {
+		labelCommentID, err := importer.addLabelChangeIssueComment(issueID, change, change.NewValue, true, userMap, labelMap)
+		if err != nil {
+			return gitea.NullID, err
+		}
+		if labelCommentID != gitea.NullID {
+			issueCommentID = labelCommentID
+		}
+	}
+
 	return issueCommentID, nil
 }
@@ -34,7 +34,7 @@ func TestImportTicketClose(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
 }
 
 func TestImportTicketReopen(t *testing.T) {
@@ -65,5 +65,176 @@ func TestImportTicketReopen(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketWithWorkflowStatus(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// ticket has custom workflow status mapped onto a closed issue with a label
+	openTicket.status = verifiedStatus.tracName
+	openTicket.closed = true
+	openTicket.statusLabel = verifiedStatus
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us no ticket changes
+	expectTracChangeRetrievals(t, openTicket)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketWorkflowStatusChangeBetweenOpenStatuses(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one change from "accepted" to "testing"
+	expectTracChangeRetrievals(t, openTicket, testingTicketChange)
+
+	// expect label changes but no change to issue state
+	expectAllTicketWorkflowStatusActions(t, openTicket, testingTicketChange, false)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, testingTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketWorkflowStatusChangeToClosedStatus(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one change from "testing" to "verified"
+	expectTracChangeRetrievals(t, openTicket, verifiedTicketChange)
+
+	// expect issue to be closed and label changes
+	expectAllTicketWorkflowStatusActions(t, openTicket, verifiedTicketChange, true)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, verifiedTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketInitialWorkflowStatusThenStatusChange(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// ticket is created "accepted" then changed to "testing"
+	openTicket.status = testingStatus.tracName
+	openTicket.statusLabel = testingStatus
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us the initial status of the ticket and one change from "accepted" to "testing"
+	expectTracChangeRetrievals(t, openTicket, initialAcceptedTicketChange, testingTicketChange)
+
+	// expect label of initial status to be added so that the later change has a label to remove
+	expectUserLookup(t, initialAcceptedTicketChange.author)
+	expectIssueCommentCreationForLabelChange(t, openTicket, initialAcceptedTicketChange, acceptedStatus, true)
+
+	// expect label changes but no change to issue state
+	expectAllTicketWorkflowStatusActions(t, openTicket, testingTicketChange, false)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, initialAcceptedTicketChange, testingTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketInitialClosedWorkflowStatus(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// ticket has custom workflow status mapped onto a closed issue with a label
+	openTicket.status = verifiedStatus.tracName
+	openTicket.closed = true
+	openTicket.statusLabel = verifiedStatus
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us only the initial status of the ticket
+	expectTracChangeRetrievals(t, openTicket, initialVerifiedTicketChange)
+
+	// expect label of initial status to be added but no close comment as the issue is created closed
+	expectUserLookup(t, initialVerifiedTicketChange.author)
+	expectIssueCommentCreationForLabelChange(t, openTicket, initialVerifiedTicketChange, verifiedStatus, true)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket, initialVerifiedTicketChange)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
 }
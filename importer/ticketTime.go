@@ -0,0 +1,83 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// names of the custom fields used by the TimingAndEstimation plugin - these are imported as Gitea time tracking data rather than converted
+const (
+	hoursCustomFieldName          = "hours"          // hours worked, each change to which is a work log entry
+	estimatedHoursCustomFieldName = "estimatedhours" // estimated hours
+	totalHoursCustomFieldName     = "totalhours"     // running total of hours worked - Gitea calculates this itself
+)
+
+// hoursToSeconds converts a Trac hours value into seconds, returns false if value is not a valid number of hours.
+func hoursToSeconds(hours string) (int64, bool) {
+	hoursValue, err := strconv.ParseFloat(strings.TrimSpace(hours), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return int64(math.Round(hoursValue * 3600)), true
+}
+
+// importTicketTimeEstimate imports the estimated hours of a Trac ticket as the time estimate of its Gitea issue.
+func (importer *Importer) importTicketTimeEstimate(issueID int64, ticket *trac.Ticket) error {
+	for _, customField := range ticket.CustomFields {
+		if customField.Field.Name != estimatedHoursCustomFieldName {
+			continue
+		}
+
+		seconds, ok := hoursToSeconds(customField.Value)
+		if !ok {
+			log.Warn("invalid estimated hours \"%s\" for Trac ticket %d - ignored", customField.Value, ticket.TicketID)
+			return nil
+		}
+		if seconds == 0 {
+			return nil
+		}
+
+		return importer.giteaAccessor.SetIssueTimeEstimate(issueID, seconds)
+	}
+
+	return nil
+}
+
+// importHoursChange imports a change to the hours worked on a Trac ticket as time tracked against the Gitea issue by the author of the change.
+// No Gitea issue comment is created for this so NullID is always returned.
+func (importer *Importer) importHoursChange(issueID int64, change *trac.TicketChange, userMap map[string]string) (int64, error) {
+	seconds, ok := hoursToSeconds(change.NewValue)
+	if !ok {
+		log.Warn("invalid hours \"%s\" recorded on Trac ticket %d - ignored", change.NewValue, change.TicketID)
+		return gitea.NullID, nil
+	}
+	if seconds == 0 {
+		return gitea.NullID, nil
+	}
+
+	userID, err := importer.getUserID(change.Author, userMap)
+	if err != nil {
+		return gitea.NullID, err
+	}
+	if userID == gitea.NullID {
+		userID = importer.defaultAuthorID
+	}
+
+	trackedTime := gitea.TrackedTime{UserID: userID, Seconds: seconds, Time: change.Time}
+	err = importer.giteaAccessor.AddIssueTrackedTime(issueID, &trackedTime)
+	if err != nil {
+		return gitea.NullID, err
+	}
+
+	return gitea.NullID, nil
+}
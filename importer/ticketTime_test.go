@@ -0,0 +1,78 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+)
+
+func TestImportTicketWithTimeEstimate(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	openTicket.customFields = []trac.TicketCustomField{{Field: estimatedHoursCustomField, Value: estimatedHours}}
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect estimated hours to be set as issue time estimate
+	expectIssueTimeEstimateToBeSet(t, openTicket, estimatedHoursSeconds)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us no changes
+	expectTracChangeRetrievals(t, openTicket)
+
+	// expect issue update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketHoursChange(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, openTicket)
+
+	// expect all actions for creating Gitea issue from Trac ticket
+	expectAllTicketActions(t, openTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, openTicket)
+
+	// expect trac to return us one hours change
+	expectTracChangeRetrievals(t, openTicket, hoursTicketChange)
+
+	// expect to lookup Gitea equivalent of author of Trac ticket change
+	expectUserLookup(t, hoursTicketChange.author)
+
+	// expect hours to be recorded as time tracked by change author
+	expectIssueTrackedTimeCreation(t, openTicket, hoursTicketChange, workedHoursSeconds)
+
+	// expect issue update time to be updated - hours change does not create a comment so does not count
+	expectIssueUpdateTimeSetToLatestOf(t, openTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, openTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
+}
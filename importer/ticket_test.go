@@ -33,7 +33,7 @@ func TestImportClosedTicketOnly(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
 }
 
 func TestImportOpenTicketOnly(t *testing.T) {
@@ -61,7 +61,7 @@ func TestImportOpenTicketOnly(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
 }
 
 func TestImportMultipleTicketsOnly(t *testing.T) {
@@ -94,7 +94,7 @@ func TestImportMultipleTicketsOnly(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
 }
 
 func TestImportTicketWithNoTracUser(t *testing.T) {
@@ -122,7 +122,7 @@ func TestImportTicketWithNoTracUser(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
 }
 
 func TestImportTicketWithUnmappedTracUser(t *testing.T) {
@@ -150,5 +150,33 @@ func TestImportTicketWithUnmappedTracUser(t *testing.T) {
 	// expect all issue counts to be updated
 	expectIssueCountUpdates(t)
 
-	dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	dataImporter.ImportTickets(ticketMaps())
+}
+
+func TestImportTicketWithUnmappedReporterAndMappedOwner(t *testing.T) {
+	setUpTickets(t)
+	defer tearDown(t)
+
+	// first thing to expect is retrieval of ticket from Trac
+	expectTracTicketRetrievals(t, unmappedReporterTicket)
+
+	// expect all actions for creating Gitea issues from Trac tickets - the unmapped reporter (not the mapped owner) is the original author
+	expectAllTicketActions(t, unmappedReporterTicket)
+
+	// expect trac to return us no attachments
+	expectTracAttachmentRetrievals(t, unmappedReporterTicket)
+
+	// expect trac to return us no changes
+	expectTracChangeRetrievals(t, unmappedReporterTicket)
+
+	// expect issues update time to be updated
+	expectIssueUpdateTimeSetToLatestOf(t, unmappedReporterTicket)
+
+	// expect issue comment count to be updated
+	expectIssueCommentCountUpdate(t, unmappedReporterTicket)
+
+	// expect all issue counts to be updated
+	expectIssueCountUpdates(t)
+
+	dataImporter.ImportTickets(ticketMaps())
 }
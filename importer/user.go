@@ -6,28 +6,64 @@ package importer
 
 import (
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
 	"github.com/stevejefferson/trac2gitea/log"
 )
 
 // regexp for matching a user: $1=username (may have space padding) $2=user email (optional)
 var userRegexp = regexp.MustCompile(`([^<]*)(?:<([^>]+)>)?`)
 
-// DefaultUserMap retrieves the default mapping between Trac users and Gitea users
+// readUserProfiles retrieves the profiles recorded by Trac for its users, indexed by user name
+func (importer *Importer) readUserProfiles() (map[string]*trac.UserProfile, error) {
+	userProfiles := make(map[string]*trac.UserProfile)
+	err := importer.tracAccessor.GetUserProfiles(func(profile *trac.UserProfile) error {
+		userProfiles[profile.UserName] = profile
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return userProfiles, nil
+}
+
+// DefaultUserMap retrieves the default mapping between Trac users and Gitea users.
+// Where Trac records a full name or email address for a user in their preferences, these are also used to find the Gitea user.
 func (importer *Importer) DefaultUserMap() (map[string]string, error) {
-	userMap := make(map[string]string)
+	userProfiles, err := importer.readUserProfiles()
+	if err != nil {
+		return nil, err
+	}
 
-	err := importer.tracAccessor.GetUserNames(func(user string) error {
+	userMap := make(map[string]string)
+	err = importer.tracAccessor.GetUserNames(func(user string) error {
 		userName := userRegexp.ReplaceAllString(user, `$1`)
 		trimmedUserName := strings.Trim(userName, " ")
 		userEmail := userRegexp.ReplaceAllString(user, `$2`)
+		if userEmail == "" && strings.Contains(trimmedUserName, "@") {
+			// bare email address (e.g. from a ticket Cc list)
+			userEmail = trimmedUserName
+		}
+		userProfile := userProfiles[trimmedUserName]
+		if userEmail == "" && userProfile != nil {
+			userEmail = userProfile.Email
+		}
 
 		matchedUserName, err := importer.giteaAccessor.MatchUser(trimmedUserName, userEmail)
 		if err != nil {
 			return err
 		}
+		if matchedUserName == "" && userProfile != nil && userProfile.FullName != "" && userProfile.FullName != trimmedUserName {
+			matchedUserName, err = importer.giteaAccessor.MatchUser(userProfile.FullName, userEmail)
+			if err != nil {
+				return err
+			}
+		}
 		log.Debug("matched user \"%s\", email \"%s\" to \"%s\"", userName, userEmail, matchedUserName)
 
 		userMap[user] = matchedUserName
@@ -41,11 +77,63 @@ func (importer *Importer) DefaultUserMap() (map[string]string, error) {
 	return userMap, nil
 }
 
-// getUserID retrieves the Gitea user ID corresponding to a Trac user name
+// tracUserExternalIDs returns the external ids by which a Trac user may be identified in a Gitea login source:
+// the Trac login (without any email address) and then the user's email address, if known.
+func tracUserExternalIDs(tracUser string) []string {
+	externalIDs := []string{}
+	userName := strings.Trim(userRegexp.ReplaceAllString(tracUser, `$1`), " ")
+	if userName != "" {
+		externalIDs = append(externalIDs, userName)
+	}
+	userEmail := strings.Trim(userRegexp.ReplaceAllString(tracUser, `$2`), " ")
+	if userEmail != "" && userEmail != userName {
+		externalIDs = append(externalIDs, userEmail)
+	}
+
+	return externalIDs
+}
+
+// tracUserOriginalAuthorID returns the "original author id" to record on Gitea issues and comments by a Trac user with no Gitea equivalent.
+// Gitea hands such content over to a user on them linking an external account whose external id matches the original author id
+// but Gitea stores original author ids as numbers, so this can only match a Trac login which is itself a numeric external id:
+// no id (0) is recorded otherwise, and no id can be recorded that would match an external id which is not a number (such as a user name or email address).
+func tracUserOriginalAuthorID(tracUser string) int64 {
+	userName := strings.Trim(userRegexp.ReplaceAllString(tracUser, `$1`), " ")
+	originalAuthorID, err := strconv.ParseInt(userName, 10, 64)
+	if err != nil || originalAuthorID < 0 {
+		return 0
+	}
+
+	return originalAuthorID
+}
+
+// ImportOriginalServiceType records the git service after which the configured login source is named (if any) as the service
+// from which the Gitea repository was migrated: Gitea only hands content by original authors over to users linking accounts from a login source
+// in repositories migrated from the service of the same name.
+func (importer *Importer) ImportOriginalServiceType() error {
+	if importer.loginSourceID == gitea.NullID {
+		return nil
+	}
+
+	serviceType, err := importer.giteaAccessor.GetLoginSourceServiceType(importer.loginSourceID)
+	if err != nil {
+		return err
+	}
+	if serviceType == 0 {
+		log.Warn("login source %d is not named after a git service from which Gitea migrates repositories (or cannot be accessed): "+
+			"Gitea will not hand content by unmapped Trac users over to users linking accounts from it - rerun the import with --overwrite instead", importer.loginSourceID)
+		return nil
+	}
+
+	return importer.giteaAccessor.SetRepoOriginalServiceType(serviceType)
+}
+
+// getUserID retrieves the Gitea user ID corresponding to a Trac user name.
+// A Trac user with no mapping is identified with any Gitea user who has linked an account with the Trac user's login or email address from the configured login source.
 func (importer *Importer) getUserID(tracUser string, userMap map[string]string) (int64, error) {
 	giteaUserName := userMap[tracUser]
 	if giteaUserName == "" {
-		return gitea.NullID, nil
+		return importer.getExternalUserID(tracUser)
 	}
 
 	userID, err := importer.giteaAccessor.GetUserID(giteaUserName)
@@ -56,3 +144,131 @@ func (importer *Importer) getUserID(tracUser string, userMap map[string]string)
 	log.Debug("mapped Trac user %s onto Gitea user %s", tracUser, giteaUserName)
 	return userID, nil
 }
+
+// getExternalUserID retrieves the id of the Gitea user who has linked an account from the configured login source
+// whose external id is the Trac login or email address of a Trac user - returns NullID if no login source is configured or there is no such user.
+func (importer *Importer) getExternalUserID(tracUser string) (int64, error) {
+	if importer.loginSourceID == gitea.NullID {
+		return gitea.NullID, nil
+	}
+
+	for _, externalID := range tracUserExternalIDs(tracUser) {
+		userID, err := importer.giteaAccessor.GetExternalUserID(importer.loginSourceID, externalID)
+		if err != nil {
+			return gitea.NullID, err
+		}
+		if userID != gitea.NullID {
+			log.Debug("mapped Trac user %s onto Gitea user %d linked to %s through login source %d", tracUser, userID, externalID, importer.loginSourceID)
+			return userID, nil
+		}
+	}
+
+	return gitea.NullID, nil
+}
+
+// regexp for runs of characters not permitted in Gitea user names
+var invalidUserNameRegexp = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// placeholderUserName returns the name of the placeholder Gitea user for a Trac user: "%s" in the name format is replaced by the Trac user name
+// with any email address removed and any characters not permitted by Gitea replaced.
+func placeholderUserName(tracUser string, nameFormat string) string {
+	userName := strings.Trim(userRegexp.ReplaceAllString(tracUser, `$1`), " ")
+	if userName == "" {
+		userName = userRegexp.ReplaceAllString(tracUser, `$2`)
+	}
+	if atPos := strings.Index(userName, "@"); atPos != -1 {
+		userName = userName[0:atPos]
+	}
+	userName = strings.Trim(invalidUserNameRegexp.ReplaceAllString(userName, "-"), "-_.")
+	if userName == "" {
+		return ""
+	}
+
+	return strings.ReplaceAll(nameFormat, "%s", userName)
+}
+
+// createPlaceholderUser creates a placeholder Gitea user for a Trac user, returns the name of the Gitea user or "" if none could be created.
+func (importer *Importer) createPlaceholderUser(tracUser string, nameFormat string, userProfiles map[string]*trac.UserProfile) (string, error) {
+	userName := placeholderUserName(tracUser, nameFormat)
+	if userName == "" {
+		log.Warn("cannot create placeholder Gitea user name for Trac user \"%s\"", tracUser)
+		return "", nil
+	}
+
+	// a previous import may already have created the placeholder user
+	userID, err := importer.giteaAccessor.GetUserID(userName)
+	if err != nil {
+		return "", err
+	}
+	if userID != gitea.NullID {
+		log.Debug("found existing Gitea user %s for Trac user %s", userName, tracUser)
+		return userName, nil
+	}
+
+	trimmedUserName := strings.Trim(userRegexp.ReplaceAllString(tracUser, `$1`), " ")
+	userEmail := userRegexp.ReplaceAllString(tracUser, `$2`)
+	if userEmail == "" && strings.Contains(trimmedUserName, "@") {
+		userEmail = trimmedUserName
+	}
+	fullName := ""
+	if userProfile := userProfiles[trimmedUserName]; userProfile != nil {
+		fullName = userProfile.FullName
+		if userEmail == "" {
+			userEmail = userProfile.Email
+		}
+	}
+
+	// Gitea email addresses must be unique - one belonging to an existing user cannot be reused
+	if userEmail != "" {
+		emailUserID, err := importer.giteaAccessor.GetUserID(userEmail)
+		if err != nil {
+			return "", err
+		}
+		if emailUserID != gitea.NullID {
+			log.Warn("email address %s of Trac user %s belongs to an existing Gitea user - not used for placeholder user %s", userEmail, tracUser, userName)
+			userEmail = ""
+		}
+	}
+
+	user := gitea.User{Name: userName, FullName: fullName, Email: userEmail}
+	userID, err = importer.giteaAccessor.AddPlaceholderUser(&user)
+	if err != nil {
+		return "", err
+	}
+	if userID == gitea.NullID {
+		return "", nil
+	}
+
+	log.Info("created placeholder Gitea user %s for Trac user %s", userName, tracUser)
+	return userName, nil
+}
+
+// CreatePlaceholderUsers creates restricted Gitea users who cannot log in for all Trac users in the user map with no Gitea equivalent.
+// The user name of each placeholder user is formed from the provided name format, in which "%s" is replaced by the Trac user name.
+// The user map is updated with the created users.
+func (importer *Importer) CreatePlaceholderUsers(userMap map[string]string, nameFormat string) error {
+	userProfiles, err := importer.readUserProfiles()
+	if err != nil {
+		return err
+	}
+
+	tracUsers := []string{}
+	for tracUser, giteaUser := range userMap {
+		if giteaUser == "" && strings.Trim(tracUser, " ") != "" {
+			tracUsers = append(tracUsers, tracUser)
+		}
+	}
+	sort.Strings(tracUsers)
+
+	for _, tracUser := range tracUsers {
+		giteaUser, err := importer.createPlaceholderUser(tracUser, nameFormat, userProfiles)
+		if err != nil {
+			return err
+		}
+		if giteaUser != "" {
+			userMap[tracUser] = giteaUser
+		}
+	}
+
+	return nil
+}
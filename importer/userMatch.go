@@ -0,0 +1,278 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// UserMatch is the Gitea user best matching a Trac user according to fuzzy matching.
+type UserMatch struct {
+	GiteaUser  string
+	Confidence float64 // between 0 (no similarity) and 1 (certain)
+	Ambiguous  bool    // true if other Gitea users match equally well
+}
+
+// UncertainUserMatchConfidence is the confidence below which a fuzzy user match is considered uncertain and should be reviewed.
+const UncertainUserMatchConfidence = 0.85
+
+// confidence below which a Gitea user is not considered a candidate for a Trac user at all
+const minUserMatchConfidence = 0.5
+
+// IsUncertain returns true if a fuzzy user match should be reviewed before use.
+func (match *UserMatch) IsUncertain() bool {
+	return match.Confidence < UncertainUserMatchConfidence || match.Ambiguous
+}
+
+// transliterations of non-ASCII letters commonly found in names
+var nameTransliterations = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ą': "a", 'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ę': "e", 'ě': "e", 'ğ': "g",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ı': "i", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ß': "ss", 'ť': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ů': "u", 'ű': "u", 'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// regexp for runs of characters separating the parts of a name
+var nameSeparatorRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalizeName returns the parts of a name in lower case, transliterated to ASCII and without punctuation.
+func normalizeName(name string) []string {
+	var transliteratedName strings.Builder
+	for _, char := range strings.ToLower(name) {
+		if transliteration, found := nameTransliterations[char]; found {
+			transliteratedName.WriteString(transliteration)
+		} else {
+			transliteratedName.WriteRune(char)
+		}
+	}
+
+	return strings.Fields(nameSeparatorRegexp.ReplaceAllString(transliteratedName.String(), " "))
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(str1 string, str2 string) int {
+	runes1, runes2 := []rune(str1), []rune(str2)
+	prevRow := make([]int, len(runes2)+1)
+	for index := range prevRow {
+		prevRow[index] = index
+	}
+
+	for index1 := 1; index1 <= len(runes1); index1++ {
+		row := make([]int, len(runes2)+1)
+		row[0] = index1
+		for index2 := 1; index2 <= len(runes2); index2++ {
+			cost := 1
+			if runes1[index1-1] == runes2[index2-1] {
+				cost = 0
+			}
+			row[index2] = min3(prevRow[index2]+1, row[index2-1]+1, prevRow[index2-1]+cost)
+		}
+		prevRow = row
+	}
+
+	return prevRow[len(runes2)]
+}
+
+func min3(val1 int, val2 int, val3 int) int {
+	minVal := val1
+	if val2 < minVal {
+		minVal = val2
+	}
+	if val3 < minVal {
+		minVal = val3
+	}
+	return minVal
+}
+
+// similarity returns the similarity of two strings, between 0 (nothing in common) and 1 (identical).
+func similarity(str1 string, str2 string) float64 {
+	maxLen := len([]rune(str1))
+	if len2 := len([]rune(str2)); len2 > maxLen {
+		maxLen = len2
+	}
+	if maxLen == 0 {
+		return 0
+	}
+
+	return 1 - float64(editDistance(str1, str2))/float64(maxLen)
+}
+
+// nameSimilarity returns the similarity of two normalized names, allowing for their parts being in a different order (e.g. "Smith John").
+func nameSimilarity(nameParts1 []string, nameParts2 []string) float64 {
+	if len(nameParts1) == 0 || len(nameParts2) == 0 {
+		return 0
+	}
+
+	name1, name2 := strings.Join(nameParts1, " "), strings.Join(nameParts2, " ")
+	if name1 == name2 {
+		return 0.95
+	}
+
+	sortedParts1 := append([]string{}, nameParts1...)
+	sort.Strings(sortedParts1)
+	sortedParts2 := append([]string{}, nameParts2...)
+	sort.Strings(sortedParts2)
+	sortedName1, sortedName2 := strings.Join(sortedParts1, " "), strings.Join(sortedParts2, " ")
+	if sortedName1 == sortedName2 {
+		return 0.9
+	}
+
+	bestSimilarity := similarity(name1, name2)
+	if sortedSimilarity := similarity(sortedName1, sortedName2); sortedSimilarity > bestSimilarity {
+		bestSimilarity = sortedSimilarity
+	}
+	return bestSimilarity * 0.8
+}
+
+// loginSimilarity returns the similarity of a normalized login name (user name or email local part) and the normalized parts of a user or full name.
+// Logins formed by joining the parts of a full name (e.g. "johnsmith") or from an initial and a surname (e.g. "jsmith") are also recognised.
+func loginSimilarity(login []string, nameParts []string) float64 {
+	if len(login) == 0 || len(nameParts) == 0 {
+		return 0
+	}
+
+	joinedLogin := strings.Join(login, "")
+	joinedName := strings.Join(nameParts, "")
+	if joinedLogin == joinedName {
+		return 0.9
+	}
+	if len(nameParts) > 1 {
+		var initials strings.Builder
+		for _, namePart := range nameParts[0 : len(nameParts)-1] {
+			initials.WriteString(namePart[0:1])
+		}
+		if joinedLogin == initials.String()+nameParts[len(nameParts)-1] {
+			return 0.8
+		}
+	}
+
+	return similarity(joinedLogin, joinedName) * 0.8
+}
+
+// userDetails holds the normalized details of a Trac or Gitea user used for fuzzy matching.
+type userDetails struct {
+	name      []string
+	fullName  []string
+	email     string
+	emailName []string
+}
+
+// createUserDetails returns the normalized details of a user.
+func createUserDetails(name string, fullName string, email string) *userDetails {
+	details := userDetails{name: normalizeName(name), fullName: normalizeName(fullName), email: strings.ToLower(email)}
+	if atPos := strings.Index(details.email, "@"); atPos != -1 {
+		details.emailName = normalizeName(details.email[0:atPos])
+	}
+
+	return &details
+}
+
+// userMatchConfidence returns our confidence that a Trac user and a Gitea user are the same person.
+func userMatchConfidence(tracUser *userDetails, giteaUser *userDetails) float64 {
+	if tracUser.email != "" && tracUser.email == giteaUser.email {
+		return 1
+	}
+	if len(tracUser.name) > 0 && strings.Join(tracUser.name, "") == strings.Join(giteaUser.name, "") {
+		return 0.95
+	}
+
+	confidences := []float64{
+		nameSimilarity(tracUser.fullName, giteaUser.fullName),
+		loginSimilarity(tracUser.name, giteaUser.fullName),
+		loginSimilarity(giteaUser.name, tracUser.fullName),
+		loginSimilarity(tracUser.emailName, giteaUser.name),
+		loginSimilarity(tracUser.emailName, giteaUser.fullName),
+		loginSimilarity(tracUser.name, giteaUser.name),
+	}
+
+	bestConfidence := 0.0
+	for _, confidence := range confidences {
+		if confidence > bestConfidence {
+			bestConfidence = confidence
+		}
+	}
+	return bestConfidence
+}
+
+// FuzzyMatchUsers finds the Gitea user most similar to each Trac user with no Gitea equivalent in the user map.
+// Similarity is judged on normalized (and transliterated) user and full names, allowing for swapped first and last names,
+// and on the local part of the Trac user's email address.
+// Returns the best match for each Trac user with a candidate Gitea user: the user map itself is not updated.
+func (importer *Importer) FuzzyMatchUsers(userMap map[string]string) (map[string]*UserMatch, error) {
+	giteaUsers := []*gitea.User{}
+	err := importer.giteaAccessor.GetUsers(func(user *gitea.User) error {
+		giteaUsers = append(giteaUsers, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(giteaUsers, func(i, j int) bool { return giteaUsers[i].Name < giteaUsers[j].Name })
+
+	giteaUserDetails := []*userDetails{}
+	for _, giteaUser := range giteaUsers {
+		giteaUserDetails = append(giteaUserDetails, createUserDetails(giteaUser.Name, giteaUser.FullName, giteaUser.Email))
+	}
+
+	userProfiles, err := importer.readUserProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	userMatches := make(map[string]*UserMatch)
+	for tracUser, giteaUser := range userMap {
+		if giteaUser != "" {
+			continue
+		}
+
+		userName := strings.Trim(userRegexp.ReplaceAllString(tracUser, `$1`), " ")
+		userEmail := userRegexp.ReplaceAllString(tracUser, `$2`)
+		if userEmail == "" && strings.Contains(userName, "@") {
+			userEmail = userName
+			userName = ""
+		}
+		fullName := ""
+		if strings.Contains(userName, " ") {
+			// Trac user names are sometimes full names
+			fullName = userName
+		}
+		if userProfile := userProfiles[userName]; userProfile != nil {
+			if userProfile.FullName != "" {
+				fullName = userProfile.FullName
+			}
+			if userEmail == "" {
+				userEmail = userProfile.Email
+			}
+		}
+		tracUserDetails := createUserDetails(userName, fullName, userEmail)
+
+		var bestMatch *UserMatch
+		for index, giteaUser := range giteaUsers {
+			confidence := userMatchConfidence(tracUserDetails, giteaUserDetails[index])
+			switch {
+			case confidence < minUserMatchConfidence:
+				continue
+			case bestMatch == nil || confidence > bestMatch.Confidence:
+				bestMatch = &UserMatch{GiteaUser: strings.ToLower(giteaUser.Name), Confidence: confidence}
+			case confidence == bestMatch.Confidence:
+				bestMatch.Ambiguous = true
+			}
+		}
+		if bestMatch != nil {
+			log.Debug("fuzzy matched user \"%s\" to \"%s\" with confidence %.2f", tracUser, bestMatch.GiteaUser, bestMatch.Confidence)
+			userMatches[tracUser] = bestMatch
+		}
+	}
+
+	return userMatches, nil
+}
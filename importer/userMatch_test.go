@@ -0,0 +1,127 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/importer"
+)
+
+func expectToRetrieveGiteaUsers(t *testing.T, users ...*gitea.User) {
+	mockGiteaAccessor.
+		EXPECT().
+		GetUsers(gomock.Any()).
+		DoAndReturn(func(handlerFn func(user *gitea.User) error) error {
+			for _, user := range users {
+				handlerFn(user)
+			}
+			return nil
+		})
+}
+
+func fuzzyMatchUser(t *testing.T, tracUser string, giteaUsers ...*gitea.User) *importer.UserMatch {
+	expectToRetrieveGiteaUsers(t, giteaUsers...)
+	userMatches, err := dataImporter.FuzzyMatchUsers(map[string]string{tracUser: ""})
+	assertTrue(t, err == nil)
+	return userMatches[tracUser]
+}
+
+func TestFuzzyMatchUserOnSwappedFullName(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	expectToRetrieveTracUserProfiles(t, &trac.UserProfile{UserName: "jb", FullName: "Bloggs, Joe"})
+	match := fuzzyMatchUser(t, "jb",
+		&gitea.User{Name: "jbloggs", FullName: "Joe Bloggs"},
+		&gitea.User{Name: "fbloggs", FullName: "Fred Bloggs"})
+	assertEquals(t, match.GiteaUser, "jbloggs")
+	assertTrue(t, !match.IsUncertain())
+}
+
+func TestFuzzyMatchUserOnTransliteratedName(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	expectToRetrieveTracUserProfiles(t)
+	match := fuzzyMatchUser(t, "José García",
+		&gitea.User{Name: "jgarcia", FullName: "Jose Garcia"},
+		&gitea.User{Name: "jgreen", FullName: "Jane Green"})
+	assertEquals(t, match.GiteaUser, "jgarcia")
+	assertTrue(t, !match.IsUncertain())
+}
+
+func TestFuzzyMatchUserOnEmailLocalPart(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	expectToRetrieveTracUserProfiles(t)
+	match := fuzzyMatchUser(t, "Jane <jane.doe@old.example.com>",
+		&gitea.User{Name: "jane-doe", Email: "jane@new.example.com"},
+		&gitea.User{Name: "john-doe", Email: "john@new.example.com"})
+	assertEquals(t, match.GiteaUser, "jane-doe")
+	assertTrue(t, !match.IsUncertain())
+}
+
+func TestFuzzyMatchUserOnInitialAndSurname(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	expectToRetrieveTracUserProfiles(t)
+	match := fuzzyMatchUser(t, "Anne Smith",
+		&gitea.User{Name: "asmith"})
+	assertEquals(t, match.GiteaUser, "asmith")
+	assertTrue(t, match.IsUncertain())
+}
+
+func TestFuzzyMatchUserWithTypoIsUncertain(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	expectToRetrieveTracUserProfiles(t)
+	match := fuzzyMatchUser(t, "jonhsmith",
+		&gitea.User{Name: "johnsmith"},
+		&gitea.User{Name: "admin"})
+	assertEquals(t, match.GiteaUser, "johnsmith")
+	assertTrue(t, match.IsUncertain())
+	assertTrue(t, match.Confidence < importer.UncertainUserMatchConfidence)
+}
+
+func TestAmbiguousFuzzyMatchUserIsUncertain(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	expectToRetrieveTracUserProfiles(t)
+	match := fuzzyMatchUser(t, "Chris Jones",
+		&gitea.User{Name: "cjones", FullName: "Chris Jones"},
+		&gitea.User{Name: "chris-jones", FullName: "Chris Jones"})
+	assertTrue(t, match.Ambiguous)
+	assertTrue(t, match.IsUncertain())
+}
+
+func TestFuzzyMatchUserWithNoCandidate(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	expectToRetrieveTracUserProfiles(t)
+	match := fuzzyMatchUser(t, "zebedee",
+		&gitea.User{Name: "admin"},
+		&gitea.User{Name: "alice", FullName: "Alice Andrews"})
+	assertTrue(t, match == nil)
+}
+
+func TestFuzzyMatchIgnoresMappedUsers(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	expectToRetrieveGiteaUsers(t, &gitea.User{Name: "alice"})
+	expectToRetrieveTracUserProfiles(t)
+	userMatches, err := dataImporter.FuzzyMatchUsers(map[string]string{"alice2": "bob"})
+	assertTrue(t, err == nil)
+	assertEquals(t, len(userMatches), 0)
+}
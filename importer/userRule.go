@@ -0,0 +1,128 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/stevejefferson/trac2gitea/log"
+)
+
+// UserRule is a rule mapping Trac users onto Gitea users, used for Trac users with no explicit entry in the user map.
+type UserRule interface {
+	// MapUser returns the name of the Gitea user onto which the rule maps a Trac user and true, or false if the rule does not apply to the Trac user.
+	MapUser(tracUser string) (string, bool)
+
+	// String returns the rule as written in the user map.
+	String() string
+}
+
+// patternUserRule maps Trac users matching a regular expression onto a Gitea user name which may refer to submatches of the expression (as $1 etc.).
+type patternUserRule struct {
+	pattern   *regexp.Regexp
+	giteaUser string
+}
+
+// CreatePatternUserRule returns a rule mapping Trac users matching a regular expression onto a Gitea user name which may refer to submatches of the expression.
+func CreatePatternUserRule(pattern string, giteaUser string) (UserRule, error) {
+	patternRegexp, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return &patternUserRule{pattern: patternRegexp, giteaUser: giteaUser}, nil
+}
+
+func (rule *patternUserRule) MapUser(tracUser string) (string, bool) {
+	match := rule.pattern.FindStringSubmatchIndex(tracUser)
+	if match == nil {
+		return "", false
+	}
+
+	return string(rule.pattern.ExpandString(nil, rule.giteaUser, tracUser, match)), true
+}
+
+func (rule *patternUserRule) String() string {
+	return "/" + rule.pattern.String() + "/ = " + rule.giteaUser
+}
+
+// domainUserRule maps Trac users whose email addresses belong to a domain onto a single Gitea user.
+type domainUserRule struct {
+	domain    string
+	giteaUser string
+}
+
+// CreateDomainUserRule returns a rule mapping Trac users whose email addresses belong to a domain onto a single Gitea user.
+func CreateDomainUserRule(domain string, giteaUser string) UserRule {
+	return &domainUserRule{domain: strings.ToLower(domain), giteaUser: giteaUser}
+}
+
+func (rule *domainUserRule) MapUser(tracUser string) (string, bool) {
+	userEmail := userRegexp.ReplaceAllString(tracUser, `$2`)
+	if userEmail == "" {
+		userEmail = strings.Trim(tracUser, " ")
+	}
+	atPos := strings.LastIndex(userEmail, "@")
+	if atPos == -1 || strings.ToLower(userEmail[atPos+1:]) != rule.domain {
+		return "", false
+	}
+
+	return rule.giteaUser, true
+}
+
+func (rule *domainUserRule) String() string {
+	return "@" + rule.domain + " = " + rule.giteaUser
+}
+
+// tableUserRule maps Trac users onto Gitea users using a table loaded from elsewhere (e.g. a directory export).
+type tableUserRule struct {
+	source string
+	table  map[string]string
+}
+
+// CreateTableUserRule returns a rule mapping Trac users onto Gitea users using a table, source describes the table as written in the user map.
+func CreateTableUserRule(source string, table map[string]string) UserRule {
+	return &tableUserRule{source: source, table: table}
+}
+
+func (rule *tableUserRule) MapUser(tracUser string) (string, bool) {
+	giteaUser, found := rule.table[tracUser]
+	return giteaUser, found
+}
+
+func (rule *tableUserRule) String() string {
+	return rule.source
+}
+
+// ApplyUserRules maps each Trac user with no entry in the user map using the first of the provided rules which applies to that user.
+// Returns the rule used for each Trac user so mapped.
+func (importer *Importer) ApplyUserRules(userMap map[string]string, rules []UserRule) (map[string]UserRule, error) {
+	appliedRules := make(map[string]UserRule)
+	if len(rules) == 0 {
+		return appliedRules, nil
+	}
+
+	err := importer.tracAccessor.GetUserNames(func(user string) error {
+		if _, found := userMap[user]; found {
+			return nil
+		}
+
+		for _, rule := range rules {
+			if giteaUser, applies := rule.MapUser(user); applies {
+				log.Debug("mapped Trac user \"%s\" onto \"%s\" using rule %s", user, giteaUser, rule)
+				userMap[user] = giteaUser
+				appliedRules[user] = rule
+				return nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return appliedRules, nil
+}
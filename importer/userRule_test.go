@@ -0,0 +1,107 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package importer_test
+
+import (
+	"testing"
+
+	"github.com/stevejefferson/trac2gitea/importer"
+)
+
+const (
+	corpUser        = "jbloggs@corp.example.com"
+	contractorUser  = "Fred Smith <fred@Contractor.com>"
+	directoryUser   = "asmith"
+	unmatchedUser   = "nobody"
+	literalCorpUser = "admin@corp.example.com"
+)
+
+func createUserRules(t *testing.T) []importer.UserRule {
+	patternRule, err := importer.CreatePatternUserRule(`^(.*)@corp\.example\.com$`, "$1")
+	assertTrue(t, err == nil)
+	domainRule := importer.CreateDomainUserRule("contractor.com", "contractors")
+	tableRule := importer.CreateTableUserRule("csv:directory.csv = trac, gitea", map[string]string{directoryUser: "alice.smith", corpUser: "joe.bloggs"})
+
+	return []importer.UserRule{patternRule, domainRule, tableRule}
+}
+
+func TestPatternUserRule(t *testing.T) {
+	rule, err := importer.CreatePatternUserRule(`^(.*)@corp\.example\.com$`, "$1")
+	assertTrue(t, err == nil)
+
+	giteaUser, applies := rule.MapUser(corpUser)
+	assertTrue(t, applies)
+	assertEquals(t, giteaUser, "jbloggs")
+
+	_, applies = rule.MapUser(directoryUser)
+	assertTrue(t, !applies)
+
+	assertEquals(t, rule.String(), `/^(.*)@corp\.example\.com$/ = $1`)
+}
+
+func TestInvalidPatternUserRule(t *testing.T) {
+	_, err := importer.CreatePatternUserRule(`^(.*@corp`, "$1")
+	assertTrue(t, err != nil)
+}
+
+func TestDomainUserRule(t *testing.T) {
+	rule := importer.CreateDomainUserRule("Contractor.com", "contractors")
+
+	giteaUser, applies := rule.MapUser(contractorUser)
+	assertTrue(t, applies)
+	assertEquals(t, giteaUser, "contractors")
+
+	giteaUser, applies = rule.MapUser("bob@contractor.com")
+	assertTrue(t, applies)
+	assertEquals(t, giteaUser, "contractors")
+
+	_, applies = rule.MapUser("bob@subcontractor.com")
+	assertTrue(t, !applies)
+
+	assertEquals(t, rule.String(), "@contractor.com = contractors")
+}
+
+func TestApplyUserRules(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	rules := createUserRules(t)
+	expectToRetrieveTracUsers(t, corpUser, contractorUser, directoryUser, unmatchedUser, literalCorpUser)
+
+	userMap := map[string]string{literalCorpUser: "gitea-admin"}
+	appliedRules, err := dataImporter.ApplyUserRules(userMap, rules)
+	assertTrue(t, err == nil)
+
+	// literal mapping takes precedence over rules
+	assertEquals(t, userMap[literalCorpUser], "gitea-admin")
+	_, found := appliedRules[literalCorpUser]
+	assertTrue(t, !found)
+
+	// first applicable rule wins
+	assertEquals(t, userMap[corpUser], "jbloggs")
+	assertEquals(t, appliedRules[corpUser], rules[0])
+
+	assertEquals(t, userMap[contractorUser], "contractors")
+	assertEquals(t, appliedRules[contractorUser], rules[1])
+
+	assertEquals(t, userMap[directoryUser], "alice.smith")
+	assertEquals(t, appliedRules[directoryUser], rules[2])
+
+	_, found = userMap[unmatchedUser]
+	assertTrue(t, !found)
+	_, found = appliedRules[unmatchedUser]
+	assertTrue(t, !found)
+}
+
+func TestApplyNoUserRules(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	userMap := map[string]string{}
+	appliedRules, err := dataImporter.ApplyUserRules(userMap, []importer.UserRule{})
+	assertTrue(t, err == nil)
+	assertEquals(t, len(userMap), 0)
+	assertEquals(t, len(appliedRules), 0)
+}
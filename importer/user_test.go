@@ -8,6 +8,9 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/stevejefferson/trac2gitea/accessor/gitea"
+	"github.com/stevejefferson/trac2gitea/accessor/trac"
+	"github.com/stevejefferson/trac2gitea/importer"
 )
 
 const (
@@ -23,8 +26,28 @@ const (
 	noMatchUserName  = "user3"
 	noMatchUserEmail = "u3@ghi.jkl"
 	noMatchUser      = noMatchUserName + " <" + noMatchUserEmail + ">"
+
+	profileEmailUser        = "user4"
+	profileEmailUserEmail   = "u4@mno.pqr"
+	matchedProfileEmailUser = "matched-user4"
+
+	profileNameUser        = "user5"
+	profileNameUserName    = "User Five"
+	matchedProfileNameUser = "matched-user5"
 )
 
+func expectToRetrieveTracUserProfiles(t *testing.T, profiles ...*trac.UserProfile) {
+	mockTracAccessor.
+		EXPECT().
+		GetUserProfiles(gomock.Any()).
+		DoAndReturn(func(handlerFn func(profile *trac.UserProfile) error) error {
+			for _, profile := range profiles {
+				handlerFn(profile)
+			}
+			return nil
+		})
+}
+
 func expectToRetrieveTracUsers(t *testing.T, users ...string) {
 	mockTracAccessor.
 		EXPECT().
@@ -48,6 +71,7 @@ func TestDefaultUserMapForUserWithNoEmail(t *testing.T) {
 	setUp(t)
 	defer tearDown(t)
 
+	expectToRetrieveTracUserProfiles(t)
 	expectToRetrieveTracUsers(t, noEmailUser)
 	expectMatchUser(t, noEmailUserName, "", matchedNoEmailUser)
 	userMap, _ := dataImporter.DefaultUserMap()
@@ -58,6 +82,7 @@ func TestDefaultUserMapForUserWithEmail(t *testing.T) {
 	setUp(t)
 	defer tearDown(t)
 
+	expectToRetrieveTracUserProfiles(t)
 	expectToRetrieveTracUsers(t, emailUser)
 	expectMatchUser(t, emailUserName, emailUserEmail, matchedEmailUser)
 	userMap, _ := dataImporter.DefaultUserMap()
@@ -68,6 +93,7 @@ func TestDefaultUserMapForUnmatchedUser(t *testing.T) {
 	setUp(t)
 	defer tearDown(t)
 
+	expectToRetrieveTracUserProfiles(t)
 	expectToRetrieveTracUsers(t, noMatchUser)
 	expectMatchUser(t, noMatchUserName, noMatchUserEmail, "")
 
@@ -79,6 +105,7 @@ func TestDefaultUserMapForMultipleUsers(t *testing.T) {
 	setUp(t)
 	defer tearDown(t)
 
+	expectToRetrieveTracUserProfiles(t)
 	expectToRetrieveTracUsers(t, noEmailUser, emailUser, noMatchUser)
 	expectMatchUser(t, noEmailUserName, "", matchedNoEmailUser)
 	expectMatchUser(t, emailUserName, emailUserEmail, matchedEmailUser)
@@ -89,3 +116,234 @@ func TestDefaultUserMapForMultipleUsers(t *testing.T) {
 	assertEquals(t, userMap[emailUser], matchedEmailUser)
 	assertEquals(t, userMap[noMatchUser], "")
 }
+
+func TestDefaultUserMapForUserWithProfileEmail(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	expectToRetrieveTracUserProfiles(t, &trac.UserProfile{UserName: profileEmailUser, FullName: "", Email: profileEmailUserEmail})
+	expectToRetrieveTracUsers(t, profileEmailUser)
+	expectMatchUser(t, profileEmailUser, profileEmailUserEmail, matchedProfileEmailUser)
+
+	userMap, _ := dataImporter.DefaultUserMap()
+	assertEquals(t, userMap[profileEmailUser], matchedProfileEmailUser)
+}
+
+func TestDefaultUserMapForUserWithEmailAndProfileEmail(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	// email given with Trac user name takes precedence over that in profile
+	expectToRetrieveTracUserProfiles(t, &trac.UserProfile{UserName: emailUserName, FullName: "", Email: profileEmailUserEmail})
+	expectToRetrieveTracUsers(t, emailUser)
+	expectMatchUser(t, emailUserName, emailUserEmail, matchedEmailUser)
+
+	userMap, _ := dataImporter.DefaultUserMap()
+	assertEquals(t, userMap[emailUser], matchedEmailUser)
+}
+
+func TestDefaultUserMapForUserWithProfileFullName(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	// expect to fall back on matching full name from profile if user name does not match
+	expectToRetrieveTracUserProfiles(t, &trac.UserProfile{UserName: profileNameUser, FullName: profileNameUserName, Email: ""})
+	expectToRetrieveTracUsers(t, profileNameUser)
+	expectMatchUser(t, profileNameUser, "", "")
+	expectMatchUser(t, profileNameUserName, "", matchedProfileNameUser)
+
+	userMap, _ := dataImporter.DefaultUserMap()
+	assertEquals(t, userMap[profileNameUser], matchedProfileNameUser)
+}
+
+const (
+	placeholderUserFormat = "trac-%s"
+
+	unmappedUserName      = "unmapped.user"
+	unmappedUserEmail     = "uu@stu.vwx"
+	unmappedUser          = unmappedUserName + " <" + unmappedUserEmail + ">"
+	unmappedUserFullName  = "Unmapped User"
+	unmappedUserGiteaName = "trac-unmapped.user"
+	unmappedUserID        = int64(5001)
+
+	unmappedEmailUser          = "someone@xyz.abc"
+	unmappedEmailUserGiteaName = "trac-someone"
+	unmappedEmailUserID        = int64(5002)
+
+	existingPlaceholderUser          = "user6"
+	existingPlaceholderUserGiteaName = "trac-user6"
+	existingPlaceholderUserID        = int64(5003)
+)
+
+func expectGiteaUserLookup(t *testing.T, userName string, userID int64) {
+	mockGiteaAccessor.
+		EXPECT().
+		GetUserID(gomock.Eq(userName)).
+		Return(userID, nil)
+}
+
+func expectPlaceholderUserCreation(t *testing.T, userName string, fullName string, email string, userID int64) {
+	mockGiteaAccessor.
+		EXPECT().
+		AddPlaceholderUser(gomock.Any()).
+		DoAndReturn(func(user *gitea.User) (int64, error) {
+			assertEquals(t, user.Name, userName)
+			assertEquals(t, user.FullName, fullName)
+			assertEquals(t, user.Email, email)
+			return userID, nil
+		})
+}
+
+func TestCreatePlaceholderUsers(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	userMap[emailUser] = matchedEmailUser
+	userMap[unmappedUser] = ""
+	userMap[unmappedEmailUser] = ""
+
+	expectToRetrieveTracUserProfiles(t, &trac.UserProfile{UserName: unmappedUserName, FullName: unmappedUserFullName, Email: ""})
+
+	// placeholder users are created in order of Trac user name
+	// expect placeholder user for Trac user identified only by email
+	expectGiteaUserLookup(t, unmappedEmailUserGiteaName, gitea.NullID)
+	expectGiteaUserLookup(t, unmappedEmailUser, gitea.NullID)
+	expectPlaceholderUserCreation(t, unmappedEmailUserGiteaName, "", unmappedEmailUser, unmappedEmailUserID)
+
+	// expect placeholder user with name and email from Trac user and full name from profile
+	expectGiteaUserLookup(t, unmappedUserGiteaName, gitea.NullID)
+	expectGiteaUserLookup(t, unmappedUserEmail, gitea.NullID)
+	expectPlaceholderUserCreation(t, unmappedUserGiteaName, unmappedUserFullName, unmappedUserEmail, unmappedUserID)
+
+	dataImporter.CreatePlaceholderUsers(userMap, placeholderUserFormat)
+	assertEquals(t, userMap[emailUser], matchedEmailUser)
+	assertEquals(t, userMap[unmappedUser], unmappedUserGiteaName)
+	assertEquals(t, userMap[unmappedEmailUser], unmappedEmailUserGiteaName)
+}
+
+func TestCreatePlaceholderUserWithEmailOfExistingUser(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	userMap[unmappedUser] = ""
+
+	expectToRetrieveTracUserProfiles(t)
+
+	// expect placeholder user to be created without email belonging to another user
+	expectGiteaUserLookup(t, unmappedUserGiteaName, gitea.NullID)
+	expectGiteaUserLookup(t, unmappedUserEmail, int64(5678))
+	expectPlaceholderUserCreation(t, unmappedUserGiteaName, "", "", unmappedUserID)
+
+	dataImporter.CreatePlaceholderUsers(userMap, placeholderUserFormat)
+	assertEquals(t, userMap[unmappedUser], unmappedUserGiteaName)
+}
+
+func TestCreatePlaceholderUserWhichAlreadyExists(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	userMap[existingPlaceholderUser] = ""
+
+	expectToRetrieveTracUserProfiles(t)
+
+	// expect existing placeholder user from previous import to be used - gomock will fail the test if one is created
+	expectGiteaUserLookup(t, existingPlaceholderUserGiteaName, existingPlaceholderUserID)
+
+	dataImporter.CreatePlaceholderUsers(userMap, placeholderUserFormat)
+	assertEquals(t, userMap[existingPlaceholderUser], existingPlaceholderUserGiteaName)
+}
+
+const (
+	loginSourceID            = int64(7)
+	linkedTracUser           = "linked-user"
+	linkedGiteaUserID        = int64(5004)
+	emailLinkedTracUser      = "email-linked-user <email-linked@example.com>"
+	emailLinkedTracUserEmail = "email-linked@example.com"
+	emailLinkedGiteaUserID   = int64(5005)
+	unlinkedTracUser         = "unlinked-user"
+	linkedUserPermission     = "TICKET_MODIFY"
+)
+
+func expectExternalUserLookup(t *testing.T, externalID string, userID int64) {
+	mockGiteaAccessor.
+		EXPECT().
+		GetExternalUserID(gomock.Eq(loginSourceID), gomock.Eq(externalID)).
+		Return(userID, nil)
+}
+
+func TestUnmappedUsersLinkedThroughLoginSource(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	loginSourceDataImporter, _ := importer.CreateImporter(mockTracAccessor, mockGiteaAccessor, mockMarkdownConverter, defaultUser, false, loginSourceID)
+	userMap[linkedTracUser] = ""
+	userMap[emailLinkedTracUser] = ""
+	userMap[unlinkedTracUser] = ""
+	linkedPermissionMap := map[string]string{linkedUserPermission: importer.PermissionWrite}
+
+	mockTracAccessor.
+		EXPECT().
+		GetPermissions(gomock.Any()).
+		DoAndReturn(func(handlerFn func(subject string, action string) error) error {
+			handlerFn(linkedTracUser, linkedUserPermission)
+			handlerFn(emailLinkedTracUser, linkedUserPermission)
+			handlerFn(unlinkedTracUser, linkedUserPermission)
+			return nil
+		})
+	expectRepoOwnership(t, false)
+
+	// expect each unmapped Trac user to be looked up in the login source by their Trac login and then by their email address
+	expectExternalUserLookup(t, linkedTracUser, linkedGiteaUserID)
+	expectExternalUserLookup(t, "email-linked-user", gitea.NullID)
+	expectExternalUserLookup(t, emailLinkedTracUserEmail, emailLinkedGiteaUserID)
+	expectExternalUserLookup(t, unlinkedTracUser, gitea.NullID)
+
+	// expect linked Gitea users to be used for Trac users
+	expectRepoCollaborator(t, linkedGiteaUserID, gitea.WriteAccess)
+	expectRepoCollaborator(t, emailLinkedGiteaUserID, gitea.WriteAccess)
+
+	loginSourceDataImporter.ImportPermissions(userMap, linkedPermissionMap)
+}
+
+func expectLoginSourceServiceType(t *testing.T, serviceType int64) {
+	mockGiteaAccessor.
+		EXPECT().
+		GetLoginSourceServiceType(gomock.Eq(loginSourceID)).
+		Return(serviceType, nil)
+}
+
+func TestImportOriginalServiceTypeOfLoginSource(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	loginSourceDataImporter, _ := importer.CreateImporter(mockTracAccessor, mockGiteaAccessor, mockMarkdownConverter, defaultUser, false, loginSourceID)
+
+	// expect repository to be marked as migrated from the git service after which the login source is named
+	expectLoginSourceServiceType(t, 2)
+	mockGiteaAccessor.
+		EXPECT().
+		SetRepoOriginalServiceType(gomock.Eq(int64(2))).
+		Return(nil)
+
+	loginSourceDataImporter.ImportOriginalServiceType()
+}
+
+func TestImportOriginalServiceTypeOfLoginSourceWithNoService(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	loginSourceDataImporter, _ := importer.CreateImporter(mockTracAccessor, mockGiteaAccessor, mockMarkdownConverter, defaultUser, false, loginSourceID)
+
+	// expect no original service type to be recorded for a login source not named after a git service
+	expectLoginSourceServiceType(t, 0)
+
+	loginSourceDataImporter.ImportOriginalServiceType()
+}
+
+func TestImportOriginalServiceTypeWithoutLoginSource(t *testing.T) {
+	setUp(t)
+	defer tearDown(t)
+
+	// expect no login source lookup without a login source
+	dataImporter.ImportOriginalServiceType()
+}
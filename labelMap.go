@@ -15,56 +15,115 @@ import (
 
 const (
 	componentTypeName  = "component"
+	keywordTypeName    = "keyword"
 	priorityTypeName   = "priority"
 	resolutionTypeName = "resolution"
 	severityTypeName   = "severity"
+	statusTypeName     = "status"
 	typeTypeName       = "type"
 	versionTypeName    = "version"
 )
 
-func readDefaultLabelMaps(dataImporter *importer.Importer) (componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap map[string]string, err error) {
+// prefixes of label map targets which are not Gitea labels
+const (
+	milestoneTargetPrefix = "milestone:"
+	assigneeTargetPrefix  = "assignee:"
+)
+
+// checkLabelMapTargets checks that a label map entry for a Trac item of a given type only uses targets applicable to that type:
+// only versions can be mapped onto milestones and only components onto default assignees.
+func checkLabelMapTargets(labelType string, mapping string) error {
+	for _, target := range importer.SplitLabelMapping(mapping) {
+		if strings.HasPrefix(target, milestoneTargetPrefix) && labelType != versionTypeName {
+			return fmt.Errorf("only Trac versions can be mapped onto a milestone")
+		}
+		if strings.HasPrefix(target, assigneeTargetPrefix) && labelType != componentTypeName {
+			return fmt.Errorf("only Trac components can be mapped onto a default assignee")
+		}
+	}
+
+	return nil
+}
+
+// Gitea issue states onto which Trac statuses are mapped
+const (
+	openStatusName   = "open"
+	closedStatusName = "closed"
+)
+
+func readDefaultLabelMaps(dataImporter *importer.Importer) (componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap, keywordMap map[string]string, statusMap map[string]*importer.StatusMapping, err error) {
 	componentMap, err = dataImporter.DefaultComponentLabelMap()
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	priorityMap, err = dataImporter.DefaultPriorityLabelMap()
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	resolutionMap, err = dataImporter.DefaultResolutionLabelMap()
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	severityMap, err = dataImporter.DefaultSeverityLabelMap()
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	typeMap, err = dataImporter.DefaultTypeLabelMap()
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	versionMap, err = dataImporter.DefaultVersionLabelMap()
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	keywordMap, err = dataImporter.DefaultKeywordLabelMap()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, err
+	}
+
+	statusMap, err = dataImporter.DefaultStatusMap()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	return
 }
 
-// readLabelMaps reads the label maps from the provided file, if no file provided, import default maps using the provided importer
-func readLabelMaps(mapFile string, dataImporter *importer.Importer) (componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap map[string]string, err error) {
+// parseStatusMapping parses the Gitea equivalent of a Trac status from a label map: "open" or "closed", optionally followed by ", <gitea-label>".
+func parseStatusMapping(mapping string) (*importer.StatusMapping, error) {
+	state := mapping
+	label := ""
+	if commaPos := strings.Index(mapping, ","); commaPos != -1 {
+		state = strings.Trim(mapping[0:commaPos], " ")
+		label = strings.Trim(mapping[commaPos+1:], " ")
+	}
+
+	switch state {
+	case openStatusName:
+		return &importer.StatusMapping{Closed: false, Label: label}, nil
+	case closedStatusName:
+		return &importer.StatusMapping{Closed: true, Label: label}, nil
+	}
+
+	return nil, fmt.Errorf("expecting '%s' or '%s' for status", openStatusName, closedStatusName)
+}
+
+// readLabelMaps reads the label maps from the provided file, if no file provided, import default maps using the provided importer.
+// Blank lines and lines starting with '#' are ignored.
+func readLabelMaps(mapFile string, dataImporter *importer.Importer) (componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap, keywordMap map[string]string, statusMap map[string]*importer.StatusMapping, err error) {
 	if mapFile == "" {
 		return readDefaultLabelMaps(dataImporter)
 	}
 
 	fd, err := os.Open(mapFile)
 	if err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 	defer fd.Close()
 
@@ -74,23 +133,35 @@ func readLabelMaps(mapFile string, dataImporter *importer.Importer) (componentMa
 	severityMap = make(map[string]string)
 	typeMap = make(map[string]string)
 	versionMap = make(map[string]string)
+	keywordMap = make(map[string]string)
+	statusMap = make(map[string]*importer.StatusMapping)
 
 	scanner := bufio.NewScanner(fd)
 	for scanner.Scan() {
 		mapLine := scanner.Text()
+		trimmedLine := strings.TrimSpace(mapLine)
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+
 		equalsPos := strings.LastIndex(mapLine, "=")
 		if equalsPos == -1 {
-			return nil, nil, nil, nil, nil, nil, fmt.Errorf("badly formatted label map file %s: expecting '=', found %s", mapFile, mapLine)
+			return nil, nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("badly formatted label map file %s: expecting '=', found %s", mapFile, mapLine)
 		}
 
 		tracLabelAndType := strings.Trim(mapLine[0:equalsPos], " ")
 		colonPos := strings.LastIndex(tracLabelAndType, ":")
 		if equalsPos == -1 {
-			return nil, nil, nil, nil, nil, nil, fmt.Errorf("badly formatted label map file %s: expecting ':', found %s", mapFile, mapLine)
+			return nil, nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("badly formatted label map file %s: expecting ':', found %s", mapFile, mapLine)
 		}
 		labelType := strings.Trim(tracLabelAndType[0:colonPos], " ")
 		tracLabel := strings.Trim(tracLabelAndType[colonPos+1:], " ")
 		giteaLabel := strings.Trim(mapLine[equalsPos+1:], " ")
+		if labelType != statusTypeName {
+			if err := checkLabelMapTargets(labelType, giteaLabel); err != nil {
+				return nil, nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("badly formatted label map file %s: %v, found %s", mapFile, err, mapLine)
+			}
+		}
 
 		switch labelType {
 		case componentTypeName:
@@ -105,13 +176,21 @@ func readLabelMaps(mapFile string, dataImporter *importer.Importer) (componentMa
 			typeMap[tracLabel] = giteaLabel
 		case versionTypeName:
 			versionMap[tracLabel] = giteaLabel
+		case keywordTypeName:
+			keywordMap[tracLabel] = giteaLabel
+		case statusTypeName:
+			statusMapping, err := parseStatusMapping(giteaLabel)
+			if err != nil {
+				return nil, nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("badly formatted label map file %s: %v, found %s", mapFile, err, mapLine)
+			}
+			statusMap[tracLabel] = statusMapping
 		default:
-			return nil, nil, nil, nil, nil, nil, fmt.Errorf("badly formatted label map file %s: expecting Trac label type before ':', found %s", mapFile, mapLine)
+			return nil, nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("badly formatted label map file %s: expecting Trac label type before ':', found %s", mapFile, mapLine)
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, nil, nil, nil, nil, nil, err
+		return nil, nil, nil, nil, nil, nil, nil, nil, err
 	}
 
 	return
@@ -127,19 +206,51 @@ func writeLabelMapToFile(fd *os.File, labelType string, labelMap map[string]stri
 	return nil
 }
 
-func writeLabelMapsToFile(mapFile string, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap map[string]string) error {
+func writeStatusMapToFile(fd *os.File, statusMap map[string]*importer.StatusMapping) error {
+	for tracStatus, statusMapping := range statusMap {
+		mapping := openStatusName
+		if statusMapping.Closed {
+			mapping = closedStatusName
+		}
+		if statusMapping.Label != "" {
+			mapping = mapping + ", " + statusMapping.Label
+		}
+		if _, err := fd.WriteString(statusTypeName + ":" + tracStatus + " = " + mapping + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// labelMapHeader describes the syntax of the label map at the top of a generated label map file.
+const labelMapHeader = `# <label-type>:<trac-item-name> = <target>[, <target>...]
+#   <label-type> is one of component, priority, resolution, severity, type, version or keyword
+#   each <target> is a Gitea label <label-name> [#rrggbb], milestone:<gitea-milestone-name> (versions only) or assignee:<gitea-user> (components only)
+#   a target containing a comma must be enclosed in double quotes, with any double quote within it written as two double quotes - e.g. "area, core" #fbca04
+# status:<trac-status> = open|closed[, <target>]
+# blank lines and lines starting with '#' are ignored
+`
+
+func writeLabelMapsToFile(mapFile string, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap, keywordMap map[string]string, statusMap map[string]*importer.StatusMapping) error {
 	fd, err := os.Create(mapFile)
 	if err != nil {
 		return err
 	}
 	defer fd.Close()
 
+	if _, err := fd.WriteString(labelMapHeader); err != nil {
+		return err
+	}
+
 	writeLabelMapToFile(fd, componentTypeName, componentMap)
 	writeLabelMapToFile(fd, priorityTypeName, priorityMap)
 	writeLabelMapToFile(fd, resolutionTypeName, resolutionMap)
 	writeLabelMapToFile(fd, severityTypeName, severityMap)
 	writeLabelMapToFile(fd, typeTypeName, typeMap)
 	writeLabelMapToFile(fd, versionTypeName, versionMap)
+	writeLabelMapToFile(fd, keywordTypeName, keywordMap)
+	writeStatusMapToFile(fd, statusMap)
 
 	return nil
 }
@@ -7,6 +7,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/stevejefferson/trac2gitea/importer"
 	"github.com/stevejefferson/trac2gitea/markdown"
@@ -24,6 +25,13 @@ var overwrite bool
 var verbose bool
 var wikiConvertPredefineds bool
 var generateMaps bool
+var fuzzyMatchUsers bool
+var createPlaceholderUsers bool
+var placeholderUserFormat string
+var placeholderUserMapFile string
+var loginSourceID int64
+var releases string
+var milestoneReleases bool
 var tracRootDir string
 var giteaRootDir string
 var giteaUser string
@@ -32,9 +40,18 @@ var userMapInputFile string
 var userMapOutputFile string
 var labelMapInputFile string
 var labelMapOutputFile string
+var customFieldMapInputFile string
+var customFieldMapOutputFile string
+var permissionMapInputFile string
+var permissionMapOutputFile string
+var milestoneMapInputFile string
+var milestoneMapOutputFile string
 var giteaWikiRepoURL string
 var giteaWikiRepoToken string
 var giteaWikiRepoDir string
+var giteaAPI bool
+var giteaToken string
+var giteaDumpDir string
 
 // parseArgs parses the command line arguments, populating the variables above.
 func parseArgs() {
@@ -44,11 +61,33 @@ func parseArgs() {
 		"password/token for accessing wiki repository (ignored if wiki-url provided)")
 	wikiDirParam := pflag.String("wiki-dir", "",
 		"directory into which to checkout (clone) wiki repository - defaults to cwd")
+	giteaAPIParam := pflag.Bool("gitea-api", false,
+		"access Gitea through its REST API rather than directly through its database - <gitea-root> is then the Gitea server URL")
+	giteaTokenParam := pflag.String("gitea-token", "",
+		"access token for the Gitea REST API (required with gitea-api)")
+	giteaDumpParam := pflag.String("gitea-dump", "",
+		"write a Gitea repository dump into the given directory for loading with 'gitea restore-repo' - <gitea-root> is then the Gitea server URL")
 	wikiConvertPredefinedsParam := pflag.Bool("wiki-convert-predefined", false,
 		"convert Trac predefined wiki pages - by default we skip these")
 
+	fuzzyMatchUsersParam := pflag.Bool("fuzzy-match-users", false,
+		"map Trac users with no exact Gitea equivalent onto the most similar Gitea user - uncertain matches are only used in generated maps, where they are flagged for review")
+	createPlaceholderUsersParam := pflag.Bool("create-placeholder-users", false,
+		"create restricted, login-disabled Gitea users for Trac users with no Gitea equivalent, recording them in a copy of the user map written to the placeholder-user-map file")
+	placeholderUserFormatParam := pflag.String("placeholder-user-format", "trac-%s",
+		"format of the names of placeholder Gitea users - '%s' is replaced by the Trac user name")
+	placeholderUserMapParam := pflag.String("placeholder-user-map", "",
+		"file to which to write the user map including placeholder users - defaults to <user-map>"+placeholderUserMapSuffix+" or, with no user map, "+defaultPlaceholderUserMapFile)
+	loginSourceIDParam := pflag.Int64("login-source-id", 0,
+		"id of Gitea login source whose accounts are identified with Trac users - content by unmapped Trac users is attributed to any Gitea user linked to their Trac login or email address")
+	releasesParam := pflag.String("releases", "",
+		"create a Gitea release for each Trac version: 'draft' or 'published' - a 'published' release with no git tag of the same name, or for an unreleased version, is created as a draft instead (with a warning)")
+	milestoneReleasesParam := pflag.Bool("milestone-releases", false,
+		"also create a Gitea release for each completed Trac milestone, named after its mapped Gitea milestone (requires releases)")
+	milestoneMapParam := pflag.String("milestone-map", "",
+		"milestone map file - an alternative to the <milestone-map> argument which does not require the other map files to be provided")
 	generateMapsParam := pflag.Bool("generate-maps", false,
-		"generate default user/label mappings into provided map files (note: no conversion will be performed in this case)")
+		"generate default user/label/custom field/permission/milestone mappings into provided map files (note: no conversion will be performed in this case)")
 	dbOnlyParam := pflag.Bool("db-only", false,
 		"convert database only")
 	wikiOnlyParam := pflag.Bool("wiki-only", false,
@@ -62,7 +101,7 @@ func parseArgs() {
 
 	pflag.Usage = func() {
 		fmt.Fprintf(os.Stderr,
-			"Usage: %s [options] <trac-root> <gitea-root> <gitea-user> <gitea-repo> [<user-map>] [<label-map>]\n",
+			"Usage: %s [options] <trac-root> <gitea-root> <gitea-user> <gitea-repo> [<user-map>] [<label-map>] [<custom-field-map>] [<permission-map>] [<milestone-map>]\n",
 			os.Args[0])
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		pflag.PrintDefaults()
@@ -76,6 +115,21 @@ func parseArgs() {
 	wikiOnly = *wikiOnlyParam
 	wikiPush = !*wikiNoPushParam
 	generateMaps = *generateMapsParam
+	fuzzyMatchUsers = *fuzzyMatchUsersParam
+	createPlaceholderUsers = *createPlaceholderUsersParam
+	placeholderUserFormat = *placeholderUserFormatParam
+	loginSourceID = *loginSourceIDParam
+	releases = *releasesParam
+	milestoneReleases = *milestoneReleasesParam
+	if releases != "" && releases != "draft" && releases != "published" {
+		log.Fatal("releases must be 'draft' or 'published', found %s!", releases)
+	}
+	if milestoneReleases && releases == "" {
+		log.Fatal("cannot create milestone releases without releases!")
+	}
+	if createPlaceholderUsers && !strings.Contains(placeholderUserFormat, "%s") {
+		log.Fatal("placeholder user format %s must contain '%%s'!", placeholderUserFormat)
+	}
 
 	if dbOnly && wikiOnly {
 		log.Fatal("cannot generate only database AND only wiki!")
@@ -84,8 +138,17 @@ func parseArgs() {
 	giteaWikiRepoURL = *wikiURLParam
 	giteaWikiRepoToken = *wikiTokenParam
 	giteaWikiRepoDir = *wikiDirParam
+	giteaAPI = *giteaAPIParam
+	giteaToken = *giteaTokenParam
+	giteaDumpDir = *giteaDumpParam
+	if giteaAPI && giteaDumpDir != "" {
+		log.Fatal("cannot access Gitea through its REST API AND write a Gitea dump!")
+	}
+	if giteaAPI && createPlaceholderUsers {
+		log.Warn("placeholder users created through the Gitea REST API are not removed if the import fails - delete them through Gitea if necessary")
+	}
 
-	if (pflag.NArg() < 4) || (pflag.NArg() > 6) {
+	if (pflag.NArg() < 4) || (pflag.NArg() > 9) {
 		pflag.Usage()
 		os.Exit(1)
 	}
@@ -111,11 +174,60 @@ func parseArgs() {
 			labelMapInputFile = labelMapFile
 		}
 	}
+
+	if pflag.NArg() > 6 {
+		customFieldMapFile := pflag.Arg(6)
+		if generateMaps {
+			customFieldMapOutputFile = customFieldMapFile
+		} else {
+			customFieldMapInputFile = customFieldMapFile
+		}
+	}
+
+	if pflag.NArg() > 7 {
+		permissionMapFile := pflag.Arg(7)
+		if generateMaps {
+			permissionMapOutputFile = permissionMapFile
+		} else {
+			permissionMapInputFile = permissionMapFile
+		}
+	}
+
+	milestoneMapFile := *milestoneMapParam
+	if pflag.NArg() > 8 {
+		if milestoneMapFile != "" {
+			log.Fatal("cannot provide milestone map both as an argument AND through milestone-map!")
+		}
+		milestoneMapFile = pflag.Arg(8)
+	}
+	if milestoneMapFile != "" {
+		if generateMaps {
+			milestoneMapOutputFile = milestoneMapFile
+		} else {
+			milestoneMapInputFile = milestoneMapFile
+		}
+	}
+
+	placeholderUserMapFile = *placeholderUserMapParam
+	if placeholderUserMapFile == "" {
+		placeholderUserMapFile = defaultPlaceholderUserMapFile
+		if userMapInputFile != "" {
+			placeholderUserMapFile = userMapInputFile + placeholderUserMapSuffix
+		}
+	}
 }
 
 // importData imports the non-wiki Trac data.
-func importData(dataImporter *importer.Importer, userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap map[string]string) error {
+func importData(dataImporter *importer.Importer, userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap, keywordMap, customFieldMap, permissionMap, milestoneMap map[string]string,
+	statusMap map[string]*importer.StatusMapping) error {
 	var err error
+	if err = dataImporter.ImportOriginalServiceType(); err != nil {
+		return err
+	}
+	// milestones must precede versions, which may be mapped onto existing milestones
+	if err = dataImporter.ImportMilestones(milestoneMap); err != nil {
+		return err
+	}
 	if err = dataImporter.ImportComponents(componentMap); err != nil {
 		return err
 	}
@@ -134,20 +246,56 @@ func importData(dataImporter *importer.Importer, userMap, componentMap, priority
 	if err = dataImporter.ImportVersions(versionMap); err != nil {
 		return err
 	}
-	if err = dataImporter.ImportMilestones(); err != nil {
+	if err = dataImporter.ImportKeywords(keywordMap); err != nil {
 		return err
 	}
-	if err = dataImporter.ImportTickets(userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap); err != nil {
+	if err = dataImporter.ImportStatuses(statusMap); err != nil {
 		return err
 	}
+	if err = dataImporter.ImportCustomFieldLabels(customFieldMap); err != nil {
+		return err
+	}
+	if err = dataImporter.ImportTickets(&importer.TicketMaps{
+		UserMap:        userMap,
+		ComponentMap:   componentMap,
+		PriorityMap:    priorityMap,
+		ResolutionMap:  resolutionMap,
+		SeverityMap:    severityMap,
+		TypeMap:        typeMap,
+		VersionMap:     versionMap,
+		KeywordMap:     keywordMap,
+		CustomFieldMap: customFieldMap,
+		MilestoneMap:   milestoneMap,
+		StatusMap:      statusMap}); err != nil {
+		return err
+	}
+	if err = dataImporter.ImportTicketDependencies(); err != nil {
+		return err
+	}
+	if err = dataImporter.ImportPermissions(userMap, permissionMap); err != nil {
+		return err
+	}
+	if releases != "" {
+		if err = dataImporter.ImportReleases(releases == "draft", milestoneReleases, milestoneMap); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
 // performImport performs the actual import
-func performImport(dataImporter *importer.Importer, userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap map[string]string) error {
+func performImport(dataImporter *importer.Importer, userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap, keywordMap, customFieldMap, permissionMap, milestoneMap map[string]string,
+	statusMap map[string]*importer.StatusMapping) error {
+	if createPlaceholderUsers {
+		if err := dataImporter.CreatePlaceholderUsers(userMap, placeholderUserFormat); err != nil {
+			dataImporter.RollbackImport()
+			return err
+		}
+	}
+
 	if !wikiOnly {
-		if err := importData(dataImporter, userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap); err != nil {
+		if err := importData(dataImporter, userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap, keywordMap, customFieldMap, permissionMap, milestoneMap, statusMap); err != nil {
 			dataImporter.RollbackImport()
 			return err
 		}
@@ -160,28 +308,49 @@ func performImport(dataImporter *importer.Importer, userMap, componentMap, prior
 		}
 	}
 
-	return dataImporter.CommitImport()
+	if err := dataImporter.CommitImport(); err != nil {
+		return err
+	}
+
+	// record any placeholder users in a copy of the user map for review - the provided user map (and any comments in it) is left alone
+	if createPlaceholderUsers {
+		if err := writeUserMapToFile(placeholderUserMapFile, userMap); err != nil {
+			return err
+		}
+		log.Info("wrote user map including placeholder users to %s", placeholderUserMapFile)
+	}
+
+	return nil
 }
 
-// createImporter creates and configures the importer
-func createImporter() (*importer.Importer, error) {
+// createImporter creates and configures the importer, returns the importer and the markdown converter it uses
+func createImporter() (*importer.Importer, *markdown.DefaultConverter, error) {
 	tracAccessor, err := trac.CreateDefaultAccessor(tracRootDir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	var giteaAccessor gitea.Accessor
+	if giteaAPI {
+		giteaAccessor, err = gitea.CreateAPIAccessor(
+			giteaRootDir, giteaToken, giteaUser, giteaRepo, giteaWikiRepoURL, giteaWikiRepoToken, giteaWikiRepoDir, overwrite, wikiPush)
+	} else if giteaDumpDir != "" {
+		giteaAccessor, err = gitea.CreateDumpAccessor(
+			giteaDumpDir, giteaRootDir, giteaUser, giteaRepo, giteaWikiRepoURL, giteaWikiRepoToken, giteaWikiRepoDir, overwrite, wikiPush)
+	} else {
+		giteaAccessor, err = gitea.CreateDefaultAccessor(
+			giteaRootDir, giteaUser, giteaRepo, giteaWikiRepoURL, giteaWikiRepoToken, giteaWikiRepoDir, overwrite, wikiPush)
 	}
-	giteaAccessor, err := gitea.CreateDefaultAccessor(
-		giteaRootDir, giteaUser, giteaRepo, giteaWikiRepoURL, giteaWikiRepoToken, giteaWikiRepoDir, overwrite, wikiPush)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	markdownConverter := markdown.CreateDefaultConverter(tracAccessor, giteaAccessor)
 
-	dataImporter, err := importer.CreateImporter(tracAccessor, giteaAccessor, markdownConverter, giteaUser, wikiConvertPredefineds)
+	dataImporter, err := importer.CreateImporter(tracAccessor, giteaAccessor, markdownConverter, giteaUser, wikiConvertPredefineds, loginSourceID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return dataImporter, nil
+	return dataImporter, markdownConverter, nil
 }
 
 func main() {
@@ -193,24 +362,50 @@ func main() {
 	}
 	log.SetLevel(logLevel)
 
-	dataImporter, err := createImporter()
+	dataImporter, markdownConverter, err := createImporter()
+	if err != nil {
+		log.Fatal("%+v", err)
+		return
+	}
+
+	// when generating maps, an existing user map file may provide explicit mappings and rules for the generated map
+	userMapFile := userMapInputFile
+	if generateMaps && userMapOutputFile != "" {
+		if _, err := os.Stat(userMapOutputFile); err == nil {
+			userMapFile = userMapOutputFile
+		}
+	}
+	userMap, err := readUserMap(userMapFile, dataImporter)
 	if err != nil {
 		log.Fatal("%+v", err)
 		return
 	}
 
-	userMap, err := readUserMap(userMapInputFile, dataImporter)
+	componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap, keywordMap, statusMap, err := readLabelMaps(labelMapInputFile, dataImporter)
 	if err != nil {
 		log.Fatal("%+v", err)
 		return
 	}
 
-	componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap, err := readLabelMaps(labelMapInputFile, dataImporter)
+	customFieldMap, err := readCustomFieldMap(customFieldMapInputFile, dataImporter)
 	if err != nil {
 		log.Fatal("%+v", err)
 		return
 	}
 
+	permissionMap, err := readPermissionMap(permissionMapInputFile, dataImporter)
+	if err != nil {
+		log.Fatal("%+v", err)
+		return
+	}
+
+	milestoneMap, err := readMilestoneMap(milestoneMapInputFile, dataImporter)
+	if err != nil {
+		log.Fatal("%+v", err)
+		return
+	}
+	markdownConverter.SetMilestoneMap(milestoneMap)
+
 	if generateMaps {
 		// note: no need to commit or rollback transaction here - nothing has been imported yet
 		if userMapOutputFile != "" {
@@ -221,17 +416,38 @@ func main() {
 			log.Info("wrote user map to %s", userMapOutputFile)
 		}
 		if labelMapOutputFile != "" {
-			if err = writeLabelMapsToFile(labelMapOutputFile, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap); err != nil {
+			if err = writeLabelMapsToFile(labelMapOutputFile, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap, keywordMap, statusMap); err != nil {
 				log.Fatal("%+v", err)
 				return
 			}
 			log.Info("wrote label map to %s", labelMapOutputFile)
 		}
+		if customFieldMapOutputFile != "" {
+			if err = writeCustomFieldMapToFile(customFieldMapOutputFile, customFieldMap); err != nil {
+				log.Fatal("%+v", err)
+				return
+			}
+			log.Info("wrote custom field map to %s", customFieldMapOutputFile)
+		}
+		if permissionMapOutputFile != "" {
+			if err = writePermissionMapToFile(permissionMapOutputFile, permissionMap); err != nil {
+				log.Fatal("%+v", err)
+				return
+			}
+			log.Info("wrote permission map to %s", permissionMapOutputFile)
+		}
+		if milestoneMapOutputFile != "" {
+			if err = writeMilestoneMapToFile(milestoneMapOutputFile, milestoneMap); err != nil {
+				log.Fatal("%+v", err)
+				return
+			}
+			log.Info("wrote milestone map to %s", milestoneMapOutputFile)
+		}
 
 		return
 	}
 
-	err = performImport(dataImporter, userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap)
+	err = performImport(dataImporter, userMap, componentMap, priorityMap, resolutionMap, severityMap, typeMap, versionMap, keywordMap, customFieldMap, permissionMap, milestoneMap, statusMap)
 	if err != nil {
 		log.Fatal("%+v", err)
 		return
@@ -11,4 +11,10 @@ type Converter interface {
 
 	// WikiConvert converts a comment/description string associated with a Trac wiki page to Gitea markdown
 	WikiConvert(wikiPage string, in string) string
+
+	// MilestoneConvert converts a description of a Trac milestone to Gitea markdown
+	MilestoneConvert(milestoneName string, in string) string
+
+	// ReleaseConvert converts a description of a Trac version being imported as a Gitea release to Gitea markdown
+	ReleaseConvert(in string) string
 }
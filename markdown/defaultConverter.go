@@ -16,19 +16,27 @@ func CreateDefaultConverter(tracAccessor trac.Accessor, giteaAccessor gitea.Acce
 }
 
 // DefaultConverter is the default implementation of the Trac markdown to Gitea markdown converter.
-// This is used in two circumstances:
-// 1. for ticket comments - in which case ticketID != NullID and wikiAccessor == nil
-// 2. for wiki imports - in which case ticketID == NullID and wikiAccessor != nil
+// This is used in three circumstances:
+// 1. for ticket comments - in which case ticketID != NullID
+// 2. for wiki imports - in which case wikiPage != ""
+// 3. for milestone descriptions - in which case milestoneName != ""
 type DefaultConverter struct {
 	tracAccessor  trac.Accessor
 	giteaAccessor gitea.Accessor
+	milestoneMap  map[string]string
 }
 
-func (converter *DefaultConverter) convertNonCodeBlockText(ticketID int64, wikiPage string, in string) string {
+// SetMilestoneMap sets the mapping between Trac milestone names and Gitea milestone names used to resolve links to milestones.
+// If no milestone map is set, Trac milestones are assumed to have the same names in Gitea.
+func (converter *DefaultConverter) SetMilestoneMap(milestoneMap map[string]string) {
+	converter.milestoneMap = milestoneMap
+}
+
+func (converter *DefaultConverter) convertNonCodeBlockText(ticketID int64, wikiPage string, milestoneName string, in string) string {
 	out := in
 
 	// do simple one-line constructs first
-	out = converter.convertLinks(ticketID, wikiPage, out)
+	out = converter.convertLinks(ticketID, wikiPage, milestoneName, out)
 	out = converter.convertAnchors(out)
 	out = converter.convertEscapes(out)
 	out = converter.convertLists(out)
@@ -51,7 +59,7 @@ func (converter *DefaultConverter) convertNonCodeBlockText(ticketID int64, wikiP
 	return out
 }
 
-func (converter *DefaultConverter) convert(ticketID int64, wikiPage string, in string) string {
+func (converter *DefaultConverter) convert(ticketID int64, wikiPage string, milestoneName string, in string) string {
 	out := in
 
 	// ensure we have Unix EOLs
@@ -59,7 +67,7 @@ func (converter *DefaultConverter) convert(ticketID int64, wikiPage string, in s
 
 	// perform conversions on text not in a code block using the ticket-specific link conversion
 	out = converter.convertNonCodeBlocks(out, func(in string) string {
-		return converter.convertNonCodeBlockText(ticketID, wikiPage, in)
+		return converter.convertNonCodeBlockText(ticketID, wikiPage, milestoneName, in)
 	})
 
 	// finally, convert any code blocks
@@ -72,10 +80,20 @@ func (converter *DefaultConverter) convert(ticketID int64, wikiPage string, in s
 
 // TicketConvert converts a comment/description string associated with a Trac ticket to Gitea markdown
 func (converter *DefaultConverter) TicketConvert(ticketID int64, in string) string {
-	return converter.convert(ticketID, "", in)
+	return converter.convert(ticketID, "", "", in)
 }
 
 // WikiConvert converts a comment/description string associated with a Trac wiki page to Gitea markdown
 func (converter *DefaultConverter) WikiConvert(wikiPage string, in string) string {
-	return converter.convert(trac.NullID, wikiPage, in)
+	return converter.convert(trac.NullID, wikiPage, "", in)
+}
+
+// MilestoneConvert converts a description of a Trac milestone to Gitea markdown
+func (converter *DefaultConverter) MilestoneConvert(milestoneName string, in string) string {
+	return converter.convert(trac.NullID, "", milestoneName, in)
+}
+
+// ReleaseConvert converts a description of a Trac version being imported as a Gitea release to Gitea markdown
+func (converter *DefaultConverter) ReleaseConvert(in string) string {
+	return converter.convert(trac.NullID, "", "", in)
 }
@@ -140,6 +140,15 @@ func (converter *DefaultConverter) resolveTicketCommentLink(ticketID int64, link
 
 func (converter *DefaultConverter) resolveMilestoneLink(link string) string {
 	milestoneName := milestoneLinkRegexp.ReplaceAllString(link, `$1`)
+	if converter.milestoneMap != nil {
+		giteaMilestoneName := converter.milestoneMap[milestoneName]
+		if giteaMilestoneName == "" {
+			log.Warn("Trac milestone \"%s\" referenced by Trac link \"%s\" is not mapped onto a Gitea milestone", milestoneName, link)
+			return link // not a recognised link - do not mark
+		}
+		milestoneName = giteaMilestoneName
+	}
+
 	milestoneID, err := converter.giteaAccessor.GetMilestoneID(milestoneName)
 	if err != nil {
 		return link // not a recognised link - do not mark (error should already be logged)
@@ -182,7 +191,7 @@ func (converter *DefaultConverter) resolveWikiAttachmentLink(wikiPage string, at
 	return markLink(attachmentURL)
 }
 
-func (converter *DefaultConverter) resolveAttachmentLink(ticketID int64, wikiPage string, link string) string {
+func (converter *DefaultConverter) resolveAttachmentLink(ticketID int64, wikiPage string, milestoneName string, link string) string {
 	attachmentName := attachmentLinkRegexp.ReplaceAllString(link, `$1`)
 	attachmentWikiPage := attachmentLinkRegexp.ReplaceAllString(link, `$2`)
 	attachmentTicketIDStr := attachmentLinkRegexp.ReplaceAllString(link, `$3`)
@@ -206,6 +215,10 @@ func (converter *DefaultConverter) resolveAttachmentLink(ticketID int64, wikiPag
 		return converter.resolveTicketAttachmentLink(ticketID, attachmentName, link)
 	} else if wikiPage != "" {
 		return converter.resolveWikiAttachmentLink(wikiPage, attachmentName, link)
+	} else if milestoneName != "" {
+		// Gitea milestones have no attachments so Trac milestone attachments are not imported
+		log.Warn("cannot find attachment \"%s\" for milestone %s for Trac link \"%s\": milestone attachments are not imported", attachmentName, milestoneName, link)
+		return link
 	}
 
 	log.Warn("Trac attachment link \"%s\" requires either ticket or wiki", link)
@@ -318,7 +331,7 @@ func (converter *DefaultConverter) convertBrackettedTracLinks(in string) string
 }
 
 // convertUnbrackettedTracLinks converts Trac-style links after any surrounding Trac bracketting and link texts have been processed
-func (converter *DefaultConverter) convertUnbrackettedTracLinks(ticketID int64, wikiPage string, in string) string {
+func (converter *DefaultConverter) convertUnbrackettedTracLinks(ticketID int64, wikiPage string, milestoneName string, in string) string {
 	out := in
 
 	out = httpLinkRegexp.ReplaceAllStringFunc(out, func(match string) string {
@@ -338,7 +351,7 @@ func (converter *DefaultConverter) convertUnbrackettedTracLinks(ticketID int64,
 	})
 
 	out = attachmentLinkRegexp.ReplaceAllStringFunc(out, func(match string) string {
-		return converter.resolveAttachmentLink(ticketID, wikiPage, match)
+		return converter.resolveAttachmentLink(ticketID, wikiPage, milestoneName, match)
 	})
 
 	out = changesetLinkRegexp.ReplaceAllStringFunc(out, func(match string) string {
@@ -396,13 +409,13 @@ func (converter *DefaultConverter) unmarkLinks(in string) string {
 	return out
 }
 
-func (converter *DefaultConverter) convertLinks(ticketID int64, wikiPage string, in string) string {
+func (converter *DefaultConverter) convertLinks(ticketID int64, wikiPage string, milestoneName string, in string) string {
 	out := in
 
 	// conversion occurs in three distinct phases with each phase dealing with one part of the link syntax
 	// and leaving the remainder for the next stage
 	out = converter.convertBrackettedTracLinks(out)
-	out = converter.convertUnbrackettedTracLinks(ticketID, wikiPage, out)
+	out = converter.convertUnbrackettedTracLinks(ticketID, wikiPage, milestoneName, out)
 	out = converter.unmarkLinks(out)
 	return out
 }
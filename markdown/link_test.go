@@ -65,6 +65,10 @@ func wikiConvert(tracText string) string {
 	return converter.WikiConvert(wikiPage, tracText)
 }
 
+func milestoneConvert(tracText string) string {
+	return converter.MilestoneConvert(descriptionMilestoneName, tracText)
+}
+
 // verifyLink verifies that the provided trac formatting for a link + text results in the corresponding markdown format
 func verifyLink(
 	t *testing.T,
@@ -347,6 +351,25 @@ func TestMilestoneLink(t *testing.T) {
 		milestoneURL)
 }
 
+const tracMilestoneName = "some-trac-milestone"
+
+func setUpRenamedMilestoneLink(t *testing.T) {
+	setUpMilestoneLink(t)
+
+	// Trac milestone is renamed in Gitea
+	converter.SetMilestoneMap(map[string]string{tracMilestoneName: milestoneName})
+}
+
+func TestRenamedMilestoneLink(t *testing.T) {
+	verifyAllLinkTypes(
+		t,
+		setUpRenamedMilestoneLink,
+		tearDown,
+		wikiConvert,
+		"milestone:"+tracMilestoneName,
+		milestoneURL)
+}
+
 const (
 	attachmentName        = "some-attachment.png"
 	attachmentWikiRelPath = "attachments-dir/somepage/xyz.png"
@@ -450,6 +473,29 @@ func TestExplicitTicketAttachmentLink(t *testing.T) {
 		ticketAttachmentURL)
 }
 
+const descriptionMilestoneName = "milestone-with-description"
+
+func TestMilestoneDescriptionWikiAttachmentLink(t *testing.T) {
+	verifyAllLinkTypes(
+		t,
+		setUpExplicitWikiAttachmentLink,
+		tearDown,
+		milestoneConvert,
+		"attachment:"+attachmentName+":wiki:"+otherWikiPage,
+		attachmentWikiURL)
+}
+
+func TestMilestoneDescriptionImplicitAttachmentLink(t *testing.T) {
+	// Trac milestone attachments are not imported so links to them are left unconverted
+	verifyLink(
+		t,
+		setUp,
+		tearDown,
+		milestoneConvert,
+		tracPlainLink("attachment:"+attachmentName),
+		tracPlainLink("attachment:"+attachmentName))
+}
+
 const (
 	commitID  = "123abc456def7890"
 	commitURL = "url-of-changeset-commit"
@@ -0,0 +1,83 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/stevejefferson/trac2gitea/importer"
+)
+
+// prefix of Trac milestone names in the milestone map
+const milestoneMapPrefix = "milestone:"
+
+// readMilestoneMap reads the milestone map from the provided file, if no file provided, import a default map using the provided importer.
+// Blank lines and lines starting with '#' are ignored.
+func readMilestoneMap(mapFile string, dataImporter *importer.Importer) (map[string]string, error) {
+	if mapFile == "" {
+		return dataImporter.DefaultMilestoneMap()
+	}
+
+	fd, err := os.Open(mapFile)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	milestoneMap := make(map[string]string)
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		mapLine := scanner.Text()
+		trimmedLine := strings.TrimSpace(mapLine)
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+
+		equalsPos := strings.LastIndex(mapLine, "=")
+		if equalsPos == -1 {
+			return nil, fmt.Errorf("badly formatted milestone map file %s: expecting '=', found %s", mapFile, mapLine)
+		}
+
+		tracMilestone := strings.Trim(mapLine[0:equalsPos], " ")
+		if !strings.HasPrefix(tracMilestone, milestoneMapPrefix) {
+			return nil, fmt.Errorf("badly formatted milestone map file %s: expecting '%s' before Trac milestone name, found %s", mapFile, milestoneMapPrefix, mapLine)
+		}
+		tracMilestone = strings.Trim(tracMilestone[len(milestoneMapPrefix):], " ")
+		giteaMilestone := strings.Trim(mapLine[equalsPos+1:], " ")
+		milestoneMap[tracMilestone] = giteaMilestone
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return milestoneMap, nil
+}
+
+func writeMilestoneMapToFile(mapFile string, milestoneMap map[string]string) error {
+	fd, err := os.Create(mapFile)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	tracMilestones := []string{}
+	for tracMilestone := range milestoneMap {
+		tracMilestones = append(tracMilestones, tracMilestone)
+	}
+	sort.Strings(tracMilestones)
+
+	for _, tracMilestone := range tracMilestones {
+		if _, err := fd.WriteString(milestoneMapPrefix + tracMilestone + " = " + milestoneMap[tracMilestone] + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
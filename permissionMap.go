@@ -0,0 +1,84 @@
+// Copyright 2020 Steve Jefferson. All rights reserved.
+// Use of this source code is governed by a GPL-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/stevejefferson/trac2gitea/importer"
+)
+
+// readPermissionMap reads the permission map from the provided file, if no file provided, import a default map using the provided importer.
+// Blank lines and lines starting with '#' are ignored.
+func readPermissionMap(mapFile string, dataImporter *importer.Importer) (map[string]string, error) {
+	if mapFile == "" {
+		return dataImporter.DefaultPermissionMap()
+	}
+
+	fd, err := os.Open(mapFile)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	permissionMap := make(map[string]string)
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		mapLine := scanner.Text()
+		trimmedLine := strings.TrimSpace(mapLine)
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+
+		equalsPos := strings.LastIndex(mapLine, "=")
+		if equalsPos == -1 {
+			return nil, fmt.Errorf("badly formatted permission map file %s: expecting '=', found %s", mapFile, mapLine)
+		}
+
+		tracPermissionOrGroup := strings.Trim(mapLine[0:equalsPos], " ")
+		giteaValue := strings.Trim(mapLine[equalsPos+1:], " ")
+		if !strings.Contains(tracPermissionOrGroup, ":") {
+			switch giteaValue {
+			case importer.PermissionRead, importer.PermissionWrite, importer.PermissionAdmin, importer.PermissionNone:
+			default:
+				return nil, fmt.Errorf("badly formatted permission map file %s: expecting '%s', '%s', '%s' or '%s' for permission %s, found %s",
+					mapFile, importer.PermissionRead, importer.PermissionWrite, importer.PermissionAdmin, importer.PermissionNone, tracPermissionOrGroup, giteaValue)
+			}
+		}
+		permissionMap[tracPermissionOrGroup] = giteaValue
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissionMap, nil
+}
+
+func writePermissionMapToFile(mapFile string, permissionMap map[string]string) error {
+	fd, err := os.Create(mapFile)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	keys := []string{}
+	for key := range permissionMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if _, err := fd.WriteString(key + " = " + permissionMap[key] + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
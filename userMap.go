@@ -6,17 +6,172 @@ package main
 
 import (
 	"bufio"
+	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/stevejefferson/trac2gitea/importer"
+	"github.com/stevejefferson/trac2gitea/log"
 )
 
-// readUserMap reads the user map from the provided file, if no file provided, import a default map using the provided importer
+// rules read from the user map and the rule used to map each Trac user mapped by one of them
+var userMapRules []importer.UserRule
+var appliedUserMapRules map[string]importer.UserRule
+
+// fuzzy matches used in the default user map
+var fuzzyUserMatches map[string]*importer.UserMatch
+
+// regexp for a trailing comment on a user map line (which may not contain '=')
+var userMapCommentRegexp = regexp.MustCompile(`\s+#[^=]*$`)
+
+// prefix of user map lines loading mappings from a CSV file
+const csvUserMapPrefix = "csv:"
+
+// suffix of the copy of the user map including any placeholder users created
+const placeholderUserMapSuffix = ".placeholders"
+
+// file to which the user map including any placeholder users created is written if no user map is provided
+const defaultPlaceholderUserMapFile = "placeholder-users.map"
+
+// readCSVUserMap reads a table mapping Trac users onto Gitea users from the named columns of a CSV file with a header row.
+func readCSVUserMap(csvFile string, tracColumn string, giteaColumn string) (map[string]string, error) {
+	fd, err := os.Open(csvFile)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	reader := csv.NewReader(fd)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header of user map CSV file %s: %v", csvFile, err)
+	}
+
+	tracIndex, giteaIndex := -1, -1
+	for index, column := range header {
+		switch strings.TrimSpace(column) {
+		case tracColumn:
+			tracIndex = index
+		case giteaColumn:
+			giteaIndex = index
+		}
+	}
+	if tracIndex == -1 || giteaIndex == -1 {
+		return nil, fmt.Errorf("user map CSV file %s: expecting columns '%s' and '%s', found %s", csvFile, tracColumn, giteaColumn, strings.Join(header, ","))
+	}
+
+	table := make(map[string]string)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading user map CSV file %s: %v", csvFile, err)
+		}
+		if tracIndex >= len(record) || giteaIndex >= len(record) {
+			continue
+		}
+
+		tracUserName := strings.TrimSpace(record[tracIndex])
+		if tracUserName != "" {
+			table[tracUserName] = strings.TrimSpace(record[giteaIndex])
+		}
+	}
+
+	return table, nil
+}
+
+// parseUserMapRule parses a user map line describing a rule, returns nil if the line is a literal mapping.
+// Rules are of the form "/<regexp>/ = <gitea-user>" (where <gitea-user> may refer to submatches of <regexp> as $1 etc.),
+// "@<email-domain> = <gitea-user>" or "csv:<csv-file> = <trac-user-column>, <gitea-user-column>".
+func parseUserMapRule(mapFile string, mapLine string, tracUserOrRule string, giteaUserOrColumns string) (importer.UserRule, error) {
+	switch {
+	case len(tracUserOrRule) > 2 && strings.HasPrefix(tracUserOrRule, "/") && strings.HasSuffix(tracUserOrRule, "/"):
+		rule, err := importer.CreatePatternUserRule(tracUserOrRule[1:len(tracUserOrRule)-1], giteaUserOrColumns)
+		if err != nil {
+			return nil, fmt.Errorf("badly formatted user map file %s: invalid regular expression in %s: %v", mapFile, mapLine, err)
+		}
+		return rule, nil
+
+	case len(tracUserOrRule) > 1 && strings.HasPrefix(tracUserOrRule, "@"):
+		return importer.CreateDomainUserRule(tracUserOrRule[1:], giteaUserOrColumns), nil
+
+	case strings.HasPrefix(tracUserOrRule, csvUserMapPrefix):
+		columns := strings.Split(giteaUserOrColumns, ",")
+		if len(columns) != 2 {
+			return nil, fmt.Errorf("badly formatted user map file %s: expecting '<trac-user-column>, <gitea-user-column>', found %s", mapFile, mapLine)
+		}
+
+		// CSV file is relative to the user map file
+		csvFile := strings.TrimPrefix(tracUserOrRule, csvUserMapPrefix)
+		if !filepath.IsAbs(csvFile) {
+			csvFile = filepath.Join(filepath.Dir(mapFile), csvFile)
+		}
+		table, err := readCSVUserMap(csvFile, strings.TrimSpace(columns[0]), strings.TrimSpace(columns[1]))
+		if err != nil {
+			return nil, err
+		}
+		return importer.CreateTableUserRule(tracUserOrRule+" = "+giteaUserOrColumns, table), nil
+	}
+
+	return nil, nil
+}
+
+// defaultUserMap retrieves the default user map using the provided importer, optionally completed by fuzzy matching.
+// Uncertain fuzzy matches are only used when generating maps, so that they can be reviewed.
+func defaultUserMap(dataImporter *importer.Importer) (map[string]string, error) {
+	userMap, err := dataImporter.DefaultUserMap()
+	if err != nil || !fuzzyMatchUsers {
+		return userMap, err
+	}
+
+	fuzzyUserMatches, err = dataImporter.FuzzyMatchUsers(userMap)
+	if err != nil {
+		return nil, err
+	}
+	for tracUserName, match := range fuzzyUserMatches {
+		if match.IsUncertain() && !generateMaps {
+			log.Warn("uncertain match of Trac user %s onto Gitea user %s (confidence %.2f) ignored - review using --generate-maps",
+				tracUserName, match.GiteaUser, match.Confidence)
+			delete(fuzzyUserMatches, tracUserName)
+			continue
+		}
+		userMap[tracUserName] = match.GiteaUser
+	}
+
+	return userMap, nil
+}
+
+// userMapComment returns the trailing comment for the user map line of a Trac user mapped by fuzzy matching, "" if none.
+func userMapComment(tracUserName string) string {
+	match, found := fuzzyUserMatches[tracUserName]
+	if !found {
+		return ""
+	}
+
+	comment := fmt.Sprintf("fuzzy match, confidence %.2f", match.Confidence)
+	if match.Ambiguous {
+		comment = comment + ", other candidates match equally well"
+	}
+	if match.IsUncertain() {
+		comment = "UNCERTAIN " + comment + " - please review"
+	}
+	return " # " + comment
+}
+
+// readUserMap reads the user map from the provided file, if no file provided, import a default map using the provided importer.
+// Any rules in the file are applied to Trac users with no explicit mapping.
+// When generating maps, Trac users not mapped by the file are given their default mapping.
 func readUserMap(mapFile string, dataImporter *importer.Importer) (map[string]string, error) {
 	if mapFile == "" {
-		return dataImporter.DefaultUserMap()
+		return defaultUserMap(dataImporter)
 	}
 
 	fd, err := os.Open(mapFile)
@@ -26,9 +181,16 @@ func readUserMap(mapFile string, dataImporter *importer.Importer) (map[string]st
 	defer fd.Close()
 
 	userMap := make(map[string]string)
+	userMapRules = []importer.UserRule{}
 	scanner := bufio.NewScanner(fd)
 	for scanner.Scan() {
 		userMapLine := scanner.Text()
+		trimmedLine := strings.TrimSpace(userMapLine)
+		if trimmedLine == "" || strings.HasPrefix(trimmedLine, "#") {
+			continue
+		}
+
+		userMapLine = userMapCommentRegexp.ReplaceAllString(userMapLine, "")
 		equalsPos := strings.LastIndex(userMapLine, "=")
 		if equalsPos == -1 {
 			return nil, fmt.Errorf("badly formatted user map file %s: found line %s", mapFile, userMapLine)
@@ -36,6 +198,14 @@ func readUserMap(mapFile string, dataImporter *importer.Importer) (map[string]st
 
 		tracUserName := strings.Trim(userMapLine[0:equalsPos], " ")
 		giteaUserName := strings.Trim(userMapLine[equalsPos+1:], " ")
+		rule, err := parseUserMapRule(mapFile, userMapLine, tracUserName, giteaUserName)
+		if err != nil {
+			return nil, err
+		}
+		if rule != nil {
+			userMapRules = append(userMapRules, rule)
+			continue
+		}
 		userMap[tracUserName] = giteaUserName
 	}
 
@@ -43,9 +213,31 @@ func readUserMap(mapFile string, dataImporter *importer.Importer) (map[string]st
 		return nil, err
 	}
 
+	// literal mappings take precedence over rules, which are applied in order
+	appliedUserMapRules, err = dataImporter.ApplyUserRules(userMap, userMapRules)
+	if err != nil {
+		return nil, err
+	}
+
+	if generateMaps {
+		dfltUserMap, err := defaultUserMap(dataImporter)
+		if err != nil {
+			return nil, err
+		}
+		for tracUserName, giteaUserName := range dfltUserMap {
+			if _, found := userMap[tracUserName]; found {
+				// user mapped by the file - any fuzzy match was not used
+				delete(fuzzyUserMatches, tracUserName)
+				continue
+			}
+			userMap[tracUserName] = giteaUserName
+		}
+	}
+
 	return userMap, nil
 }
 
+// writeUserMapToFile writes the user map to a file, noting the rule or fuzzy match used to map any Trac user mapped by one and retaining the rules themselves.
 func writeUserMapToFile(mapFile string, userMap map[string]string) error {
 	fd, err := os.Create(mapFile)
 	if err != nil {
@@ -53,11 +245,41 @@ func writeUserMapToFile(mapFile string, userMap map[string]string) error {
 	}
 	defer fd.Close()
 
-	for tracUserName, giteaUserName := range userMap {
-		if _, err := fd.WriteString(tracUserName + " = " + giteaUserName + "\n"); err != nil {
+	tracUserNames := []string{}
+	for tracUserName := range userMap {
+		tracUserNames = append(tracUserNames, tracUserName)
+	}
+	sort.Strings(tracUserNames)
+
+	uncertainMatchCount := 0
+	for _, tracUserName := range tracUserNames {
+		if match, found := fuzzyUserMatches[tracUserName]; found && match.IsUncertain() {
+			uncertainMatchCount++
+		}
+		if rule, found := appliedUserMapRules[tracUserName]; found {
+			if _, err := fd.WriteString("# from rule: " + rule.String() + "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := fd.WriteString(tracUserName + " = " + userMap[tracUserName] + userMapComment(tracUserName) + "\n"); err != nil {
 			return err
 		}
 	}
 
+	if uncertainMatchCount > 0 {
+		log.Warn("%d uncertain fuzzy user matches flagged for review in %s", uncertainMatchCount, mapFile)
+	}
+
+	if len(userMapRules) > 0 {
+		if _, err := fd.WriteString("\n# rules for Trac users not mapped above\n"); err != nil {
+			return err
+		}
+		for _, rule := range userMapRules {
+			if _, err := fd.WriteString(rule.String() + "\n"); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }